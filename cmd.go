@@ -56,6 +56,9 @@ If no operation is specified and targets are provided -Y will be assumed
 New options:
     -N --repo             Assume targets are from the repositories
     -a --aur              Assume targets are from the AUR
+    --reviewnote <note>   Note recorded in the review ledger for reviewed diffs
+    --unattended          Never prompt and stop on changes that need a review
+    --report <file>       Unattended run report, Markdown if it ends in .md
 
 Permanent configuration options:
     --save                Causes the following options to be saved back to the
@@ -70,6 +73,10 @@ Permanent configuration options:
     --mflags      <flags> Pass arguments to makepkg
     --pacman      <file>  pacman command to use
     --git         <file>  git command to use
+    --hg          <file>  hg command to use
+    --svn         <file>  svn command to use
+    --bzr         <file>  bzr command to use
+    --fossil      <file>  fossil command to use
     --gitflags    <flags> Pass arguments to git
     --gpg         <file>  gpg command to use
     --gpgflags    <flags> Pass arguments to gpg
@@ -105,6 +112,13 @@ Permanent configuration options:
     --doublelineresults   List each search result on two lines, like pacman
 
     --devel               Check development packages during sysupgrade
+    --develtags           Follow the newest remote tag of #tag= git sources
+    --noupgradedelay      Ignore the AUR upgrade delay for this run
+    --migrate             Replace foreign packages that moved to the repos during sysupgrade
+    --explain-rank        Print the score breakdown of each search result
+    --offline             Search, show info and complete from the AUR metadata cache
+    --required-by         List the AUR packages requiring the -Si or -Qi targets
+    --format <format>     Print -Ss results and -Si info as json, tsv or a Go template
     --rebuild             Always build target packages
     --rebuildall          Always build all AUR packages
     --norebuild           Skip package build if in cache and up to date
@@ -122,16 +136,39 @@ Permanent configuration options:
 
     --timeupdate          Check packages' AUR page for changes during sysupgrade
 
+query specific options:
+       --format <format>  Print -Qu upgrades and -Qi info as json, tsv or a Go template
+                          and the --tree as tree, dot or json
+       --count            Print the number of repo, AUR and devel upgrades of -Qu
+       --tree             Print the dependency tree of installed targets
+       --depth <n>        Limit the --tree to n levels
+       --reverse          Print the packages requiring the --tree targets instead
+       --optional         Follow optional dependencies in the --tree
+       --nodedup          Expand every occurrence of a package in the --tree
+
 show specific options:
     -c --complete         Used for completions
     -d --defaultconfig    Print default yay configuration
     -g --currentconfig    Print current yay configuration
     -s --stats            Display system package statistics
     -w --news             Print arch news
+       --advisories       List installed packages affected by security advisories
+       --sbom             Print a software bill of materials of installed packages
+       --format <format>  SBOM format: spdx (default) or cyclonedx, -Ps as json,
+                          tsv or a Go template
+       --install-timer    Install systemd units running unattended upgrades
+       --rebuild-check    List foreign packages broken by library or runtime upgrades
+       --rebuild-queue    With --rebuild-check, rebuild them on the next sysupgrade
+       --history          List the transactions yay ran
+       --package <name>   With --history, only list the transactions of a package
+       --since <age>      With --history, only list transactions since an age or date
 
 yay specific options:
     -c --clean            Remove unneeded dependencies (-cc to ignore optdepends)
        --gendb            Generates development package DB used for updating
+       --local            With --gendb, use the existing build directories only
+       --exportreviews    Print the PKGBUILD review ledger as JSON
+       --importreviews    Merge review ledgers from the given files
 
 getpkgbuild specific options:
     -f --force            Force download for existing ABS packages
@@ -221,6 +258,23 @@ func handleQuery(ctx context.Context, run *runtime.Runtime, cmdArgs *parser.Argu
 			cmdArgs.ExistsDouble("u", "sysupgrade"), filter)
 	}
 
+	if cmdArgs.ExistsArg("tree") {
+		return printTree(run, cmdArgs, dbExecutor)
+	}
+
+	if cmdArgs.ExistsArg("i", "info") && cmdArgs.ExistsArg("required-by") {
+		return requiredBy(ctx, run, cmdArgs, dbExecutor, true)
+	}
+
+	if cmdArgs.ExistsArg("i", "info") && cmdArgs.ExistsArg("format") {
+		formatter, err := newFormatter(cmdArgs)
+		if err != nil {
+			return err
+		}
+
+		return queryInfo(run, cmdArgs, dbExecutor, formatter)
+	}
+
 	if err := run.CmdBuilder.Show(run.CmdBuilder.BuildPacmanCmd(ctx,
 		cmdArgs, run.Cfg.Mode, settings.NoConfirm)); err != nil {
 		if str := err.Error(); strings.Contains(str, "exit status") {
@@ -269,9 +323,21 @@ func handlePrint(ctx context.Context, run *runtime.Runtime, cmdArgs *parser.Argu
 			dbExecutor.LastBuildTime(), run.Cfg.BottomUp, double, quiet)
 	case cmdArgs.ExistsArg("c", "complete"):
 		return completion.Show(ctx, run.HTTPClient, dbExecutor,
-			run.Cfg.AURURL, run.Cfg.CompletionPath, run.Cfg.CompletionInterval, cmdArgs.ExistsDouble("c", "complete"))
+			run.Cfg.AURURL, run.Cfg.CompletionPath, run.Cfg.CompletionInterval, cmdArgs.ExistsDouble("c", "complete"),
+			run.AURCache, run.Cfg.Offline)
 	case cmdArgs.ExistsArg("s", "stats"):
-		return localStatistics(ctx, run, dbExecutor)
+		return localStatistics(ctx, run, cmdArgs, dbExecutor)
+	case cmdArgs.ExistsArg("advisories"):
+		return printAdvisories(ctx, run, dbExecutor)
+	case cmdArgs.ExistsArg("install-timer"):
+		return installTimer(run)
+	case cmdArgs.ExistsArg("rebuild-check"):
+		return rebuildCheck(run, dbExecutor, cmdArgs.ExistsArg("rebuild-queue"))
+	case cmdArgs.ExistsArg("history"):
+		return printHistory(run, cmdArgs)
+	case cmdArgs.ExistsArg("sbom"):
+		format, _, _ := cmdArgs.GetArg("format")
+		return printSBOM(ctx, run, dbExecutor, format)
 	}
 
 	return nil
@@ -283,7 +349,15 @@ func handleYay(ctx context.Context, run *runtime.Runtime,
 ) error {
 	switch {
 	case cmdArgs.ExistsArg("gendb"):
+		if cmdArgs.ExistsArg("local") {
+			return createLocalDevelDB(ctx, run, dbExecutor)
+		}
+
 		return createDevelDB(ctx, run, dbExecutor)
+	case cmdArgs.ExistsArg("exportreviews"):
+		return exportReviews(run)
+	case cmdArgs.ExistsArg("importreviews"):
+		return importReviews(run, cmdArgs.Targets)
 	case cmdArgs.ExistsDouble("c"):
 		return cleanDependencies(ctx, run.Cfg, cmdBuilder, cmdArgs, dbExecutor, true)
 	case cmdArgs.ExistsArg("c", "clean"):
@@ -341,7 +415,13 @@ func handleSync(ctx context.Context, run *runtime.Runtime, cmdArgs *parser.Argum
 
 	switch {
 	case cmdArgs.ExistsArg("s", "search"):
-		return syncSearch(ctx, targets, dbExecutor, run.QueryBuilder, !cmdArgs.ExistsArg("q", "quiet"))
+		formatter, err := newFormatter(cmdArgs)
+		if err != nil {
+			return err
+		}
+
+		return syncSearch(ctx, run.Logger, targets, dbExecutor, run.QueryBuilder,
+			!cmdArgs.ExistsArg("q", "quiet"), formatter)
 	case cmdArgs.ExistsArg("p", "print", "print-format"):
 		return run.CmdBuilder.Show(run.CmdBuilder.BuildPacmanCmd(ctx,
 			cmdArgs, run.Cfg.Mode, settings.NoConfirm))
@@ -352,11 +432,17 @@ func handleSync(ctx context.Context, run *runtime.Runtime, cmdArgs *parser.Argum
 	case cmdArgs.ExistsArg("g", "groups"):
 		return run.CmdBuilder.Show(run.CmdBuilder.BuildPacmanCmd(ctx,
 			cmdArgs, run.Cfg.Mode, settings.NoConfirm))
+	case cmdArgs.ExistsArg("i", "info") && cmdArgs.ExistsArg("required-by"):
+		return requiredBy(ctx, run, cmdArgs, dbExecutor, false)
 	case cmdArgs.ExistsArg("i", "info"):
 		return syncInfo(ctx, run, cmdArgs, targets, dbExecutor)
 	case cmdArgs.ExistsArg("u", "sysupgrade") || len(cmdArgs.Targets) > 0:
 		return syncInstall(ctx, run, cmdArgs, dbExecutor)
 	case cmdArgs.ExistsArg("y", "refresh"):
+		if run.Cfg.Mode == parser.ModeAUR {
+			return refreshAURMetadata(ctx, run)
+		}
+
 		return run.CmdBuilder.Show(run.CmdBuilder.BuildPacmanCmd(ctx,
 			cmdArgs, run.Cfg.Mode, settings.NoConfirm))
 	}
@@ -378,7 +464,9 @@ func handleRemove(ctx context.Context, run *runtime.Runtime, cmdArgs *parser.Arg
 func displayNumberMenu(ctx context.Context, run *runtime.Runtime, pkgS []string, dbExecutor db.Executor,
 	queryBuilder query.Builder, cmdArgs *parser.Arguments,
 ) error {
-	queryBuilder.Execute(ctx, dbExecutor, pkgS)
+	if err := queryBuilder.Execute(ctx, dbExecutor, pkgS); err != nil {
+		return err
+	}
 
 	if err := queryBuilder.Results(dbExecutor, query.NumberMenu); err != nil {
 		return err
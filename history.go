@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yay/v12/pkg/history"
+	"github.com/Jguer/yay/v12/pkg/output"
+	"github.com/Jguer/yay/v12/pkg/runtime"
+	"github.com/Jguer/yay/v12/pkg/settings/parser"
+	"github.com/Jguer/yay/v12/pkg/text"
+)
+
+// printHistory lists the recorded transactions, optionally only those of
+// a package or since a time.
+func printHistory(run *runtime.Runtime, cmdArgs *parser.Arguments) error {
+	formatter, err := newFormatter(cmdArgs)
+	if err != nil {
+		return err
+	}
+
+	var since time.Time
+
+	if value, _, _ := cmdArgs.GetArg("since"); value != "" {
+		if since, err = history.ParseSince(value, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	pkgName, _, _ := cmdArgs.GetArg("package")
+
+	transactions, err := history.NewLog(run.Cfg.HistoryPath, run.Logger.Child("history")).Read()
+	if err != nil {
+		return err
+	}
+
+	transactions = history.Filter(transactions, pkgName, since)
+
+	if formatter != nil {
+		var out strings.Builder
+		if err := output.Write(&out, formatter, transactions); err != nil {
+			return err
+		}
+
+		run.Logger.Print(out.String())
+
+		return nil
+	}
+
+	if len(transactions) == 0 {
+		run.Logger.Infoln(gotext.Get("No transactions recorded."))
+		return nil
+	}
+
+	for i := range transactions {
+		printTransaction(run.Logger, &transactions[i])
+	}
+
+	return nil
+}
+
+func printTransaction(logger *text.Logger, transaction *history.Transaction) {
+	result := text.Green(string(transaction.Result))
+	if transaction.Result != history.ResultSuccess {
+		result = text.Red(string(transaction.Result))
+	}
+
+	logger.OperationInfoln(fmt.Sprintf("%s %s (%s)",
+		transaction.Time.Local().Format("2006-01-02 15:04:05"),
+		strings.Join(transaction.Command, " "), result))
+
+	for i := range transaction.Packages {
+		pkg := &transaction.Packages[i]
+
+		line := " " + text.Bold(strings.ToLower(pkg.Source)) + "/" + text.Bold(pkg.Name) + " "
+		if pkg.OldVersion != "" {
+			line += text.Red(pkg.OldVersion) + " -> "
+		}
+
+		line += text.Green(pkg.NewVersion)
+
+		details := make([]string, 0, 2)
+		if pkg.Commit != "" {
+			details = append(details, gotext.Get("commit %s", shortCommit(pkg.Commit)))
+		}
+
+		if pkg.BuildSeconds > 0 {
+			built := time.Duration(pkg.BuildSeconds * float64(time.Second)).Round(time.Second)
+			details = append(details, gotext.Get("built in %s", built))
+		}
+
+		if len(details) != 0 {
+			line += " [" + strings.Join(details, ", ") + "]"
+		}
+
+		if pkg.Result != history.ResultSuccess {
+			line += " " + text.Red(string(pkg.Result))
+			if pkg.Error != "" {
+				line += ": " + pkg.Error
+			}
+		}
+
+		logger.Println(line)
+	}
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+
+	return commit
+}
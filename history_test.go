@@ -0,0 +1,121 @@
+//go:build !integration
+// +build !integration
+
+package main
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yay/v12/pkg/db/mock"
+	"github.com/Jguer/yay/v12/pkg/history"
+	"github.com/Jguer/yay/v12/pkg/runtime"
+	"github.com/Jguer/yay/v12/pkg/settings"
+	"github.com/Jguer/yay/v12/pkg/settings/parser"
+	"github.com/Jguer/yay/v12/pkg/text"
+)
+
+func TestPrintHistory(t *testing.T) {
+	t.Parallel()
+
+	historyPath := filepath.Join(t.TempDir(), "history.jsonl")
+	log := history.NewLog(historyPath, text.NewLogger(io.Discard, io.Discard, strings.NewReader(""), false, "test"))
+
+	require.NoError(t, log.Append(&history.Transaction{
+		Time:    time.Now().AddDate(0, 0, -60),
+		Command: []string{"yay", "-S", "foo"},
+		Result:  history.ResultSuccess,
+		Packages: []history.Package{
+			{Name: "foo", Source: "AUR", NewVersion: "1.0-1", Result: history.ResultSuccess},
+		},
+	}))
+	require.NoError(t, log.Append(&history.Transaction{
+		Time:    time.Now().AddDate(0, 0, -1),
+		Command: []string{"yay", "-Syu"},
+		Result:  history.ResultFailed,
+		Packages: []history.Package{
+			{
+				Name: "foo", Source: "AUR", OldVersion: "1.0-1", NewVersion: "1.1-1",
+				Commit: "0123456789abcdef", BuildSeconds: 75, Result: history.ResultFailed, Error: "exit status 4",
+			},
+			{Name: "bar", Source: "Sync", OldVersion: "1-1", NewVersion: "2-1", Result: history.ResultSuccess},
+		},
+	}))
+
+	testCases := []struct {
+		name    string
+		options map[string]string
+		want    []string
+		notWant string
+		wantErr string
+	}{
+		{
+			name:    "package since",
+			options: map[string]string{"package": "foo", "since": "30d"},
+			want: []string{
+				"yay -Syu (\x1b[31mfailed\x1b[0m)",
+				" \x1b[1maur\x1b[0m/\x1b[1mfoo\x1b[0m \x1b[31m1.0-1\x1b[0m -> \x1b[32m1.1-1\x1b[0m " +
+					"[commit 0123456789ab, built in 1m15s] \x1b[31mfailed\x1b[0m: exit status 4\n",
+			},
+			notWant: "bar",
+		},
+		{
+			name:    "template",
+			options: map[string]string{"format": "{{.Result}} {{len .Packages}}"},
+			want:    []string{"success 1\nfailed 2\n"},
+		},
+		{
+			name:    "no transactions",
+			options: map[string]string{"package": "baz"},
+			want:    []string{"No transactions recorded."},
+		},
+		{
+			name:    "invalid since",
+			options: map[string]string{"since": "soon"},
+			wantErr: "invalid --since 'soon'",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			out := &strings.Builder{}
+			run := &runtime.Runtime{
+				Logger: text.NewLogger(out, io.Discard, strings.NewReader(""), false, "test"),
+				Cfg:    &settings.Configuration{HistoryPath: historyPath},
+			}
+
+			cmdArgs := parser.MakeArguments()
+			cmdArgs.AddArg("P", "history")
+
+			for option, value := range tc.options {
+				cmdArgs.CreateOrAppendOption(option, value)
+			}
+
+			err := handleCmd(context.Background(), run, cmdArgs, &mock.DBExecutor{})
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+
+			for _, want := range tc.want {
+				assert.Contains(t, out.String(), want)
+			}
+
+			if tc.notWant != "" {
+				assert.NotContains(t, out.String(), tc.notWant)
+			}
+		})
+	}
+}
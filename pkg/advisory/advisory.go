@@ -0,0 +1,160 @@
+// Package advisory reads vulnerability feeds in the Arch Linux security
+// tracker JSON format and matches them against package versions.
+package advisory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/Jguer/yay/v12/pkg/db"
+)
+
+// Advisory statuses that do not report a confirmed vulnerability.
+const (
+	// StatusNotAffected marks advisories that do not apply to any version.
+	StatusNotAffected = "Not affected"
+	// StatusUnknown marks advisories that have not been triaged yet.
+	StatusUnknown = "Unknown"
+)
+
+// Advisory is a single AVG (Arch Vulnerability Group) entry of the feed.
+type Advisory struct {
+	Name       string   `json:"name"`
+	Packages   []string `json:"packages"`
+	Status     string   `json:"status"`
+	Severity   string   `json:"severity"`
+	Type       string   `json:"type"`
+	Affected   string   `json:"affected"`
+	Fixed      string   `json:"fixed"`
+	Ticket     string   `json:"ticket"`
+	Issues     []string `json:"issues"`
+	Advisories []string `json:"advisories"`
+}
+
+// Affects reports whether version is vulnerable to the advisory.
+func (a *Advisory) Affects(version string) bool {
+	if a.Status == StatusNotAffected || a.Status == StatusUnknown {
+		return false
+	}
+
+	if a.Fixed != "" {
+		return db.VerCmp(version, a.Fixed) < 0
+	}
+
+	// Without a fixed version only versions up to the one found affected are
+	// known to be vulnerable.
+	return a.Affected == "" || db.VerCmp(version, a.Affected) <= 0
+}
+
+// Feed is a parsed advisory feed indexed by package name.
+type Feed struct {
+	byPackage map[string][]*Advisory
+}
+
+// NewFeed indexes advisories by the packages they cover.
+func NewFeed(advisories []Advisory) *Feed {
+	feed := &Feed{byPackage: make(map[string][]*Advisory)}
+
+	for i := range advisories {
+		adv := &advisories[i]
+		for _, pkg := range adv.Packages {
+			feed.byPackage[pkg] = append(feed.byPackage[pkg], adv)
+		}
+	}
+
+	return feed
+}
+
+// Parse decodes a feed in the security tracker JSON format.
+func Parse(r io.Reader) (*Feed, error) {
+	advisories := []Advisory{}
+	if err := json.NewDecoder(r).Decode(&advisories); err != nil {
+		return nil, err
+	}
+
+	return NewFeed(advisories), nil
+}
+
+// Load reads a feed from source, which may be an http(s) URL or a file path.
+func Load(ctx context.Context, client *http.Client, source string) (*Feed, error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		file, err := os.Open(source)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		return Parse(file)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &ErrFeedStatus{source: source, status: resp.Status}
+	}
+
+	return Parse(resp.Body)
+}
+
+// Affecting returns the advisories that apply to the given installed version
+// of a package, sorted by name.
+func (f *Feed) Affecting(pkgName, version string) []*Advisory {
+	affecting := make([]*Advisory, 0)
+
+	for _, adv := range f.byPackage[pkgName] {
+		if adv.Affects(version) {
+			affecting = append(affecting, adv)
+		}
+	}
+
+	sort.Slice(affecting, func(i, j int) bool {
+		return affecting[i].Name < affecting[j].Name
+	})
+
+	return affecting
+}
+
+// FixedBy returns the advisories affecting the local version of a package
+// that are no longer affecting the remote version.
+func (f *Feed) FixedBy(pkgName, localVersion, remoteVersion string) []*Advisory {
+	fixed := make([]*Advisory, 0)
+
+	for _, adv := range f.Affecting(pkgName, localVersion) {
+		if adv.Fixed != "" && !adv.Affects(remoteVersion) {
+			fixed = append(fixed, adv)
+		}
+	}
+
+	return fixed
+}
+
+// Names returns the AVG identifiers of the given advisories.
+func Names(advisories []*Advisory) []string {
+	names := make([]string, 0, len(advisories))
+	for _, adv := range advisories {
+		names = append(names, adv.Name)
+	}
+
+	return names
+}
+
+// String formats an advisory as "AVG-1 (high, arbitrary code execution)".
+func (a *Advisory) String() string {
+	return fmt.Sprintf("%s (%s, %s)", a.Name, strings.ToLower(a.Severity), a.Type)
+}
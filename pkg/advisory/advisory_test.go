@@ -0,0 +1,89 @@
+//go:build !integration
+// +build !integration
+
+package advisory
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func loadFixture(t *testing.T) *Feed {
+	t.Helper()
+
+	feed, err := Load(context.Background(), &http.Client{}, filepath.Join("testdata", "all.json"))
+	require.NoError(t, err)
+
+	return feed
+}
+
+func TestFeed_Affecting(t *testing.T) {
+	t.Parallel()
+
+	feed := loadFixture(t)
+
+	testCases := []struct {
+		name    string
+		pkg     string
+		version string
+		want    []string
+	}{
+		{name: "older than both fixes", pkg: "openssl", version: "3.0.7-2", want: []string{"AVG-2843", "AVG-2900"}},
+		{name: "between fixes", pkg: "openssl", version: "3.1.0-1", want: []string{"AVG-2900"}},
+		{name: "fixed", pkg: "openssl", version: "3.1.1-1", want: []string{}},
+		{name: "other package in group", pkg: "lib32-openssl", version: "1:3.0.0-1", want: []string{}},
+		{name: "no fix available", pkg: "libtiff", version: "4.5.0-1", want: []string{"AVG-2100"}},
+		{name: "newer than affected without fix", pkg: "libtiff", version: "4.5.1-1", want: []string{}},
+		{name: "not affected", pkg: "bash", version: "5.0.0-1", want: []string{}},
+		{name: "unknown package", pkg: "yay", version: "12.0.0-1", want: []string{}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.want, Names(feed.Affecting(tc.pkg, tc.version)))
+		})
+	}
+}
+
+func TestFeed_FixedBy(t *testing.T) {
+	t.Parallel()
+
+	feed := loadFixture(t)
+
+	assert.Equal(t, []string{"AVG-2843"}, Names(feed.FixedBy("openssl", "3.0.7-2", "3.0.8-1")))
+	assert.Equal(t, []string{"AVG-2843", "AVG-2900"}, Names(feed.FixedBy("openssl", "3.0.7-2", "3.1.1-1")))
+	assert.Equal(t, []string{}, Names(feed.FixedBy("libtiff", "4.5.0-1", "4.5.1-1")))
+	assert.Equal(t, []string{}, Names(feed.FixedBy("openssl", "3.1.1-1", "3.1.2-1")))
+}
+
+func TestLoad_URL(t *testing.T) {
+	defer gock.Off()
+
+	body, err := os.ReadFile(filepath.Join("testdata", "all.json"))
+	require.NoError(t, err)
+
+	gock.New("https://security.archlinux.org").
+		Get("/all.json").
+		Reply(200).
+		BodyString(string(body))
+
+	feed, err := Load(context.Background(), &http.Client{}, "https://security.archlinux.org/all.json")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"AVG-2100"}, Names(feed.Affecting("libtiff", "4.5.0-1")))
+
+	gock.New("https://security.archlinux.org").
+		Get("/all.json").
+		Reply(503)
+
+	_, err = Load(context.Background(), &http.Client{}, "https://security.archlinux.org/all.json")
+	assert.ErrorContains(t, err, "503")
+}
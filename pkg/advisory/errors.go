@@ -0,0 +1,18 @@
+package advisory
+
+import (
+	"errors"
+
+	"github.com/leonelquinteros/gotext"
+)
+
+var ErrNoFeed = errors.New(gotext.Get("no advisory feed set, see advisoryfeed"))
+
+type ErrFeedStatus struct {
+	source string
+	status string
+}
+
+func (e *ErrFeedStatus) Error() string {
+	return gotext.Get("unable to fetch advisory feed %s: %s", e.source, e.status)
+}
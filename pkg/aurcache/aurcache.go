@@ -0,0 +1,340 @@
+// Package aurcache keeps the AUR metadata dump used for graph resolution
+// and answers searches, info and completion from it when the AUR can not
+// be reached.
+package aurcache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/Jguer/aur"
+	"github.com/Jguer/aur/metadata"
+	"github.com/leonelquinteros/gotext"
+)
+
+// endpoint is the dump downloaded by the metadata client.
+const endpoint = "packages-meta-ext-v1.json.gz"
+
+// offlineValidity keeps the metadata client from ever downloading the dump.
+const offlineValidity = 100 * 365 * 24 * time.Hour
+
+type httpRequestDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Cache is the AUR metadata dump on disk. Its modification time is the last
+// time it was checked against the AUR.
+type Cache struct {
+	Path    string
+	baseURL string
+	client  httpRequestDoer
+	editors []aur.RequestEditorFn
+	logFn   metadata.LogFn
+	notify  func(string)
+
+	mu          sync.Mutex
+	offline     aur.QueryClient
+	unreachable bool
+	notified    bool
+}
+
+// New creates a cache of the dump at path. notify is called once with the
+// age of the dump the first time a query is answered from it.
+func New(path, baseURL string, client httpRequestDoer, logFn metadata.LogFn,
+	notify func(string), editors ...aur.RequestEditorFn,
+) *Cache {
+	return &Cache{
+		Path:    path,
+		baseURL: baseURL,
+		client:  client,
+		editors: editors,
+		logFn:   logFn,
+		notify:  notify,
+	}
+}
+
+func (c *Cache) etagPath() string {
+	return c.Path + ".etag"
+}
+
+// Age returns how long ago the dump was last refreshed.
+func (c *Cache) Age(now time.Time) (time.Duration, error) {
+	info, err := os.Stat(c.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, ErrNoCache{c.Path}
+		}
+
+		return 0, err
+	}
+
+	return now.Sub(info.ModTime()), nil
+}
+
+// Refresh downloads the dump unless the AUR reports it did not change since
+// the last refresh, using the stored ETag and the modification time of the
+// dump. It reports whether a new dump was downloaded.
+func (c *Cache) Refresh(ctx context.Context) (bool, error) {
+	reqURL, err := url.JoinPath(c.baseURL, endpoint)
+	if err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, http.NoBody)
+	if err != nil {
+		return false, err
+	}
+
+	for _, editor := range c.editors {
+		if err := editor(ctx, req); err != nil {
+			return false, err
+		}
+	}
+
+	if info, errStat := os.Stat(c.Path); errStat == nil {
+		req.Header.Set("If-Modified-Since", info.ModTime().UTC().Format(http.TimeFormat))
+
+		if etag, errRead := os.ReadFile(c.etagPath()); errRead == nil && len(etag) != 0 {
+			req.Header.Set("If-None-Match", string(etag))
+		}
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		now := time.Now()
+
+		return false, os.Chtimes(c.Path, now, now)
+	case http.StatusOK:
+	default:
+		return false, ErrRefreshStatus{reqURL, resp.Status}
+	}
+
+	if err := c.write(resp); err != nil {
+		return false, err
+	}
+
+	c.mu.Lock()
+	c.offline = nil
+	c.mu.Unlock()
+
+	return true, nil
+}
+
+// write replaces the dump with the response body and stores its ETag.
+func (c *Cache) write(resp *http.Response) error {
+	if err := os.MkdirAll(filepath.Dir(c.Path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.Path), filepath.Base(c.Path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.ReadFrom(resp.Body); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), c.Path); err != nil {
+		return err
+	}
+
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		return os.WriteFile(c.etagPath(), []byte(etag), 0o644)
+	}
+
+	if err := os.Remove(c.etagPath()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// Names lists the packages in the dump.
+func (c *Cache) Names() ([]string, error) {
+	file, err := os.Open(c.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNoCache{c.Path}
+		}
+
+		return nil, err
+	}
+	defer file.Close()
+
+	pkgs := []struct {
+		Name string `json:"Name"`
+	}{}
+	if err := json.NewDecoder(file).Decode(&pkgs); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(pkgs))
+	for i := range pkgs {
+		names = append(names, pkgs[i].Name)
+	}
+
+	return names, nil
+}
+
+// Offline returns a client that answers every query from the dump without
+// reaching the AUR.
+func (c *Cache) Offline() aur.QueryClient {
+	return &Client{cache: c}
+}
+
+// Fallback returns a client that answers queries with online and falls
+// back to the dump once the AUR turns out to be unreachable. Without a dump
+// the error of online is returned.
+func (c *Cache) Fallback(online aur.QueryClient) aur.QueryClient {
+	return &Client{cache: c, online: online}
+}
+
+// Client answers AUR queries online or from the dump.
+type Client struct {
+	cache  *Cache
+	online aur.QueryClient
+}
+
+func (c *Client) Get(ctx context.Context, query *aur.Query) ([]aur.Pkg, error) {
+	if c.online != nil && !c.cache.isUnreachable() {
+		pkgs, err := c.online.Get(ctx, query)
+		if !IsUnreachable(err) {
+			return pkgs, err
+		}
+
+		if _, errAge := c.cache.Age(time.Now()); errAge != nil {
+			return nil, err
+		}
+
+		if c.cache.logFn != nil {
+			c.cache.logFn("AUR unreachable, using metadata cache:", err)
+		}
+
+		c.cache.setUnreachable()
+	}
+
+	offline, err := c.cache.offlineClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return offline.Get(ctx, literalQuery(query))
+}
+
+// literalQuery escapes the needles of a substring query, which the metadata
+// client matches as regular expressions, so they match literally like they
+// do on the AUR.
+func literalQuery(query *aur.Query) *aur.Query {
+	if !query.Contains || query.By == aur.Provides {
+		return query
+	}
+
+	literal := *query
+	literal.Needles = make([]string, 0, len(query.Needles))
+
+	for _, needle := range query.Needles {
+		literal.Needles = append(literal.Needles, regexp.QuoteMeta(needle))
+	}
+
+	return &literal
+}
+
+func (c *Cache) isUnreachable() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.unreachable
+}
+
+func (c *Cache) setUnreachable() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.unreachable = true
+}
+
+// offlineClient loads the dump and announces its age the first time it is
+// used.
+func (c *Cache) offlineClient() (aur.QueryClient, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	age, err := c.Age(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if c.offline == nil {
+		opts := []metadata.ClientOption{
+			metadata.WithCacheFilePath(c.Path),
+			metadata.WithCustomCacheValidity(offlineValidity),
+		}
+		if c.logFn != nil {
+			opts = append(opts, metadata.WithDebugLogger(c.logFn))
+		}
+
+		offline, err := metadata.New(opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		c.offline = offline
+	}
+
+	if !c.notified && c.notify != nil {
+		c.notified = true
+		c.notify(gotext.Get("Using the AUR metadata cached %s ago", FormatAge(age)))
+	}
+
+	return c.offline, nil
+}
+
+// IsUnreachable reports whether err means the AUR could not be reached,
+// as opposed to the AUR answering with an error.
+func IsUnreachable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var netErr net.Error
+
+	return errors.As(err, &netErr)
+}
+
+// FormatAge formats the age of the dump in minutes, hours or days.
+func FormatAge(age time.Duration) string {
+	switch {
+	case age < time.Hour:
+		minutes := int(age.Minutes())
+		return gotext.GetN("%d minute", "%d minutes", minutes, minutes)
+	case age < 48*time.Hour:
+		hours := int(age.Hours())
+		return gotext.GetN("%d hour", "%d hours", hours, hours)
+	}
+
+	days := int(age.Hours() / 24)
+
+	return gotext.GetN("%d day", "%d days", days, days)
+}
@@ -0,0 +1,204 @@
+//go:build !integration
+// +build !integration
+
+package aurcache
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Jguer/aur"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mockaur "github.com/Jguer/yay/v12/pkg/dep/mock"
+)
+
+const dump = `[{"Name":"yay","PackageBase":"yay","Version":"12.0.0-1","Description":"Yet another yogurt"},` +
+	`{"Name":"yay-bin","PackageBase":"yay-bin","Version":"12.0.0-1","Description":"Yet another yogurt"}]`
+
+func TestCache_Refresh(t *testing.T) {
+	t.Parallel()
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		assert.Equal(t, "/"+endpoint, r.URL.Path)
+		assert.Equal(t, "Yay/test", r.Header.Get("User-Agent"))
+
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			assert.NotEmpty(t, r.Header.Get("If-Modified-Since"))
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(dump))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "aur.json")
+	userAgent := func(ctx context.Context, req *http.Request) error {
+		req.Header.Set("User-Agent", "Yay/test")
+		return nil
+	}
+	cache := New(path, server.URL, server.Client(), nil, nil, userAgent)
+
+	updated, err := cache.Refresh(context.Background())
+	require.NoError(t, err)
+	assert.True(t, updated)
+
+	names, err := cache.Names()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"yay", "yay-bin"}, names)
+
+	old := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, os.Chtimes(path, old, old))
+
+	updated, err = cache.Refresh(context.Background())
+	require.NoError(t, err)
+	assert.False(t, updated)
+	assert.Equal(t, 2, requests)
+
+	age, err := cache.Age(time.Now())
+	require.NoError(t, err)
+	assert.Less(t, age, time.Hour)
+}
+
+func TestCache_RefreshStatus(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	cache := New(filepath.Join(t.TempDir(), "aur.json"), server.URL, server.Client(), nil, nil)
+
+	_, err := cache.Refresh(context.Background())
+	assert.EqualError(t, err, "unable to refresh AUR metadata from "+server.URL+"/"+endpoint+": 503 Service Unavailable")
+}
+
+func TestClient_Fallback(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "aur.json")
+	require.NoError(t, os.WriteFile(path, []byte(dump), 0o644))
+
+	old := time.Now().Add(-3 * time.Hour)
+	require.NoError(t, os.Chtimes(path, old, old))
+
+	calls := 0
+	online := &mockaur.MockAUR{
+		GetFn: func(ctx context.Context, query *aur.Query) ([]aur.Pkg, error) {
+			calls++
+			return nil, &net.OpError{Op: "dial", Err: errors.New("network is unreachable")}
+		},
+	}
+
+	notices := []string{}
+	cache := New(path, "https://aur.archlinux.org", http.DefaultClient, nil,
+		func(msg string) { notices = append(notices, msg) })
+	client := cache.Fallback(online)
+
+	for i := 0; i < 2; i++ {
+		pkgs, err := client.Get(context.Background(), &aur.Query{Needles: []string{"yay-bin"}, By: aur.Name})
+		require.NoError(t, err)
+		require.Len(t, pkgs, 1)
+		assert.Equal(t, "yay-bin", pkgs[0].Name)
+	}
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, []string{"Using the AUR metadata cached 3 hours ago"}, notices)
+}
+
+func TestClient_FallbackAURError(t *testing.T) {
+	t.Parallel()
+
+	online := &mockaur.MockAUR{
+		GetFn: func(ctx context.Context, query *aur.Query) ([]aur.Pkg, error) {
+			return nil, errors.New("too many package results")
+		},
+	}
+
+	cache := New(filepath.Join(t.TempDir(), "aur.json"), "https://aur.archlinux.org", http.DefaultClient, nil, nil)
+
+	_, err := cache.Fallback(online).Get(context.Background(), &aur.Query{Needles: []string{"y"}, By: aur.NameDesc})
+	assert.EqualError(t, err, "too many package results")
+}
+
+func TestClient_FallbackNoCache(t *testing.T) {
+	t.Parallel()
+
+	unreachable := &net.OpError{Op: "dial", Err: errors.New("network is unreachable")}
+	calls := 0
+	online := &mockaur.MockAUR{
+		GetFn: func(ctx context.Context, query *aur.Query) ([]aur.Pkg, error) {
+			calls++
+			return nil, unreachable
+		},
+	}
+
+	cache := New(filepath.Join(t.TempDir(), "aur.json"), "https://aur.archlinux.org", http.DefaultClient, nil, nil)
+	client := cache.Fallback(online)
+
+	for i := 0; i < 2; i++ {
+		_, err := client.Get(context.Background(), &aur.Query{Needles: []string{"yay"}, By: aur.Name})
+		assert.ErrorIs(t, err, unreachable)
+	}
+
+	assert.Equal(t, 2, calls, "the AUR is queried again without a dump")
+}
+
+func TestClient_OfflineLiteralNeedles(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "aur.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[{"Name":"c++-utils","PackageBase":"c++-utils",`+
+		`"Version":"1.0-1","Description":"Utilities"},`+
+		`{"Name":"ccc-utils","PackageBase":"ccc-utils","Version":"1.0-1","Description":"Utilities"}]`), 0o644))
+
+	client := New(path, "https://aur.archlinux.org", http.DefaultClient, nil, nil).Offline()
+
+	for needle, want := range map[string][]string{"c++": {"c++-utils"}, "(": {}} {
+		query := &aur.Query{Needles: []string{needle}, By: aur.NameDesc, Contains: true}
+
+		pkgs, err := client.Get(context.Background(), query)
+		require.NoError(t, err, needle)
+		assert.Equal(t, []string{needle}, query.Needles)
+
+		names := make([]string, 0, len(pkgs))
+		for i := range pkgs {
+			names = append(names, pkgs[i].Name)
+		}
+
+		assert.Equal(t, want, names, needle)
+	}
+}
+
+func TestClient_OfflineNoCache(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "aur.json")
+	cache := New(path, "https://aur.archlinux.org", http.DefaultClient, nil, nil)
+
+	_, err := cache.Offline().Get(context.Background(), &aur.Query{Needles: []string{"yay"}, By: aur.Name})
+	assert.ErrorIs(t, err, ErrNoCache{path})
+}
+
+func TestFormatAge(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "1 minute", FormatAge(90*time.Second))
+	assert.Equal(t, "5 hours", FormatAge(5*time.Hour))
+	assert.Equal(t, "3 days", FormatAge(80*time.Hour))
+}
@@ -0,0 +1,20 @@
+package aurcache
+
+import "github.com/leonelquinteros/gotext"
+
+type ErrNoCache struct {
+	path string
+}
+
+func (e ErrNoCache) Error() string {
+	return gotext.Get("no AUR metadata cache at %s, run 'yay -Sy --aur' while online to download it", e.path)
+}
+
+type ErrRefreshStatus struct {
+	url    string
+	status string
+}
+
+func (e ErrRefreshStatus) Error() string {
+	return gotext.Get("unable to refresh AUR metadata from %s: %s", e.url, e.status)
+}
@@ -0,0 +1,112 @@
+// Package aurrequest reads feeds of AUR package requests, such as the merge
+// and deletion requests handled by the AUR package maintainers, and finds
+// what became of a package base that left the AUR.
+package aurrequest
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Request types.
+const (
+	TypeMerge    = "merge"
+	TypeDeletion = "deletion"
+	TypeOrphan   = "orphan"
+)
+
+// StatusAccepted marks requests that were carried out.
+const StatusAccepted = "accepted"
+
+// Request is a single package request of the feed.
+type Request struct {
+	ID   int    `json:"id"`
+	Type string `json:"type"`
+	// Package is the package base the request was filed against.
+	Package string `json:"package"`
+	// MergeInto is the package base a merge request merged the package into.
+	MergeInto string `json:"mergeInto,omitempty"`
+	Status    string `json:"status"`
+	Comment   string `json:"comment,omitempty"`
+}
+
+// Feed is a parsed request feed indexed by package base.
+type Feed struct {
+	byPackage map[string]*Request
+}
+
+// NewFeed indexes the accepted merge and deletion requests by package base.
+// The latest request of a package base wins.
+func NewFeed(requests []Request) *Feed {
+	feed := &Feed{byPackage: make(map[string]*Request)}
+
+	for i := range requests {
+		req := &requests[i]
+		if req.Status != StatusAccepted || (req.Type != TypeMerge && req.Type != TypeDeletion) {
+			continue
+		}
+
+		if prev, ok := feed.byPackage[req.Package]; ok && prev.ID > req.ID {
+			continue
+		}
+
+		feed.byPackage[req.Package] = req
+	}
+
+	return feed
+}
+
+// Parse decodes a feed, a JSON array of requests.
+func Parse(r io.Reader) (*Feed, error) {
+	requests := []Request{}
+	if err := json.NewDecoder(r).Decode(&requests); err != nil {
+		return nil, err
+	}
+
+	return NewFeed(requests), nil
+}
+
+// Load reads a feed from source, which may be an http(s) URL or a file path.
+func Load(ctx context.Context, client *http.Client, source string) (*Feed, error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		file, err := os.Open(source)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		return Parse(file)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &ErrFeedStatus{source: source, status: resp.Status}
+	}
+
+	return Parse(resp.Body)
+}
+
+// Find returns the accepted merge or deletion request of a package base, if
+// any. A nil feed has no requests.
+func (f *Feed) Find(pkgBase string) *Request {
+	if f == nil {
+		return nil
+	}
+
+	return f.byPackage[pkgBase]
+}
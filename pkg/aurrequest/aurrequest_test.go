@@ -0,0 +1,58 @@
+//go:build !integration
+// +build !integration
+
+package aurrequest
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestFeed_Find(t *testing.T) {
+	t.Parallel()
+
+	feed, err := Load(context.Background(), &http.Client{}, filepath.Join("testdata", "requests.json"))
+	require.NoError(t, err)
+
+	merge := feed.Find("python-foo")
+	require.NotNil(t, merge)
+	assert.Equal(t, TypeMerge, merge.Type)
+	assert.Equal(t, "python-foo-ng", merge.MergeInto)
+
+	deletion := feed.Find("old-tool")
+	require.NotNil(t, deletion)
+	assert.Equal(t, TypeDeletion, deletion.Type)
+
+	assert.Nil(t, feed.Find("still-here"))
+	assert.Nil(t, feed.Find("lonely"))
+	assert.Nil(t, feed.Find("yay"))
+
+	var noFeed *Feed
+	assert.Nil(t, noFeed.Find("python-foo"))
+}
+
+func TestLoad_URL(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://example.org").
+		Get("/requests.json").
+		Reply(200).
+		BodyString(`[{"id": 1, "type": "deletion", "package": "foo", "status": "accepted"}]`)
+
+	feed, err := Load(context.Background(), &http.Client{}, "https://example.org/requests.json")
+	require.NoError(t, err)
+	assert.NotNil(t, feed.Find("foo"))
+
+	gock.New("https://example.org").
+		Get("/requests.json").
+		Reply(503)
+
+	_, err = Load(context.Background(), &http.Client{}, "https://example.org/requests.json")
+	assert.ErrorContains(t, err, "503")
+}
@@ -0,0 +1,12 @@
+package aurrequest
+
+import "github.com/leonelquinteros/gotext"
+
+type ErrFeedStatus struct {
+	source string
+	status string
+}
+
+func (e *ErrFeedStatus) Error() string {
+	return gotext.Get("unable to fetch AUR request feed %s: %s", e.source, e.status)
+}
@@ -2,6 +2,7 @@ package completion
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -20,6 +21,11 @@ type PkgSynchronizer interface {
 	SyncPackages(...string) []db.IPackage
 }
 
+// AURNameLister lists AUR packages without reaching the AUR.
+type AURNameLister interface {
+	Names() ([]string, error)
+}
+
 type httpRequestDoer interface {
 	Do(req *http.Request) (*http.Response, error)
 }
@@ -27,8 +33,9 @@ type httpRequestDoer interface {
 // Show provides completion info for shells.
 func Show(ctx context.Context, httpClient httpRequestDoer,
 	dbExecutor PkgSynchronizer, aurURL, completionPath string, interval int, force bool,
+	cache AURNameLister, offline bool,
 ) error {
-	err := Update(ctx, httpClient, dbExecutor, aurURL, completionPath, interval, force)
+	err := Update(ctx, httpClient, dbExecutor, aurURL, completionPath, interval, force, cache, offline)
 	if err != nil {
 		return err
 	}
@@ -45,12 +52,17 @@ func Show(ctx context.Context, httpClient httpRequestDoer,
 }
 
 // Update updates completion cache to be used by Complete.
+// The AUR packages are listed from cache when offline is set or the AUR
+// can not be reached. An existing completion cache is kept when offline.
 func Update(ctx context.Context, httpClient httpRequestDoer,
 	dbExecutor PkgSynchronizer, aurURL, completionPath string, interval int, force bool,
+	cache AURNameLister, offline bool,
 ) error {
 	info, err := os.Stat(completionPath)
+	expired := !offline && interval != -1 && err == nil &&
+		time.Since(info.ModTime()).Hours() >= float64(interval*24)
 
-	if os.IsNotExist(err) || (interval != -1 && time.Since(info.ModTime()).Hours() >= float64(interval*24)) || force {
+	if os.IsNotExist(err) || expired || force {
 		errd := os.MkdirAll(filepath.Dir(completionPath), 0o755)
 		if errd != nil {
 			return errd
@@ -61,10 +73,27 @@ func Update(ctx context.Context, httpClient httpRequestDoer,
 			return errf
 		}
 
-		if createAURList(ctx, httpClient, aurURL, out) != nil {
+		aurList := &bytes.Buffer{}
+
+		var errAUR error
+		if !offline {
+			errAUR = createAURList(ctx, httpClient, aurURL, aurList)
+		}
+
+		if (offline || errAUR != nil) && cache != nil {
+			aurList.Reset()
+			errAUR = createCachedAURList(cache, aurList)
+		}
+
+		if errAUR != nil {
 			defer os.Remove(completionPath)
 		}
 
+		if _, errw := aurList.WriteTo(out); errw != nil {
+			out.Close()
+			return errw
+		}
+
 		erra := createRepoList(dbExecutor, out)
 
 		out.Close()
@@ -117,6 +146,22 @@ func createAURList(ctx context.Context, client httpRequestDoer, aurURL string, o
 	return nil
 }
 
+// createCachedAURList creates a new completion file from the AUR metadata cache.
+func createCachedAURList(cache AURNameLister, out io.Writer) error {
+	names, err := cache.Names()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if _, err := io.WriteString(out, name+"\tAUR\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // createRepoList appends Repo packages to completion cache.
 func createRepoList(dbExecutor PkgSynchronizer, out io.Writer) error {
 	for _, pkg := range dbExecutor.SyncPackages() {
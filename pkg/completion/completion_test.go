@@ -9,9 +9,14 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yay/v12/pkg/db"
 )
 
 const samplePackageResp = `
@@ -97,3 +102,59 @@ func Test_createAURListStatusError(t *testing.T) {
 	err := createAURList(context.Background(), doer, "https://aur.archlinux.org", out)
 	assert.EqualError(t, err, "invalid status code: 503")
 }
+
+type mockLister []string
+
+func (m mockLister) Names() ([]string, error) {
+	return m, nil
+}
+
+type mockSynchronizer struct{}
+
+func (mockSynchronizer) SyncPackages(...string) []db.IPackage {
+	return []db.IPackage{}
+}
+
+func TestUpdate_FromCache(t *testing.T) {
+	t.Parallel()
+
+	unreachable := &mockDoer{
+		t:                t,
+		wantUrl:          "https://aur.archlinux.org/packages.gz",
+		returnStatusCode: 200,
+		returnBody:       samplePackageResp,
+		returnErr:        errors.New("network is unreachable"),
+	}
+	cache := mockLister{"cytadela", "bitefusion"}
+
+	testCases := []struct {
+		desc    string
+		doer    *mockDoer
+		offline bool
+	}{
+		{desc: "offline", doer: nil, offline: true},
+		{desc: "unreachable", doer: unreachable, offline: false},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "completion.cache")
+
+			var doer httpRequestDoer
+			if tc.doer != nil {
+				doer = tc.doer
+			}
+
+			err := Update(context.Background(), doer, mockSynchronizer{},
+				"https://aur.archlinux.org", path, 7, false, cache, tc.offline)
+			require.NoError(t, err)
+
+			got, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, "cytadela\tAUR\nbitefusion\tAUR\n", string(got))
+		})
+	}
+}
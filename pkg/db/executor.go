@@ -49,6 +49,7 @@ type Executor interface {
 	LocalSatisfierExists(string) bool
 	PackageDepends(IPackage) []Depend
 	PackageGroups(IPackage) []string
+	PackageLicenses(IPackage) []string
 	PackageOptionalDepends(IPackage) []Depend
 	PackageProvides(IPackage) []Depend
 	PackagesFromGroup(string) []IPackage
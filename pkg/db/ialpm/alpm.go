@@ -428,6 +428,11 @@ func (ae *AlpmExecutor) PackageGroups(pkg alpm.IPackage) []string {
 	return alpmPackage.Groups().Slice()
 }
 
+func (ae *AlpmExecutor) PackageLicenses(pkg alpm.IPackage) []string {
+	alpmPackage := pkg.(*alpm.Package)
+	return alpmPackage.Licenses().Slice()
+}
+
 // upRepo gathers local packages and checks if they have new versions.
 // Output: Upgrade type package list.
 func (ae *AlpmExecutor) SyncUpgrades(enableDowngrade bool) (
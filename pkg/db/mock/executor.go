@@ -18,6 +18,7 @@ type (
 type DBExecutor struct {
 	db.Executor
 	AlpmArchitecturesFn           func() ([]string, error)
+	BiggestPackagesFn             func() []IPackage
 	InstalledRemotePackageNamesFn func() []string
 	InstalledRemotePackagesFn     func() map[string]IPackage
 	IsCorrectVersionInstalledFn   func(string, string) bool
@@ -25,6 +26,7 @@ type DBExecutor struct {
 	LocalPackagesFn               func() []IPackage
 	LocalSatisfierExistsFn        func(string) bool
 	PackageDependsFn              func(IPackage) []Depend
+	PackageLicensesFn             func(IPackage) []string
 	PackageOptionalDependsFn      func(alpm.IPackage) []alpm.Depend
 	PackageProvidesFn             func(IPackage) []Depend
 	PackagesFromGroupFn           func(string) []IPackage
@@ -61,6 +63,9 @@ func (t *DBExecutor) AlpmArchitectures() ([]string, error) {
 }
 
 func (t *DBExecutor) BiggestPackages() []IPackage {
+	if t.BiggestPackagesFn != nil {
+		return t.BiggestPackagesFn()
+	}
 	panic("implement me")
 }
 
@@ -118,6 +123,14 @@ func (t *DBExecutor) PackageGroups(iPackage IPackage) []string {
 	return []string{}
 }
 
+func (t *DBExecutor) PackageLicenses(iPackage IPackage) []string {
+	if t.PackageLicensesFn != nil {
+		return t.PackageLicensesFn(iPackage)
+	}
+
+	panic("implement me")
+}
+
 func (t *DBExecutor) PackageOptionalDepends(iPackage IPackage) []Depend {
 	if t.PackageOptionalDependsFn != nil {
 		return t.PackageOptionalDependsFn(iPackage)
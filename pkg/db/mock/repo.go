@@ -39,6 +39,11 @@ type Package struct {
 	PReason       alpm.PkgReason
 	PDepends      alpm.IDependList
 	PProvides     alpm.IDependList
+	PConflicts    alpm.IDependList
+	PReplaces     alpm.IDependList
+	PURL          string
+	PFiles        []alpm.File
+	PValidation   alpm.Validation
 }
 
 func (p *Package) Base() string {
@@ -90,7 +95,7 @@ func (p *Package) Base64Signature() string {
 }
 
 func (p *Package) Validation() alpm.Validation {
-	panic("not implemented")
+	return p.PValidation
 }
 
 // Architecture returns the package target Architecture.
@@ -105,7 +110,10 @@ func (p *Package) Backup() alpm.BackupList {
 
 // Conflicts returns the conflicts of the package as a DependList.
 func (p *Package) Conflicts() alpm.IDependList {
-	panic("not implemented")
+	if p.PConflicts != nil {
+		return p.PConflicts
+	}
+	return alpm.DependList{}
 }
 
 // Depends returns the package's dependency list.
@@ -118,22 +126,22 @@ func (p *Package) Depends() alpm.IDependList {
 
 // Depends returns the package's optional dependency list.
 func (p *Package) OptionalDepends() alpm.IDependList {
-	panic("not implemented")
+	return alpm.DependList{}
 }
 
 // Depends returns the package's check dependency list.
 func (p *Package) CheckDepends() alpm.IDependList {
-	panic("not implemented")
+	return alpm.DependList{}
 }
 
 // Depends returns the package's make dependency list.
 func (p *Package) MakeDepends() alpm.IDependList {
-	panic("not implemented")
+	return alpm.DependList{}
 }
 
 // Files returns the file list of the package.
 func (p *Package) Files() []alpm.File {
-	panic("not implemented")
+	return p.PFiles
 }
 
 // ContainsFile checks if the path is in the package filelist.
@@ -143,7 +151,7 @@ func (p *Package) ContainsFile(path string) (alpm.File, error) {
 
 // Groups returns the groups the package belongs to.
 func (p *Package) Groups() alpm.StringList {
-	panic("not implemented")
+	return alpm.StringList{}
 }
 
 // InstallDate returns the package install date.
@@ -153,7 +161,7 @@ func (p *Package) InstallDate() time.Time {
 
 // Licenses returns the package license list.
 func (p *Package) Licenses() alpm.StringList {
-	panic("not implemented")
+	return alpm.StringList{}
 }
 
 // SHA256Sum returns package SHA256Sum.
@@ -186,12 +194,15 @@ func (p *Package) Origin() alpm.PkgFrom {
 
 // Replaces returns a DependList with the packages this package replaces.
 func (p *Package) Replaces() alpm.IDependList {
-	panic("not implemented")
+	if p.PReplaces != nil {
+		return p.PReplaces
+	}
+	return alpm.DependList{}
 }
 
 // URL returns the upstream URL of the package.
 func (p *Package) URL() string {
-	panic("not implemented")
+	return p.PURL
 }
 
 // ComputeRequiredBy returns the names of reverse dependencies of a package.
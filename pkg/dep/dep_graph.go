@@ -26,6 +26,9 @@ type InstallInfo struct {
 	SrcinfoPath  *string
 	AURBase      *string
 	SyncDBName   *string
+	// Replaces is the installed foreign package provided by this one, removed
+	// once this one is installed.
+	Replaces string
 
 	IsGroup bool
 	Upgrade bool
@@ -317,17 +320,6 @@ func (g *Grapher) GraphSyncPkg(ctx context.Context,
 	graph *topo.Graph[string, *InstallInfo],
 	pkg alpm.IPackage, upgradeInfo *db.SyncUpgrade,
 ) *topo.Graph[string, *InstallInfo] {
-	if graph == nil {
-		graph = NewGraph()
-	}
-
-	graph.AddNode(pkg.Name())
-	_ = pkg.Provides().ForEach(func(p *alpm.Depend) error {
-		g.logger.Debugln(pkg.Name() + " provides: " + p.String())
-		graph.Provides(p.Name, p, pkg.Name())
-		return nil
-	})
-
 	dbName := pkg.DB().Name()
 	info := &InstallInfo{
 		Source:     Sync,
@@ -346,6 +338,41 @@ func (g *Grapher) GraphSyncPkg(ctx context.Context,
 		info.LocalVersion = upgradeInfo.LocalVersion
 	}
 
+	return g.graphSyncNode(graph, pkg, info)
+}
+
+// GraphSyncMigration adds a sync package replacing an installed foreign
+// package. It is installed with the reason of the foreign package and shown
+// as an upgrade from its version.
+func (g *Grapher) GraphSyncMigration(ctx context.Context,
+	graph *topo.Graph[string, *InstallInfo],
+	pkg alpm.IPackage, localVersion string, reason alpm.PkgReason,
+) *topo.Graph[string, *InstallInfo] {
+	dbName := pkg.DB().Name()
+
+	return g.graphSyncNode(graph, pkg, &InstallInfo{
+		Source:       Sync,
+		Reason:       Reason(reason),
+		Version:      pkg.Version(),
+		LocalVersion: localVersion,
+		SyncDBName:   &dbName,
+	})
+}
+
+func (g *Grapher) graphSyncNode(graph *topo.Graph[string, *InstallInfo],
+	pkg alpm.IPackage, info *InstallInfo,
+) *topo.Graph[string, *InstallInfo] {
+	if graph == nil {
+		graph = NewGraph()
+	}
+
+	graph.AddNode(pkg.Name())
+	_ = pkg.Provides().ForEach(func(p *alpm.Depend) error {
+		g.logger.Debugln(pkg.Name() + " provides: " + p.String())
+		graph.Provides(p.Name, p, pkg.Name())
+		return nil
+	})
+
 	g.ValidateAndSetNodeInfo(graph, pkg.Name(), &topo.NodeInfo[*InstallInfo]{
 		Color:      colorMap[info.Reason],
 		Background: bgColorMap[info.Source],
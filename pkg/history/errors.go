@@ -0,0 +1,14 @@
+package history
+
+import "github.com/leonelquinteros/gotext"
+
+// ErrInvalidSince is returned for a --since value that is neither an age
+// nor a date.
+type ErrInvalidSince struct {
+	value string
+}
+
+func (e ErrInvalidSince) Error() string {
+	return gotext.Get("invalid --since '%s', expected an age such as 30d, 12h, 2w or 1y, or a date such as 2024-06-01",
+		e.value)
+}
@@ -0,0 +1,244 @@
+// Package history keeps an append-only log of the transactions yay ran,
+// one JSON object per line, and queries it.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Jguer/yay/v12/pkg/text"
+)
+
+// Result is the outcome of a transaction or of one of its packages.
+type Result string
+
+const (
+	ResultSuccess Result = "success"
+	ResultFailed  Result = "failed"
+)
+
+// Package is a package built or installed by a transaction.
+type Package struct {
+	Name string `json:"name"`
+	// Source is AUR, Sync or SRCINFO.
+	Source     string `json:"source"`
+	OldVersion string `json:"oldVersion,omitempty"`
+	NewVersion string `json:"newVersion"`
+	// Commit is the AUR commit the package was built from.
+	Commit string `json:"commit,omitempty"`
+	// BuildSeconds is the makepkg time of the package base.
+	BuildSeconds float64 `json:"buildSeconds,omitempty"`
+	Result       Result  `json:"result"`
+	Error        string  `json:"error,omitempty"`
+}
+
+// Transaction is a single install or upgrade run.
+type Transaction struct {
+	Time     time.Time `json:"time"`
+	Command  []string  `json:"command"`
+	Result   Result    `json:"result"`
+	Error    string    `json:"error,omitempty"`
+	Packages []Package `json:"packages"`
+}
+
+func (t Transaction) Fields() []string {
+	pkgs := make([]string, 0, len(t.Packages))
+	for i := range t.Packages {
+		pkgs = append(pkgs, t.Packages[i].Name+"="+t.Packages[i].NewVersion)
+	}
+
+	return []string{
+		t.Time.Format(time.RFC3339), string(t.Result),
+		strings.Join(t.Command, " "), strings.Join(pkgs, ","),
+	}
+}
+
+// Finish sets the outcome of the transaction from the error it returned.
+func (t *Transaction) Finish(err error) {
+	t.Result = ResultSuccess
+	if err != nil {
+		t.Result = ResultFailed
+		t.Error = err.Error()
+	}
+}
+
+// Log is the history file.
+type Log struct {
+	FilePath string
+
+	logger *text.Logger
+}
+
+func NewLog(filePath string, logger *text.Logger) *Log {
+	return &Log{FilePath: filePath, logger: logger}
+}
+
+// Append adds a transaction to the end of the log.
+func (l *Log) Append(t *Transaction) error {
+	if err := os.MkdirAll(filepath.Dir(l.FilePath), 0o755); err != nil {
+		return err
+	}
+
+	line, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(l.FilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	// a single write keeps concurrent appends from interleaving
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// Read returns the transactions in the log, oldest first. A missing file is
+// an empty log and malformed lines are skipped.
+func (l *Log) Read() ([]Transaction, error) {
+	transactions := []Transaction{}
+
+	file, err := os.Open(l.FilePath)
+	if os.IsNotExist(err) {
+		return transactions, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history '%s': %w", l.FilePath, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+
+		var t Transaction
+		if err := json.Unmarshal(scanner.Bytes(), &t); err != nil {
+			l.logger.Debugln(fmt.Sprintf("skipping malformed history '%s' line %d:", l.FilePath, lineNo), err)
+
+			continue
+		}
+
+		transactions = append(transactions, t)
+	}
+
+	return transactions, scanner.Err()
+}
+
+// Filter keeps the transactions since a time that touched a package. With
+// a package name only that package is kept in each transaction.
+func Filter(transactions []Transaction, pkgName string, since time.Time) []Transaction {
+	filtered := make([]Transaction, 0, len(transactions))
+
+	for i := range transactions {
+		t := transactions[i]
+		if t.Time.Before(since) {
+			continue
+		}
+
+		if pkgName != "" {
+			pkgs := make([]Package, 0, 1)
+
+			for j := range t.Packages {
+				if t.Packages[j].Name == pkgName {
+					pkgs = append(pkgs, t.Packages[j])
+				}
+			}
+
+			if len(pkgs) == 0 {
+				continue
+			}
+
+			t.Packages = pkgs
+		}
+
+		filtered = append(filtered, t)
+	}
+
+	return filtered
+}
+
+var sinceRegex = regexp.MustCompile(`^(\d+)([hdwy])$`)
+
+var sinceUnits = map[string]time.Duration{
+	"h": time.Hour,
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+	"y": 365 * 24 * time.Hour,
+}
+
+// ParseSince parses an age such as 30d or 12h, or a date such as
+// 2024-06-01, into the time it starts at.
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	if match := sinceRegex.FindStringSubmatch(value); match != nil {
+		count, _ := strconv.Atoi(match[1])
+		return now.Add(-time.Duration(count) * sinceUnits[match[2]]), nil
+	}
+
+	if date, err := time.ParseInLocation("2006-01-02", value, now.Location()); err == nil {
+		return date, nil
+	}
+
+	return time.Time{}, ErrInvalidSince{value}
+}
+
+// EstimateBuild returns the duration of the last successful build of a
+// package, if it was ever built.
+func EstimateBuild(transactions []Transaction, pkgName string) (time.Duration, bool) {
+	for i := len(transactions) - 1; i >= 0; i-- {
+		for j := range transactions[i].Packages {
+			pkg := &transactions[i].Packages[j]
+			if pkg.Name == pkgName && pkg.Result == ResultSuccess && pkg.BuildSeconds > 0 {
+				return time.Duration(pkg.BuildSeconds * float64(time.Second)), true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+// GitHead reads the commit checked out in a git clone without running git.
+func GitHead(dir string) string {
+	head, err := os.ReadFile(filepath.Join(dir, ".git", "HEAD"))
+	if err != nil {
+		return ""
+	}
+
+	ref, ok := strings.CutPrefix(strings.TrimSpace(string(head)), "ref: ")
+	if !ok {
+		return ref
+	}
+
+	if commit, err := os.ReadFile(filepath.Join(dir, ".git", ref)); err == nil {
+		return strings.TrimSpace(string(commit))
+	}
+
+	packed, err := os.ReadFile(filepath.Join(dir, ".git", "packed-refs"))
+	if err != nil {
+		return ""
+	}
+
+	for _, line := range strings.Split(string(packed), "\n") {
+		if commit, name, found := strings.Cut(line, " "); found && name == ref {
+			return commit
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,135 @@
+//go:build !integration
+// +build !integration
+
+package history
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yay/v12/pkg/text"
+)
+
+func newTestLogger() *text.Logger {
+	return text.NewLogger(io.Discard, io.Discard, strings.NewReader(""), false, "test")
+}
+
+func TestLog_AppendRead(t *testing.T) {
+	t.Parallel()
+
+	log := NewLog(filepath.Join(t.TempDir(), "state", "history.jsonl"), newTestLogger())
+
+	transactions, err := log.Read()
+	require.NoError(t, err)
+	assert.Empty(t, transactions)
+
+	first := &Transaction{
+		Time:    time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		Command: []string{"yay", "-S", "foo"},
+		Packages: []Package{
+			{Name: "foo", Source: "AUR", NewVersion: "1.0-1", Commit: "abc", BuildSeconds: 42, Result: ResultSuccess},
+		},
+	}
+	first.Finish(nil)
+
+	second := &Transaction{
+		Time:    time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC),
+		Command: []string{"yay", "-Syu"},
+		Packages: []Package{
+			{Name: "bar", Source: "Sync", OldVersion: "1-1", NewVersion: "2-1", Result: ResultSuccess},
+			{Name: "foo", Source: "AUR", OldVersion: "1.0-1", NewVersion: "1.1-1", Result: ResultFailed},
+		},
+	}
+	second.Finish(os.ErrClosed)
+
+	require.NoError(t, log.Append(first))
+	require.NoError(t, log.Append(second))
+
+	transactions, err = log.Read()
+	require.NoError(t, err)
+	require.Len(t, transactions, 2)
+	assert.Equal(t, ResultSuccess, transactions[0].Result)
+	assert.Equal(t, ResultFailed, transactions[1].Result)
+	assert.Equal(t, os.ErrClosed.Error(), transactions[1].Error)
+	assert.Equal(t, *second, transactions[1])
+
+	filtered := Filter(transactions, "foo", time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC))
+	require.Len(t, filtered, 1)
+	assert.Equal(t, []Package{second.Packages[1]}, filtered[0].Packages)
+	assert.Len(t, transactions[1].Packages, 2)
+
+	assert.Empty(t, Filter(transactions, "baz", time.Time{}))
+
+	estimate, ok := EstimateBuild(transactions, "foo")
+	assert.True(t, ok)
+	assert.Equal(t, 42*time.Second, estimate)
+
+	assert.Equal(t, []string{"2024-06-01T10:00:00Z", "failed", "yay -Syu", "bar=2-1,foo=1.1-1"},
+		transactions[1].Fields())
+}
+
+func TestLog_ReadCorrupt(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	require.NoError(t, os.WriteFile(path,
+		[]byte("{\"result\":\"success\"}\n\nnot json\n{\"result\":\"failed\"}\n"), 0o644))
+
+	transactions, err := NewLog(path, newTestLogger()).Read()
+	require.NoError(t, err)
+	require.Len(t, transactions, 2)
+	assert.Equal(t, ResultSuccess, transactions[0].Result)
+	assert.Equal(t, ResultFailed, transactions[1].Result)
+}
+
+func TestParseSince(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	since, err := ParseSince("30d", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.AddDate(0, 0, -30), since)
+
+	since, err = ParseSince("12h", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-12*time.Hour), since)
+
+	since, err = ParseSince("2024-05-01", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), since)
+
+	_, err = ParseSince("a month", now)
+	assert.EqualError(t, err, "invalid --since 'a month', expected an age such as 30d, 12h, 2w or 1y, or a date such as 2024-06-01")
+}
+
+func TestGitHead(t *testing.T) {
+	t.Parallel()
+
+	loose := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(loose, ".git", "refs", "heads"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(loose, ".git", "HEAD"), []byte("ref: refs/heads/master\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(loose, ".git", "refs", "heads", "master"), []byte("1111\n"), 0o644))
+	assert.Equal(t, "1111", GitHead(loose))
+
+	packed := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(packed, ".git"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(packed, ".git", "HEAD"), []byte("ref: refs/heads/master\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(packed, ".git", "packed-refs"),
+		[]byte("# pack-refs with: peeled fully-peeled sorted\n2222 refs/heads/master\n"), 0o644))
+	assert.Equal(t, "2222", GitHead(packed))
+
+	detached := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(detached, ".git"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(detached, ".git", "HEAD"), []byte("3333\n"), 0o644))
+	assert.Equal(t, "3333", GitHead(detached))
+
+	assert.Empty(t, GitHead(t.TempDir()))
+}
@@ -5,16 +5,20 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"os"
 	"strings"
+	"time"
 
 	mapset "github.com/deckarep/golang-set/v2"
 	"github.com/leonelquinteros/gotext"
 
 	"github.com/Jguer/yay/v12/pkg/multierror"
+	"github.com/Jguer/yay/v12/pkg/review"
 	"github.com/Jguer/yay/v12/pkg/runtime"
 	"github.com/Jguer/yay/v12/pkg/settings"
 	"github.com/Jguer/yay/v12/pkg/settings/exe"
 	"github.com/Jguer/yay/v12/pkg/text"
+	"github.com/Jguer/yay/v12/pkg/unattended"
 )
 
 const (
@@ -145,6 +149,92 @@ func updatePkgbuildSeenRef(ctx context.Context, cmdBuilder exe.ICmdBuilder, pkgb
 	return errMulti.Return()
 }
 
+// gitHeadCommit returns the commit checked out in dir.
+func gitHeadCommit(ctx context.Context, cmdBuilder exe.ICmdBuilder, dir string) (string, error) {
+	stdout, stderr, err := cmdBuilder.Capture(
+		cmdBuilder.BuildGitCmd(ctx, dir, "rev-parse", "HEAD"))
+	if err != nil {
+		return "", fmt.Errorf("%s %w", stderr, err)
+	}
+
+	return strings.TrimSpace(stdout), nil
+}
+
+// reviewerName returns the name recorded in the review ledger.
+func reviewerName(cfg *settings.Configuration) string {
+	if cfg.Reviewer != "" {
+		return cfg.Reviewer
+	}
+
+	name := os.Getenv("USER")
+	if hostname, err := os.Hostname(); err == nil {
+		name += "@" + hostname
+	}
+
+	return name
+}
+
+// splitReviewed separates the bases already reviewed at their current commit
+// in the review ledger from the ones still to review.
+func splitReviewed(ctx context.Context, run *runtime.Runtime,
+	pkgbuildDirsByBase map[string]string,
+) (toReview, reviewed []string, commits map[string]string) {
+	toReview = make([]string, 0, len(pkgbuildDirsByBase))
+	reviewed = make([]string, 0)
+	commits = make(map[string]string, len(pkgbuildDirsByBase))
+
+	for base, dir := range pkgbuildDirsByBase {
+		if run.ReviewLedger == nil {
+			toReview = append(toReview, base)
+			continue
+		}
+
+		commit, err := gitHeadCommit(ctx, run.CmdBuilder, dir)
+		if err != nil {
+			run.Logger.Debugln("unable to read HEAD for", base, err)
+			toReview = append(toReview, base)
+
+			continue
+		}
+
+		commits[base] = commit
+
+		if entry, ok := run.ReviewLedger.Get(base, commit); ok {
+			run.Logger.Infoln(gotext.Get("%s: reviewed by %s on %s -- skipping", text.Cyan(base),
+				entry.Reviewer, text.FormatTime(int(entry.Time.Unix()))))
+
+			reviewed = append(reviewed, base)
+
+			continue
+		}
+
+		toReview = append(toReview, base)
+	}
+
+	return toReview, reviewed, commits
+}
+
+// stageReviews holds the reviews of bases back in the review ledger until the
+// bases are installed.
+func stageReviews(run *runtime.Runtime, bases []string, commits map[string]string) {
+	if run.ReviewLedger == nil {
+		return
+	}
+
+	reviewer := reviewerName(run.Cfg)
+	now := time.Now()
+
+	for _, base := range bases {
+		run.ReviewLedger.Stage(review.Entry{
+			Base:     base,
+			Commit:   commits[base],
+			Reviewer: reviewer,
+			Time:     now,
+			Note:     run.Cfg.ReviewNote,
+		})
+	}
+}
+
 func DiffFn(ctx context.Context, run *runtime.Runtime, w io.Writer,
 	pkgbuildDirsByBase map[string]string, installed mapset.Set[string],
 ) error {
@@ -152,9 +242,14 @@ func DiffFn(ctx context.Context, run *runtime.Runtime, w io.Writer,
 		return nil // no work to do
 	}
 
-	bases := make([]string, 0, len(pkgbuildDirsByBase))
-	for base := range pkgbuildDirsByBase {
-		bases = append(bases, base)
+	bases, reviewed, commits := splitReviewed(ctx, run, pkgbuildDirsByBase)
+
+	if errUpd := updatePkgbuildSeenRef(ctx, run.CmdBuilder, pkgbuildDirsByBase, reviewed); errUpd != nil {
+		return errUpd
+	}
+
+	if len(bases) == 0 {
+		return nil
 	}
 
 	toDiff, errMenu := selectionMenu(run.Logger, pkgbuildDirsByBase, bases, installed, gotext.Get("Diffs to show?"),
@@ -177,5 +272,49 @@ func DiffFn(ctx context.Context, run *runtime.Runtime, w io.Writer,
 		return errUpd
 	}
 
+	stageReviews(run, toDiff, commits)
+
+	return nil
+}
+
+// ReviewGateFn replaces the diff and edit menus in unattended runs. Instead of
+// showing the diffs it stops the run if a PKGBUILD changed since it was last
+// reviewed and the change is not in the review ledger.
+func ReviewGateFn(ctx context.Context, run *runtime.Runtime, w io.Writer,
+	pkgbuildDirsByBase map[string]string, installed mapset.Set[string],
+) error {
+	if len(pkgbuildDirsByBase) == 0 {
+		return nil
+	}
+
+	bases, reviewed, _ := splitReviewed(ctx, run, pkgbuildDirsByBase)
+
+	if errUpd := updatePkgbuildSeenRef(ctx, run.CmdBuilder, pkgbuildDirsByBase, reviewed); errUpd != nil {
+		return errUpd
+	}
+
+	conditions := make([]unattended.Condition, 0)
+
+	for _, base := range bases {
+		hasDiff, err := gitHasDiff(ctx, run.CmdBuilder, pkgbuildDirsByBase[base])
+		if err != nil {
+			run.Logger.Debugln("unable to compare with last review", base, err)
+		}
+
+		if !hasDiff && err == nil {
+			continue
+		}
+
+		conditions = append(conditions, unattended.Condition{
+			Kind:    unattended.ConditionDiff,
+			Package: base,
+			Detail:  gotext.Get("PKGBUILD changed since the last review"),
+		})
+	}
+
+	if len(conditions) > 0 {
+		return &unattended.ErrReviewRequired{Conditions: conditions}
+	}
+
 	return nil
 }
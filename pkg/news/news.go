@@ -58,30 +58,72 @@ type rss struct {
 	Channel channel `xml:"channel"`
 }
 
-func PrintNewsFeed(ctx context.Context, client *http.Client, logger *text.Logger,
-	cutOffDate time.Time, bottomUp, all, quiet bool,
-) error {
+func fetchFeed(ctx context.Context, client *http.Client) (*rss, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://archlinux.org/feeds/news", http.NoBody)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	rssGot := rss{}
 
 	d := xml.NewDecoder(bytes.NewReader(body))
 	if err := d.Decode(&rssGot); err != nil {
+		return nil, err
+	}
+
+	return &rssGot, nil
+}
+
+// Headline is a news item published after the cut-off date.
+type Headline struct {
+	Title string
+	Link  string
+	Date  time.Time
+}
+
+// Unread returns the news published after cutOffDate, the same news that
+// PrintNewsFeed shows by default.
+func Unread(ctx context.Context, client *http.Client, cutOffDate time.Time) ([]Headline, error) {
+	rssGot, err := fetchFeed(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	unread := make([]Headline, 0)
+
+	for _, item := range rssGot.Channel.Items {
+		date, err := time.Parse(time.RFC1123Z, item.PubDate)
+		if err != nil || !date.After(cutOffDate) {
+			continue
+		}
+
+		unread = append(unread, Headline{
+			Title: strings.TrimSpace(item.Title),
+			Link:  item.Link,
+			Date:  date,
+		})
+	}
+
+	return unread, nil
+}
+
+func PrintNewsFeed(ctx context.Context, client *http.Client, logger *text.Logger,
+	cutOffDate time.Time, bottomUp, all, quiet bool,
+) error {
+	rssGot, err := fetchFeed(ctx, client)
+	if err != nil {
 		return err
 	}
 
@@ -177,3 +177,19 @@ func TestPrintNewsFeedSameDay(t *testing.T) {
 	out, _ := io.ReadAll(r)
 	cupaloy.SnapshotT(t, out)
 }
+
+func TestUnread(t *testing.T) {
+	cutOff, _ := time.Parse(time.RFC3339, "2020-04-14T13:04:05Z")
+
+	gock.New("https://archlinux.org").
+		Get("/feeds/news").
+		Reply(200).
+		BodyString(lastNews)
+
+	defer gock.Off()
+
+	unread, err := Unread(context.Background(), &http.Client{}, cutOff)
+	assert.NoError(t, err)
+	assert.Len(t, unread, 1)
+	assert.Equal(t, "zn_poly 0.9.2-2 update requires manual intervention", unread[0].Title)
+}
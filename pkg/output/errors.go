@@ -0,0 +1,24 @@
+package output
+
+import "github.com/leonelquinteros/gotext"
+
+type ErrUnknownFormat struct {
+	format string
+}
+
+func (e *ErrUnknownFormat) Error() string {
+	return gotext.Get("unknown output format '%s', expected '%s', '%s' or a template such as '{{.Name}}'",
+		e.format, FormatJSON, FormatTSV)
+}
+
+type ErrTemplate struct {
+	err error
+}
+
+func (e *ErrTemplate) Error() string {
+	return gotext.Get("invalid output template") + ": " + e.err.Error()
+}
+
+func (e *ErrTemplate) Unwrap() error {
+	return e.err
+}
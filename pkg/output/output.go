@@ -0,0 +1,121 @@
+// Package output writes lists of records in machine-readable formats for
+// scripts, status bars and monitoring.
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+const (
+	// FormatJSON writes the records as a JSON array.
+	FormatJSON = "json"
+	// FormatTSV writes one line of tab separated fields per record.
+	FormatTSV = "tsv"
+)
+
+// Record is a value that can be written as a line of TSV.
+type Record interface {
+	Fields() []string
+}
+
+// Formatter writes records in a format given on the command line: json, tsv
+// or a Go template executed once per record, such as '{{.Name}}'.
+type Formatter struct {
+	format   string
+	template *template.Template
+}
+
+// New parses a format.
+func New(format string) (*Formatter, error) {
+	switch format {
+	case FormatJSON, FormatTSV:
+		return &Formatter{format: format}, nil
+	}
+
+	if !strings.Contains(format, "{{") {
+		return nil, &ErrUnknownFormat{format: format}
+	}
+
+	tmpl, err := template.New("format").Option("missingkey=error").Parse(format)
+	if err != nil {
+		return nil, &ErrTemplate{err: err}
+	}
+
+	return &Formatter{format: format, template: tmpl}, nil
+}
+
+// IsJSON returns true if the records are written as JSON.
+func (f *Formatter) IsJSON() bool {
+	return f.format == FormatJSON
+}
+
+// Write writes a list of records.
+func Write[T Record](w io.Writer, f *Formatter, records []T) error {
+	if f.IsJSON() {
+		if records == nil {
+			records = []T{}
+		}
+
+		return writeJSON(w, records)
+	}
+
+	for _, record := range records {
+		if err := f.writeLine(w, record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteOne writes a single record, as an object rather than an array in JSON.
+func WriteOne(w io.Writer, f *Formatter, record Record) error {
+	if f.IsJSON() {
+		return writeJSON(w, record)
+	}
+
+	return f.writeLine(w, record)
+}
+
+func (f *Formatter) writeLine(w io.Writer, record Record) error {
+	if f.template == nil {
+		fields := record.Fields()
+		for i, field := range fields {
+			fields[i] = escapeTSV(field)
+		}
+
+		_, err := fmt.Fprintln(w, strings.Join(fields, "\t"))
+
+		return err
+	}
+
+	var b strings.Builder
+	if err := f.template.Execute(&b, record); err != nil {
+		return &ErrTemplate{err: err}
+	}
+
+	line := b.String()
+	if !strings.HasSuffix(line, "\n") {
+		line += "\n"
+	}
+
+	_, err := io.WriteString(w, line)
+
+	return err
+}
+
+func writeJSON(w io.Writer, value any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(value)
+}
+
+// escapeTSV keeps a field on its line and in its column.
+func escapeTSV(field string) string {
+	return strings.NewReplacer("\t", " ", "\r", " ", "\n", " ").Replace(field)
+}
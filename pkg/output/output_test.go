@@ -0,0 +1,111 @@
+//go:build !integration
+// +build !integration
+
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testRecord struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+func (r testRecord) Fields() []string {
+	return []string{r.Name, r.Version}
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+
+	records := []testRecord{
+		{Name: "yay", Version: "12.0.0-1"},
+		{Name: "with\ttab", Version: "1\n2"},
+	}
+
+	testCases := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: FormatTSV,
+			want:   "yay\t12.0.0-1\nwith tab\t1 2\n",
+		},
+		{
+			format: FormatJSON,
+			want: `[
+  {
+    "name": "yay",
+    "version": "12.0.0-1"
+  },
+  {
+    "name": "with\ttab",
+    "version": "1\n2"
+  }
+]
+`,
+		},
+		{
+			format: "{{.Name}}={{.Version}}",
+			want:   "yay=12.0.0-1\nwith\ttab=1\n2\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.format, func(t *testing.T) {
+			t.Parallel()
+
+			f, err := New(tc.format)
+			require.NoError(t, err)
+
+			var b bytes.Buffer
+			require.NoError(t, Write(&b, f, records))
+			assert.Equal(t, tc.want, b.String())
+		})
+	}
+}
+
+func TestWriteEmptyJSON(t *testing.T) {
+	t.Parallel()
+
+	f, err := New(FormatJSON)
+	require.NoError(t, err)
+
+	var b bytes.Buffer
+	require.NoError(t, Write[testRecord](&b, f, nil))
+	assert.Equal(t, "[]\n", b.String())
+}
+
+func TestWriteOne(t *testing.T) {
+	t.Parallel()
+
+	f, err := New(FormatJSON)
+	require.NoError(t, err)
+
+	var b bytes.Buffer
+	require.NoError(t, WriteOne(&b, f, testRecord{Name: "yay"}))
+	assert.Equal(t, "{\n  \"name\": \"yay\",\n  \"version\": \"\"\n}\n", b.String())
+}
+
+func TestNewErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := New("xml")
+	assert.ErrorAs(t, err, new(*ErrUnknownFormat))
+
+	_, err = New("{{.Name")
+	assert.ErrorAs(t, err, new(*ErrTemplate))
+
+	f, err := New("{{.Missing}}")
+	require.NoError(t, err)
+
+	var b bytes.Buffer
+	err = Write(&b, f, []testRecord{{Name: "yay"}})
+	assert.ErrorAs(t, err, new(*ErrTemplate))
+}
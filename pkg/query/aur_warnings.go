@@ -17,6 +17,12 @@ type AURWarnings struct {
 	OutOfDate  []string
 	Missing    []string
 	LocalNewer []string
+	// Migrations are the packages missing from the AUR that a sync
+	// repository now provides.
+	Migrations []Migration
+	// Successors are the missing packages known to have been replaced,
+	// merged or deleted.
+	Successors []Successor
 
 	log *text.Logger
 }
@@ -40,7 +46,7 @@ func (warnings *AURWarnings) AddToWarnings(remote map[string]alpm.IPackage, aurP
 		warnings.OutOfDate = append(warnings.OutOfDate, name)
 	}
 
-	if !pkg.ShouldIgnore() && !isDevelPackage(pkg) && db.VerCmp(pkg.Version(), aurPkg.Version) > 0 {
+	if !pkg.ShouldIgnore() && !IsDevelPackage(pkg) && db.VerCmp(pkg.Version(), aurPkg.Version) > 0 {
 		left, right := GetVersionDiff(pkg.Version(), aurPkg.Version)
 
 		newerMsg := gotext.Get("%s: local (%s) is newer than AUR (%s)",
@@ -52,12 +58,74 @@ func (warnings *AURWarnings) AddToWarnings(remote map[string]alpm.IPackage, aurP
 	}
 }
 
-func (warnings *AURWarnings) CalculateMissing(remoteNames []string,
+// Migration is a foreign package that moved from the AUR to a sync
+// repository, possibly under another name.
+type Migration struct {
+	Name         string
+	LocalVersion string
+	Reason       alpm.PkgReason
+	// Package is the sync package replacing it.
+	Package alpm.IPackage
+	// Replaces is false for a sync package that only provides the foreign
+	// package under another name. Such a package is reported but not
+	// migrated to.
+	Replaces bool
+}
+
+// String describes where the package moved to, such as extra/foo.
+func (m *Migration) String() string {
+	return m.Package.DB().Name() + "/" + m.Package.Name()
+}
+
+// findMigration looks for the sync package named as or providing a foreign
+// package. A provider under another name only replaces the foreign package
+// if it declares so with replaces or conflicts.
+func findMigration(dbExecutor db.Executor, pkg alpm.IPackage) *Migration {
+	migration := &Migration{
+		Name:         pkg.Name(),
+		LocalVersion: pkg.Version(),
+		Reason:       pkg.Reason(),
+		Package:      dbExecutor.SyncPackage(pkg.Name()),
+		Replaces:     true,
+	}
+
+	if migration.Package == nil {
+		migration.Package = dbExecutor.SyncSatisfier(pkg.Name())
+		if migration.Package == nil {
+			return nil
+		}
+
+		migration.Replaces = listsName(migration.Package.Replaces(), pkg.Name()) ||
+			listsName(migration.Package.Conflicts(), pkg.Name())
+	}
+
+	return migration
+}
+
+// listsName reports whether a dependency list names a package.
+func listsName(deps alpm.IDependList, name string) bool {
+	for _, dep := range deps.Slice() {
+		if dep.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CalculateMissing finds the foreign packages that are not in the AUR. Those
+// now available in a sync repository are migrations rather than missing.
+func (warnings *AURWarnings) CalculateMissing(dbExecutor db.Executor, remoteNames []string,
 	remote map[string]alpm.IPackage, aurData map[string]*aur.Pkg,
 ) {
 	for _, name := range remoteNames {
 		if _, ok := aurData[name]; !ok && !remote[name].ShouldIgnore() {
 			if _, ok := aurData[strings.TrimSuffix(name, "-debug")]; !ok {
+				if migration := findMigration(dbExecutor, remote[name]); migration != nil {
+					warnings.Migrations = append(warnings.Migrations, *migration)
+					continue
+				}
+
 				warnings.Missing = append(warnings.Missing, name)
 			}
 		}
@@ -83,6 +151,46 @@ func (warnings *AURWarnings) Print() {
 		warnings.log.Warnln(gotext.Get("Flagged Out Of Date AUR Packages:"), formatNames(warnings.OutOfDate))
 	}
 
+	for i := range warnings.Successors {
+		successor := &warnings.Successors[i]
+
+		switch successor.Kind {
+		case SuccessorReplaced:
+			warnings.log.Warnln(gotext.Get("%s: replaced by %s in the AUR",
+				text.Cyan(successor.Name), text.Cyan(successor.Package.Name)))
+		case SuccessorMerged:
+			warnings.log.Warnln(gotext.Get("%s: merged into %s in the AUR",
+				text.Cyan(successor.Name), text.Cyan(successor.Package.Name)))
+		default:
+			warnings.log.Warnln(gotext.Get("%s: deleted from the AUR", text.Cyan(successor.Name)))
+		}
+
+		if successor.Comment != "" {
+			warnings.log.Println("    " + successor.Comment)
+		}
+	}
+
+	replaced := 0
+
+	for i := range warnings.Migrations {
+		migration := &warnings.Migrations[i]
+		if !migration.Replaces {
+			warnings.log.Warnln(gotext.Get("%s: provided by %s",
+				text.Cyan(migration.Name), text.Cyan(migration.String())))
+
+			continue
+		}
+
+		replaced++
+
+		warnings.log.Warnln(gotext.Get("%s: now available in %s",
+			text.Cyan(migration.Name), text.Cyan(migration.String())))
+	}
+
+	if replaced > 0 {
+		warnings.log.Infoln(gotext.Get("Use -Syu --migrate to replace them with the repository packages"))
+	}
+
 	if len(warnings.LocalNewer) > 0 {
 		for _, newer := range warnings.LocalNewer {
 			warnings.log.Warnln(newer)
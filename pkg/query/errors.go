@@ -19,3 +19,50 @@ type ErrNoQuery struct{}
 func (e ErrNoQuery) Error() string {
 	return gotext.Get("no query was executed")
 }
+
+// ErrInvalidQualifier means that a search qualifier could not be parsed.
+type ErrInvalidQualifier struct {
+	qualifier string
+	reason    string
+}
+
+func (e ErrInvalidQualifier) Error() string {
+	return gotext.Get("invalid search qualifier '%s': %s", e.qualifier, e.reason)
+}
+
+// ErrNoSearchTerm means that a search only has qualifiers filtering the results
+// of the AUR, which can not be listed without a search term.
+type ErrNoSearchTerm struct{}
+
+func (e ErrNoSearchTerm) Error() string {
+	return gotext.Get("a search term or a qualifier searchable on the AUR is needed to search the AUR")
+}
+
+// ErrNotInstalled means that the root of a dependency tree is not installed.
+type ErrNotInstalled struct {
+	name string
+}
+
+func (e ErrNotInstalled) Error() string {
+	return gotext.Get("package '%s' was not found", e.name)
+}
+
+// ErrInvalidTreeDepth means that the --depth of a dependency tree is not a
+// positive number.
+type ErrInvalidTreeDepth struct {
+	value string
+}
+
+func (e ErrInvalidTreeDepth) Error() string {
+	return gotext.Get("invalid --depth '%s', expected a number of levels", e.value)
+}
+
+// ErrInvalidTreeFormat means that a dependency tree can not be written in
+// the requested format.
+type ErrInvalidTreeFormat struct {
+	format string
+}
+
+func (e ErrInvalidTreeFormat) Error() string {
+	return gotext.Get("invalid --tree format '%s', expected tree, dot or json", e.format)
+}
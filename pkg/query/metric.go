@@ -2,31 +2,76 @@ package query
 
 import (
 	"hash/fnv"
+	"math"
 	"strings"
+	"time"
 
 	"github.com/adrg/strutil"
+	"github.com/leonelquinteros/gotext"
 )
 
-const minVotes = 30
+const (
+	minVotes = 30
+	// popularity at which an AUR package gets half of the popularity weight
+	halfPopularity = 1.0
+	// age at which an AUR package gets half of the recency weight
+	recencyHalfLife = 365 * 24 * time.Hour
+)
 
-// TODO: Add support for Popularity and LastModified
-func (a *abstractResults) aurSortByMetric(pkg *abstractResult) float64 {
-	return 1 - (minVotes / (minVotes + float64(pkg.votes)))
+// rankScore is the breakdown of the score of a search result. The
+// out-of-date and orphaned penalties are subtracted from the total.
+type rankScore struct {
+	exact       float64
+	name        float64
+	description float64
+	votes       float64
+	popularity  float64
+	recency     float64
+	provides    float64
+	outOfDate   float64
+	orphaned    float64
+	source      float64
 }
 
-func (a *abstractResults) GetMetric(pkg *abstractResult) float64 {
-	if v, ok := a.distanceCache[pkg.name]; ok {
-		return v
+func (r *rankScore) total() float64 {
+	return r.exact + r.name + r.description + r.votes + r.popularity + r.recency +
+		r.provides - r.outOfDate - r.orphaned + r.source
+}
+
+func (r *rankScore) explain() string {
+	return gotext.Get("score %.3f: exact %+.3f, name %+.3f, description %+.3f, votes %+.3f, popularity %+.3f, "+
+		"recency %+.3f, provides %+.3f, out-of-date -%.3f, orphaned -%.3f, source %+.3f",
+		r.total(), r.exact, r.name, r.description, r.votes, r.popularity,
+		r.recency, r.provides, r.outOfDate, r.orphaned, r.source)
+}
+
+func (a *abstractResults) rank(pkg *abstractResult) *rankScore {
+	key := pkg.source + "/" + pkg.name
+	if score, ok := a.rankCache[key]; ok {
+		return score
 	}
 
-	if strings.EqualFold(pkg.name, a.search) {
-		return 1.0
+	exact := strings.EqualFold(pkg.name, a.search)
+	score := &rankScore{
+		description: strutil.Similarity(pkg.description, a.search, a.metric) * a.weights.Description,
+		votes:       a.weights.Votes,
+		popularity:  a.weights.Popularity,
+		recency:     a.weights.Recency,
 	}
 
-	sim := strutil.Similarity(pkg.name, a.search, a.metric)
+	// an exact name match outranks every other result of its source
+	sim := 1.0
+	if exact {
+		score.exact = a.exactBoost()
+	} else {
+		sim = strutil.Similarity(pkg.name, a.search, a.metric)
+	}
 
 	for _, prov := range pkg.provides {
-		// If the package provides search, it's a perfect match
+		if strings.EqualFold(prov, a.search) {
+			score.provides = a.weights.Provides
+		}
+
 		// AUR packages don't populate provides
 		candidate := strutil.Similarity(prov, a.search, a.metric) * 0.80
 		if candidate > sim {
@@ -34,27 +79,46 @@ func (a *abstractResults) GetMetric(pkg *abstractResult) float64 {
 		}
 	}
 
-	simDesc := strutil.Similarity(pkg.description, a.search, a.metric)
+	score.name = sim * a.weights.Name
 
 	// slightly overweight sync sources by always giving them max popularity
-	popularity := 1.0
 	if pkg.source == sourceAUR {
-		popularity = a.aurSortByMetric(pkg)
+		score.votes *= 1 - (minVotes / (minVotes + float64(pkg.votes)))
+		score.popularity *= pkg.popularity / (pkg.popularity + halfPopularity)
+
+		age := a.now.Sub(time.Unix(int64(pkg.lastModified), 0))
+		score.recency *= math.Pow(0.5, math.Max(age.Hours(), 0)/recencyHalfLife.Hours())
+
+		if pkg.outOfDate {
+			score.outOfDate = a.weights.OutOfDate
+		}
+
+		if pkg.orphaned {
+			score.orphaned = a.weights.Orphaned
+		}
 	}
 
-	sim = sim*0.5 + simDesc*0.2 + popularity*0.3
+	score.source = a.separateSourceScore(pkg.source, exact)
+	a.rankCache[key] = score
+
+	return score
+}
 
-	a.distanceCache[pkg.name] = sim
+// exactBoost is the most a result can score without matching the search
+// exactly.
+func (a *abstractResults) exactBoost() float64 {
+	w := &a.weights
 
-	return sim
+	return math.Max(w.Name, 0) + math.Max(w.Description, 0) + math.Max(w.Votes, 0) +
+		math.Max(w.Popularity, 0) + math.Max(w.Recency, 0) + math.Max(w.Provides, 0)
 }
 
-func (a *abstractResults) separateSourceScore(source string, score float64) float64 {
+func (a *abstractResults) separateSourceScore(source string, exact bool) float64 {
 	if !a.separateSources {
 		return 0
 	}
 
-	if score == 1.0 {
+	if exact {
 		return 50
 	}
 
@@ -84,6 +148,5 @@ func (a *abstractResults) separateSourceScore(source string, score float64) floa
 }
 
 func (a *abstractResults) calculateMetric(pkg *abstractResult) float64 {
-	score := a.GetMetric(pkg)
-	return a.separateSourceScore(pkg.source, score) + score
+	return a.rank(pkg).total()
 }
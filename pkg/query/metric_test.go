@@ -0,0 +1,104 @@
+//go:build !integration
+// +build !integration
+
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/adrg/strutil/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yay/v12/pkg/settings"
+)
+
+func newTestResults(search string, weights settings.RankWeights, now time.Time) *abstractResults {
+	return &abstractResults{
+		search:              search,
+		metric:              &metrics.Hamming{CaseSensitive: false},
+		weights:             weights,
+		now:                 now,
+		rankCache:           map[string]*rankScore{},
+		separateSourceCache: map[string]float64{},
+	}
+}
+
+func TestRank(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	weights := settings.DefaultRankWeights()
+	require.Positive(t, weights.Popularity, "popularity ranks by default")
+	require.Positive(t, weights.Recency, "recency ranks by default")
+	results := newTestResults("editor", weights, now)
+
+	fresh := &abstractResult{
+		source: sourceAUR, name: "editor-fresh", votes: 30, popularity: 1,
+		lastModified: int(now.Unix()),
+	}
+	stale := &abstractResult{
+		source: sourceAUR, name: "editor-stale", votes: 30, popularity: 1,
+		lastModified: int(now.Add(-recencyHalfLife).Unix()), outOfDate: true, orphaned: true,
+	}
+	provider := &abstractResult{
+		source: sourceAUR, name: "editor-provi", votes: 30, popularity: 1,
+		lastModified: int(now.Unix()), provides: []string{"editor"},
+	}
+	repo := &abstractResult{source: "extra", name: "editor-repos", votes: -1}
+
+	freshScore := results.rank(fresh)
+	assert.InDelta(t, weights.Votes/2, freshScore.votes, 1e-9)
+	assert.InDelta(t, weights.Popularity/2, freshScore.popularity, 1e-9)
+	assert.InDelta(t, weights.Recency, freshScore.recency, 1e-9)
+
+	staleScore := results.rank(stale)
+	assert.InDelta(t, weights.Recency/2, staleScore.recency, 1e-9)
+	assert.InDelta(t, weights.OutOfDate, staleScore.outOfDate, 1e-9)
+	assert.InDelta(t, weights.Orphaned, staleScore.orphaned, 1e-9)
+
+	providerScore := results.rank(provider)
+	assert.InDelta(t, weights.Provides, providerScore.provides, 1e-9)
+	assert.InDelta(t, 0.8*weights.Name, providerScore.name, 1e-9)
+
+	repoScore := results.rank(repo)
+	assert.InDelta(t, weights.Votes+weights.Popularity+weights.Recency,
+		repoScore.votes+repoScore.popularity+repoScore.recency, 1e-9)
+
+	assert.Greater(t, results.calculateMetric(fresh), results.calculateMetric(stale))
+	assert.Greater(t, results.calculateMetric(provider), results.calculateMetric(fresh))
+}
+
+func TestRank_Weights(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	pkg := &abstractResult{source: sourceAUR, name: "foo", votes: 1000, lastModified: int(now.Unix()), outOfDate: true}
+
+	score := newTestResults("foo", settings.RankWeights{OutOfDate: 1}, now).rank(pkg)
+	assert.InDelta(t, -1, score.total(), 1e-9)
+
+	score = newTestResults("foo", settings.RankWeights{Name: 2}, now).rank(pkg)
+	assert.InDelta(t, 4, score.total(), 1e-9)
+	assert.Equal(t, "score 4.000: exact +2.000, name +2.000, description +0.000, votes +0.000, popularity +0.000, "+
+		"recency +0.000, provides +0.000, out-of-date -0.000, orphaned -0.000, source +0.000", score.explain())
+}
+
+func TestRank_ExactMatchFirst(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	weights := settings.DefaultRankWeights()
+	results := newTestResults("foo", weights, now)
+
+	exact := &abstractResult{
+		source: sourceAUR, name: "foo", votes: 1, lastModified: int(now.AddDate(-5, 0, 0).Unix()),
+		outOfDate: true, orphaned: true,
+	}
+	popular := &abstractResult{
+		source: "extra", name: "foo-bar", description: "foo", provides: []string{"foo"},
+	}
+
+	assert.Greater(t, results.calculateMetric(exact), results.calculateMetric(popular))
+}
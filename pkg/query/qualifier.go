@@ -0,0 +1,364 @@
+package query
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Jguer/aur"
+	"github.com/Jguer/go-alpm/v2"
+	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yay/v12/pkg/db"
+)
+
+// Qualifiers that only filter the results and can not be searched for.
+const (
+	qualifierVotes      = "votes"
+	qualifierPopularity = "popularity"
+	qualifierOutOfDate  = "outofdate"
+	qualifierUpdated    = "updated"
+	qualifierRepo       = "repo"
+)
+
+// aurOnlyQualifiers hold fields repo packages do not have, so qualifying a
+// search with them only shows AUR packages.
+var aurOnlyQualifiers = mapset.NewThreadUnsafeSet(
+	"maintainer", "submitter", "keywords", "comaintainers",
+	qualifierVotes, qualifierPopularity, qualifierOutOfDate, qualifierUpdated)
+
+var (
+	qualifierRegex = regexp.MustCompile(`^([a-z]+):([^:].*)?$`)
+	ageRegex       = regexp.MustCompile(`^(\d+)([hdwy])$`)
+)
+
+var ageUnits = map[string]time.Duration{
+	"h": time.Hour,
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+	"y": 365 * 24 * time.Hour,
+}
+
+// qualifier narrows a search to the packages whose field matches a value,
+// such as maintainer:foo or votes:>50.
+type qualifier struct {
+	field string
+	op    string
+	value string
+	// number is the value of numeric qualifiers. For updated it is the time
+	// of the last modification as a unix timestamp.
+	number float64
+}
+
+// searchQuery is a search split into its keywords and its qualifiers.
+type searchQuery struct {
+	keywords []string
+	// server is the qualifier the AUR is searched by, if any.
+	server  *qualifier
+	filters []qualifier
+	repos   mapset.Set[string]
+}
+
+// parseSearch splits the qualifiers out of the search terms. The first
+// qualifier on a field the AUR can be searched by is used for the AUR query.
+func parseSearch(terms []string, now time.Time) (*searchQuery, error) {
+	query := &searchQuery{
+		keywords: make([]string, 0, len(terms)),
+		filters:  []qualifier{},
+		repos:    mapset.NewThreadUnsafeSet[string](),
+	}
+
+	for _, term := range terms {
+		// terms such as http://... or foo:bar are searched for as they are
+		match := qualifierRegex.FindStringSubmatch(term)
+		if match == nil || !isQualifier(match[1]) {
+			query.keywords = append(query.keywords, term)
+			continue
+		}
+
+		q, err := parseQualifier(term, match[1], match[2], now)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case q.field == qualifierRepo:
+			query.repos.Add(q.value)
+		case query.server == nil && getSearchBy(q.field) != aur.NameDesc:
+			query.server = q
+		default:
+			query.filters = append(query.filters, *q)
+		}
+	}
+
+	return query, nil
+}
+
+func parseQualifier(term, field, value string, now time.Time) (*qualifier, error) {
+	if value == "" {
+		return nil, ErrInvalidQualifier{term, gotext.Get("missing value")}
+	}
+
+	q := &qualifier{field: field, op: "=", value: value}
+
+	switch field {
+	case qualifierVotes, qualifierPopularity:
+		q.op, value = splitOperator(value)
+
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, ErrInvalidQualifier{term, gotext.Get("'%s' is not a number", value)}
+		}
+
+		q.number = number
+	case qualifierOutOfDate:
+		switch value {
+		case "yes":
+			q.number = 1
+		case "no":
+			q.number = 0
+		default:
+			return nil, ErrInvalidQualifier{term, gotext.Get("expected yes or no")}
+		}
+	case qualifierUpdated:
+		op, age := splitOperator(value)
+
+		match := ageRegex.FindStringSubmatch(age)
+		if match == nil || op == "=" {
+			return nil, ErrInvalidQualifier{term,
+				gotext.Get("expected an age such as <90d or >2w, in hours, days, weeks or years")}
+		}
+
+		count, _ := strconv.Atoi(match[1])
+
+		// a package updated less than an age ago was modified after the cutoff
+		q.op = invertOperator(op)
+		q.number = float64(now.Add(-time.Duration(count) * ageUnits[match[2]]).Unix())
+	}
+
+	return q, nil
+}
+
+// isQualifier reports whether field names a search qualifier.
+func isQualifier(field string) bool {
+	switch field {
+	case qualifierVotes, qualifierPopularity, qualifierOutOfDate, qualifierUpdated, qualifierRepo:
+		return true
+	}
+
+	return getSearchBy(field) != aur.NameDesc
+}
+
+func splitOperator(value string) (op, rest string) {
+	for _, op := range []string{">=", "<=", ">", "<", "="} {
+		if rest, ok := strings.CutPrefix(value, op); ok {
+			return op, rest
+		}
+	}
+
+	return "=", value
+}
+
+func invertOperator(op string) string {
+	switch op {
+	case ">":
+		return "<"
+	case ">=":
+		return "<="
+	case "<":
+		return ">"
+	case "<=":
+		return ">="
+	}
+
+	return op
+}
+
+func (q *qualifier) compare(value float64) bool {
+	switch q.op {
+	case ">":
+		return value > q.number
+	case ">=":
+		return value >= q.number
+	case "<":
+		return value < q.number
+	case "<=":
+		return value <= q.number
+	}
+
+	return value == q.number
+}
+
+// matchAUR reports whether an AUR package satisfies the qualifier.
+func (q *qualifier) matchAUR(pkg *aur.Pkg) bool {
+	switch q.field {
+	case "name":
+		return strings.Contains(strings.ToLower(pkg.Name), strings.ToLower(q.value))
+	case "maintainer":
+		return strings.EqualFold(pkg.Maintainer, q.value)
+	case "submitter":
+		return strings.EqualFold(pkg.Submitter, q.value)
+	case "depends":
+		return q.matchDepends(pkg.Depends)
+	case "makedepends":
+		return q.matchDepends(pkg.MakeDepends)
+	case "optdepends":
+		return q.matchDepends(pkg.OptDepends)
+	case "checkdepends":
+		return q.matchDepends(pkg.CheckDepends)
+	case "provides":
+		return q.matchDepends(pkg.Provides)
+	case "conflicts":
+		return q.matchDepends(pkg.Conflicts)
+	case "replaces":
+		return q.matchDepends(pkg.Replaces)
+	case "groups":
+		return q.matchAny(pkg.Groups)
+	case "keywords":
+		return q.matchAny(pkg.Keywords)
+	case "comaintainers":
+		return q.matchAny(pkg.CoMaintainers)
+	case qualifierVotes:
+		return q.compare(float64(pkg.NumVotes))
+	case qualifierPopularity:
+		return q.compare(pkg.Popularity)
+	case qualifierOutOfDate:
+		return (pkg.OutOfDate != 0) == (q.number == 1)
+	case qualifierUpdated:
+		return q.compare(float64(pkg.LastModified))
+	}
+
+	return true
+}
+
+// matchRepo reports whether a repo package satisfies the qualifier.
+// Qualifiers on fields only AUR packages have never match.
+func (q *qualifier) matchRepo(pkg db.IPackage) bool {
+	switch q.field {
+	case "name":
+		return strings.Contains(strings.ToLower(pkg.Name()), strings.ToLower(q.value))
+	case "depends":
+		return q.matchDependList(pkg.Depends())
+	case "makedepends":
+		return q.matchDependList(pkg.MakeDepends())
+	case "optdepends":
+		return q.matchDependList(pkg.OptionalDepends())
+	case "checkdepends":
+		return q.matchDependList(pkg.CheckDepends())
+	case "provides":
+		return q.matchDependList(pkg.Provides())
+	case "conflicts":
+		return q.matchDependList(pkg.Conflicts())
+	case "replaces":
+		return q.matchDependList(pkg.Replaces())
+	case "groups":
+		return q.matchAny(pkg.Groups().Slice())
+	}
+
+	return !aurOnlyQualifiers.Contains(q.field)
+}
+
+func (q *qualifier) matchAny(values []string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, q.value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchDepends matches the names of dependencies such as "foo>=1.0" or
+// "foo: optional feature".
+func (q *qualifier) matchDepends(deps []string) bool {
+	for _, dep := range deps {
+		if i := strings.IndexAny(dep, "<>=:"); i != -1 {
+			dep = dep[:i]
+		}
+
+		if strings.EqualFold(strings.TrimSpace(dep), q.value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (q *qualifier) matchDependList(deps alpm.IDependList) bool {
+	for _, dep := range deps.Slice() {
+		if strings.EqualFold(dep.Name, q.value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// aurOnly reports whether the query can only match AUR packages.
+func (s *searchQuery) aurOnly() bool {
+	if s.server != nil && aurOnlyQualifiers.Contains(s.server.field) {
+		return true
+	}
+
+	for i := range s.filters {
+		if aurOnlyQualifiers.Contains(s.filters[i].field) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// includesSource reports whether results from a source are wanted.
+func (s *searchQuery) includesSource(source string) bool {
+	return s.repos.Cardinality() == 0 || s.repos.Contains(source)
+}
+
+func (s *searchQuery) matchAUR(pkg *aur.Pkg) bool {
+	for i := range s.filters {
+		if !s.filters[i].matchAUR(pkg) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *searchQuery) matchRepo(pkg db.IPackage) bool {
+	if s.server != nil && !s.server.matchRepo(pkg) {
+		return false
+	}
+
+	for i := range s.filters {
+		if !s.filters[i].matchRepo(pkg) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// qualified reports whether the search has any qualifiers.
+func (s *searchQuery) qualified() bool {
+	return s.server != nil || len(s.filters) != 0 || s.repos.Cardinality() != 0
+}
+
+// validateRepos checks the repo qualifiers name the AUR or a sync database.
+func (s *searchQuery) validateRepos(dbExecutor db.Executor) error {
+	if s.repos.Cardinality() == 0 {
+		return nil
+	}
+
+	known := mapset.NewThreadUnsafeSet(dbExecutor.Repos()...)
+	known.Add(sourceAUR)
+
+	for _, repo := range s.repos.ToSlice() {
+		if !known.Contains(repo) {
+			return ErrInvalidQualifier{qualifierRepo + ":" + repo, gotext.Get("unknown repository '%s'", repo)}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,169 @@
+//go:build !integration
+// +build !integration
+
+package query
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Jguer/aur"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yay/v12/pkg/db/mock"
+	mockaur "github.com/Jguer/yay/v12/pkg/dep/mock"
+	"github.com/Jguer/yay/v12/pkg/settings"
+	"github.com/Jguer/yay/v12/pkg/settings/parser"
+	"github.com/Jguer/yay/v12/pkg/text"
+)
+
+func TestParseSearch(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	search, err := parseSearch([]string{
+		"editor", "maintainer:foo", "votes:>50", "outofdate:no",
+		"updated:<90d", "repo:aur", "provides:vi", "Foo::Bar",
+		"http://example.org", "colour:red", "perl:",
+	}, now)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"editor", "Foo::Bar", "http://example.org", "colour:red", "perl:"}, search.keywords)
+	assert.Equal(t, &qualifier{field: "maintainer", op: "=", value: "foo"}, search.server)
+	assert.Equal(t, []qualifier{
+		{field: "votes", op: ">", value: ">50", number: 50},
+		{field: "outofdate", op: "=", value: "no", number: 0},
+		{field: "updated", op: ">", value: "<90d", number: float64(now.AddDate(0, 0, -90).Unix())},
+		{field: "provides", op: "=", value: "vi"},
+	}, search.filters)
+	assert.True(t, search.repos.Contains("aur"))
+	assert.True(t, search.aurOnly())
+}
+
+func TestParseSearch_Invalid(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		term    string
+		wantErr string
+	}{
+		{"maintainer:", "invalid search qualifier 'maintainer:': missing value"},
+		{"votes:>many", "invalid search qualifier 'votes:>many': 'many' is not a number"},
+		{"outofdate:maybe", "invalid search qualifier 'outofdate:maybe': expected yes or no"},
+		{"updated:90d", "invalid search qualifier 'updated:90d': expected an age such as <90d or >2w, in hours, days, weeks or years"},
+		{"updated:<3m", "invalid search qualifier 'updated:<3m': expected an age such as <90d or >2w, in hours, days, weeks or years"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.term, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := parseSearch([]string{"editor", tc.term}, time.Now())
+			assert.EqualError(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestSourceQueryBuilder_Qualifiers(t *testing.T) {
+	t.Parallel()
+
+	recent := int(time.Now().AddDate(0, 0, -10).Unix())
+	old := int(time.Now().AddDate(-2, 0, 0).Unix())
+
+	aurPkgs := []aur.Pkg{
+		{Name: "foo-editor", Description: "An editor", Maintainer: "foo", NumVotes: 120, LastModified: recent},
+		{Name: "foo-editor-git", Description: "An editor", Maintainer: "foo", NumVotes: 3, LastModified: recent},
+		{Name: "foo-old-editor", Description: "An editor", Maintainer: "foo", NumVotes: 80, LastModified: old},
+		{Name: "foo-stale-editor", Description: "An editor", Maintainer: "foo", NumVotes: 90, LastModified: recent, OutOfDate: recent},
+		{Name: "foo-viewer", Description: "A viewer", Maintainer: "foo", NumVotes: 200, LastModified: recent},
+	}
+
+	mockDB := &mock.DBExecutor{
+		ReposFn: func() []string { return []string{"core", "extra"} },
+		SyncPackagesFn: func(pkgs ...string) []mock.IPackage {
+			return []mock.IPackage{
+				&mock.Package{PName: "vim", PDescription: "An editor", PDB: mock.NewDB("extra")},
+				&mock.Package{PName: "nano", PDescription: "An editor", PDB: mock.NewDB("core")},
+			}
+		},
+		LocalPackageFn: func(string) mock.IPackage { return nil },
+	}
+
+	testCases := []struct {
+		desc        string
+		search      []string
+		wantQuery   *aur.Query
+		wantResults []string
+		wantErr     string
+	}{
+		{
+			desc:        "server-side maintainer with client-side filters",
+			search:      []string{"editor", "maintainer:foo", "votes:>50", "outofdate:no", "updated:<90d"},
+			wantQuery:   &aur.Query{Needles: []string{"foo"}, By: aur.Maintainer, Contains: true},
+			wantResults: []string{"foo-editor"},
+		},
+		{
+			desc:        "repo restricts the sources",
+			search:      []string{"editor", "repo:extra"},
+			wantResults: []string{"vim"},
+		},
+		{
+			desc:        "repo aur excludes the repos",
+			search:      []string{"editor", "repo:aur", "votes:<10"},
+			wantQuery:   &aur.Query{Needles: []string{"editor"}, By: aur.NameDesc, Contains: true},
+			wantResults: []string{"foo-editor-git"},
+		},
+		{
+			desc:    "unknown repository",
+			search:  []string{"editor", "repo:testing"},
+			wantErr: "invalid search qualifier 'repo:testing': unknown repository 'testing'",
+		},
+		{
+			desc:    "only client-side qualifiers",
+			search:  []string{"votes:>50"},
+			wantErr: ErrNoSearchTerm{}.Error(),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var gotQuery *aur.Query
+
+			mockAUR := &mockaur.MockAUR{
+				GetFn: func(ctx context.Context, query *aur.Query) ([]aur.Pkg, error) {
+					gotQuery = query
+					return aurPkgs, nil
+				},
+			}
+
+			queryBuilder := NewSourceQueryBuilder(mockAUR,
+				text.NewLogger(io.Discard, io.Discard, strings.NewReader(""), false, "test"),
+				"name", parser.ModeAny, "", false, false, false, settings.DefaultRankWeights(), false)
+
+			err := queryBuilder.Execute(context.Background(), mockDB, tc.search)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantQuery, gotQuery)
+
+			names := make([]string, 0, len(queryBuilder.results))
+			for i := range queryBuilder.results {
+				names = append(names, queryBuilder.results[i].name)
+			}
+
+			assert.ElementsMatch(t, tc.wantResults, names)
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 	"unicode"
 
 	"github.com/Jguer/aur"
@@ -16,6 +17,7 @@ import (
 
 	"github.com/Jguer/yay/v12/pkg/db"
 	"github.com/Jguer/yay/v12/pkg/intrange"
+	"github.com/Jguer/yay/v12/pkg/settings"
 	"github.com/Jguer/yay/v12/pkg/settings/parser"
 	"github.com/Jguer/yay/v12/pkg/text"
 )
@@ -33,8 +35,9 @@ const (
 
 type Builder interface {
 	Len() int
-	Execute(ctx context.Context, dbExecutor db.Executor, pkgS []string)
+	Execute(ctx context.Context, dbExecutor db.Executor, pkgS []string) error
 	Results(dbExecutor db.Executor, verboseSearch SearchVerbosity) error
+	Records(dbExecutor db.Executor) []PackageRecord
 	GetTargets(include, exclude intrange.IntRanges, otherExclude mapset.Set[string]) ([]string, error)
 }
 
@@ -47,6 +50,9 @@ type SourceQueryBuilder struct {
 	bottomUp          bool
 	singleLineResults bool
 	separateSources   bool
+	rankWeights       settings.RankWeights
+	explainRank       bool
+	ranks             map[string]*rankScore
 
 	aurClient aur.QueryClient
 	logger    *text.Logger
@@ -61,6 +67,8 @@ func NewSourceQueryBuilder(
 	bottomUp,
 	singleLineResults bool,
 	separateSources bool,
+	rankWeights settings.RankWeights,
+	explainRank bool,
 ) *SourceQueryBuilder {
 	return &SourceQueryBuilder{
 		aurClient:         aurClient,
@@ -71,6 +79,8 @@ func NewSourceQueryBuilder(
 		searchBy:          searchBy,
 		singleLineResults: singleLineResults,
 		separateSources:   separateSources,
+		rankWeights:       rankWeights,
+		explainRank:       explainRank,
 		queryMap:          map[string]map[string]interface{}{},
 		results:           make([]abstractResult, 0, 100),
 	}
@@ -82,6 +92,11 @@ type abstractResult struct {
 	description string
 	votes       int
 	provides    []string
+
+	popularity   float64
+	lastModified int
+	outOfDate    bool
+	orphaned     bool
 }
 
 type abstractResults struct {
@@ -91,8 +106,10 @@ type abstractResults struct {
 	metric          strutil.StringMetric
 	separateSources bool
 	sortBy          string
+	weights         settings.RankWeights
+	now             time.Time
 
-	distanceCache       map[string]float64
+	rankCache           map[string]*rankScore
 	separateSourceCache map[string]float64
 }
 
@@ -127,29 +144,49 @@ func (a *abstractResults) Less(i, j int) bool {
 	return cmpResult
 }
 
-func (s *SourceQueryBuilder) Execute(ctx context.Context, dbExecutor db.Executor, pkgS []string) {
+func (s *SourceQueryBuilder) Execute(ctx context.Context, dbExecutor db.Executor, pkgS []string) error {
 	var aurErr error
 
 	pkgS = RemoveInvalidTargets(s.logger, pkgS, s.targetMode)
 
+	search, err := parseSearch(pkgS, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if err := search.validateRepos(dbExecutor); err != nil {
+		return err
+	}
+
 	metric := &metrics.Hamming{
 		CaseSensitive: false,
 	}
 
 	sortableResults := &abstractResults{
 		results:             []abstractResult{},
-		search:              strings.Join(pkgS, ""),
+		search:              strings.Join(search.keywords, ""),
 		bottomUp:            s.bottomUp,
 		metric:              metric,
 		separateSources:     s.separateSources,
 		sortBy:              s.sortBy,
-		distanceCache:       map[string]float64{},
+		weights:             s.rankWeights,
+		now:                 time.Now(),
+		rankCache:           map[string]*rankScore{},
 		separateSourceCache: map[string]float64{},
 	}
 
-	if s.targetMode.AtLeastAUR() {
+	if s.targetMode.AtLeastAUR() && search.includesSource(sourceAUR) {
 		var aurResults []aur.Pkg
-		aurResults, aurErr = queryAUR(ctx, s.aurClient, pkgS, s.searchBy)
+
+		switch {
+		case search.server != nil:
+			aurResults, aurErr = queryAUR(ctx, s.aurClient, []string{search.server.value}, search.server.field)
+		case len(search.keywords) == 0 && search.qualified():
+			return ErrNoSearchTerm{}
+		default:
+			aurResults, aurErr = queryAUR(ctx, s.aurClient, search.keywords, s.searchBy)
+		}
+
 		dbName := sourceAUR
 
 		for i := range aurResults {
@@ -157,9 +194,16 @@ func (s *SourceQueryBuilder) Execute(ctx context.Context, dbExecutor db.Executor
 				s.queryMap[dbName] = map[string]interface{}{}
 			}
 
-			by := getSearchBy(s.searchBy)
-			if (by == aur.NameDesc || by == aur.None || by == aur.Name) &&
-				!matchesSearch(&aurResults[i], pkgS) {
+			if search.server != nil {
+				if !matchesKeywords(&aurResults[i], search.keywords) {
+					continue
+				}
+			} else if by := getSearchBy(s.searchBy); (by == aur.NameDesc || by == aur.None || by == aur.Name) &&
+				!matchesSearch(&aurResults[i], search.keywords) {
+				continue
+			}
+
+			if !search.matchAUR(&aurResults[i]) {
 				continue
 			}
 
@@ -171,16 +215,25 @@ func (s *SourceQueryBuilder) Execute(ctx context.Context, dbExecutor db.Executor
 				description: aurResults[i].Description,
 				provides:    aurResults[i].Provides,
 				votes:       aurResults[i].NumVotes,
+
+				popularity:   aurResults[i].Popularity,
+				lastModified: aurResults[i].LastModified,
+				outOfDate:    aurResults[i].OutOfDate != 0,
+				orphaned:     aurResults[i].Maintainer == "",
 			})
 		}
 	}
 
 	var repoResults []alpm.IPackage
-	if s.targetMode.AtLeastRepo() {
-		repoResults = dbExecutor.SyncPackages(pkgS...)
+	if s.targetMode.AtLeastRepo() && !search.aurOnly() {
+		repoResults = dbExecutor.SyncPackages(search.keywords...)
 
 		for i := range repoResults {
 			dbName := repoResults[i].DB().Name()
+			if !search.includesSource(dbName) || !search.matchRepo(repoResults[i]) {
+				continue
+			}
+
 			if s.queryMap[dbName] == nil {
 				s.queryMap[dbName] = map[string]interface{}{}
 			}
@@ -206,6 +259,7 @@ func (s *SourceQueryBuilder) Execute(ctx context.Context, dbExecutor db.Executor
 
 	sort.Sort(sortableResults)
 	s.results = sortableResults.results
+	s.ranks = sortableResults.rankCache
 
 	if aurErr != nil {
 		s.logger.Errorln(ErrAURSearch{inner: aurErr})
@@ -214,6 +268,8 @@ func (s *SourceQueryBuilder) Execute(ctx context.Context, dbExecutor db.Executor
 			s.logger.Warnln(gotext.Get("Showing repo packages only"))
 		}
 	}
+
+	return nil
 }
 
 func (s *SourceQueryBuilder) Results(dbExecutor db.Executor, verboseSearch SearchVerbosity) error {
@@ -243,6 +299,10 @@ func (s *SourceQueryBuilder) Results(dbExecutor db.Executor, verboseSearch Searc
 		}
 
 		s.logger.Println(toPrint)
+
+		if rank, ok := s.ranks[s.results[i].source+"/"+s.results[i].name]; s.explainRank && ok {
+			s.logger.Println("    " + text.Magenta(rank.explain()))
+		}
 	}
 
 	return nil
@@ -296,3 +356,19 @@ func matchesSearch(pkg *aur.Pkg, terms []string) bool {
 
 	return true
 }
+
+// matchesKeywords reports whether every keyword is in the name or the
+// description of a package found by a qualifier.
+func matchesKeywords(pkg *aur.Pkg, keywords []string) bool {
+	name := strings.ToLower(pkg.Name)
+	desc := strings.ToLower(pkg.Description)
+
+	for _, keyword := range keywords {
+		keyword = strings.ToLower(keyword)
+		if !strings.Contains(name, keyword) && !strings.Contains(desc, keyword) {
+			return false
+		}
+	}
+
+	return true
+}
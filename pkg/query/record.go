@@ -0,0 +1,180 @@
+package query
+
+import (
+	"strconv"
+
+	"github.com/Jguer/aur"
+	"github.com/Jguer/go-alpm/v2"
+
+	"github.com/Jguer/yay/v12/pkg/db"
+)
+
+// repositoryLocal is the repository of installed packages written by -Qi.
+const repositoryLocal = "local"
+
+// PackageRecord is a repo, AUR or installed package in the machine-readable
+// output of -Ss, -Si and -Qi. Fields only AUR packages have are zero for
+// repo packages.
+type PackageRecord struct {
+	Repository       string   `json:"repository"`
+	Name             string   `json:"name"`
+	Base             string   `json:"base"`
+	Version          string   `json:"version"`
+	Description      string   `json:"description"`
+	URL              string   `json:"url"`
+	Licenses         []string `json:"licenses"`
+	Groups           []string `json:"groups"`
+	Provides         []string `json:"provides"`
+	Depends          []string `json:"depends"`
+	OptDepends       []string `json:"optDepends"`
+	MakeDepends      []string `json:"makeDepends"`
+	CheckDepends     []string `json:"checkDepends"`
+	Conflicts        []string `json:"conflicts"`
+	Replaces         []string `json:"replaces"`
+	Keywords         []string `json:"keywords"`
+	Maintainer       string   `json:"maintainer"`
+	Votes            int      `json:"votes"`
+	Popularity       float64  `json:"popularity"`
+	OutOfDate        int      `json:"outOfDate"`
+	FirstSubmitted   int      `json:"firstSubmitted"`
+	LastModified     int      `json:"lastModified"`
+	InstalledVersion string   `json:"installedVersion"`
+	Reason           string   `json:"reason,omitempty"`
+}
+
+func (r PackageRecord) Fields() []string {
+	return []string{
+		r.Repository, r.Name, r.Base, r.Version, r.Description, r.URL, r.Maintainer,
+		strconv.Itoa(r.Votes), strconv.FormatFloat(r.Popularity, 'f', 2, 64),
+		strconv.FormatBool(r.OutOfDate != 0), r.InstalledVersion,
+	}
+}
+
+// NewAURRecord creates the record of an AUR package.
+func NewAURRecord(pkg *aur.Pkg, dbExecutor db.Executor) PackageRecord {
+	return PackageRecord{
+		Repository:       sourceAUR,
+		Name:             pkg.Name,
+		Base:             pkg.PackageBase,
+		Version:          pkg.Version,
+		Description:      pkg.Description,
+		URL:              pkg.URL,
+		Licenses:         nonNil(pkg.License),
+		Groups:           nonNil(pkg.Groups),
+		Provides:         nonNil(pkg.Provides),
+		Depends:          nonNil(pkg.Depends),
+		OptDepends:       nonNil(pkg.OptDepends),
+		MakeDepends:      nonNil(pkg.MakeDepends),
+		CheckDepends:     nonNil(pkg.CheckDepends),
+		Conflicts:        nonNil(pkg.Conflicts),
+		Replaces:         nonNil(pkg.Replaces),
+		Keywords:         nonNil(pkg.Keywords),
+		Maintainer:       pkg.Maintainer,
+		Votes:            pkg.NumVotes,
+		Popularity:       pkg.Popularity,
+		OutOfDate:        pkg.OutOfDate,
+		FirstSubmitted:   pkg.FirstSubmitted,
+		LastModified:     pkg.LastModified,
+		InstalledVersion: installedVersion(pkg.Name, dbExecutor),
+	}
+}
+
+// NewRepoRecord creates the record of a sync package.
+func NewRepoRecord(pkg alpm.IPackage, dbExecutor db.Executor) PackageRecord {
+	record := newALPMRecord(pkg)
+	record.Repository = pkg.DB().Name()
+	record.InstalledVersion = installedVersion(pkg.Name(), dbExecutor)
+
+	return record
+}
+
+// NewLocalRecord creates the record of an installed package.
+func NewLocalRecord(pkg alpm.IPackage) PackageRecord {
+	record := newALPMRecord(pkg)
+	record.Repository = repositoryLocal
+	record.InstalledVersion = pkg.Version()
+	record.Reason = "explicit"
+
+	if pkg.Reason() != alpm.PkgReasonExplicit {
+		record.Reason = "dependency"
+	}
+
+	return record
+}
+
+// newALPMRecord creates the record of a package from a sync or the local
+// database. The build date is used as the last modification.
+func newALPMRecord(pkg alpm.IPackage) PackageRecord {
+	record := PackageRecord{
+		Name:         pkg.Name(),
+		Base:         pkg.Base(),
+		Version:      pkg.Version(),
+		Description:  pkg.Description(),
+		URL:          pkg.URL(),
+		Licenses:     nonNil(pkg.Licenses().Slice()),
+		Groups:       nonNil(pkg.Groups().Slice()),
+		Provides:     dependStrings(pkg.Provides()),
+		Depends:      dependStrings(pkg.Depends()),
+		OptDepends:   dependStrings(pkg.OptionalDepends()),
+		MakeDepends:  dependStrings(pkg.MakeDepends()),
+		CheckDepends: dependStrings(pkg.CheckDepends()),
+		Conflicts:    dependStrings(pkg.Conflicts()),
+		Replaces:     dependStrings(pkg.Replaces()),
+		Keywords:     []string{},
+	}
+
+	if buildDate := pkg.BuildDate(); !buildDate.IsZero() {
+		record.LastModified = int(buildDate.Unix())
+	}
+
+	return record
+}
+
+func installedVersion(name string, dbExecutor db.Executor) string {
+	if pkg := dbExecutor.LocalPackage(name); pkg != nil {
+		return pkg.Version()
+	}
+
+	return ""
+}
+
+func dependStrings(deps alpm.IDependList) []string {
+	slice := deps.Slice()
+	strs := make([]string, 0, len(slice))
+
+	for i := range slice {
+		str := slice[i].String()
+		if slice[i].Description != "" {
+			str += ": " + slice[i].Description
+		}
+
+		strs = append(strs, str)
+	}
+
+	return strs
+}
+
+// nonNil keeps empty lists as empty arrays in JSON.
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+
+	return values
+}
+
+// Records returns the search results in the order they are printed.
+func (s *SourceQueryBuilder) Records(dbExecutor db.Executor) []PackageRecord {
+	records := make([]PackageRecord, 0, len(s.results))
+
+	for i := range s.results {
+		switch pkg := s.queryMap[s.results[i].source][s.results[i].name].(type) {
+		case aur.Pkg:
+			records = append(records, NewAURRecord(&pkg, dbExecutor))
+		case alpm.IPackage:
+			records = append(records, NewRepoRecord(pkg, dbExecutor))
+		}
+	}
+
+	return records
+}
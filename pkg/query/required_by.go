@@ -0,0 +1,159 @@
+package query
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Jguer/aur"
+
+	"github.com/Jguer/yay/v12/pkg/db"
+)
+
+// Dependency kinds of the AUR packages requiring a package.
+const (
+	KindDepends     = "depends"
+	KindMakeDepends = "makedepends"
+	KindOptDepends  = "optdepends"
+)
+
+var requiredByKinds = []struct {
+	kind string
+	by   aur.By
+}{
+	{KindDepends, aur.Depends},
+	{KindMakeDepends, aur.MakeDepends},
+	{KindOptDepends, aur.OptDepends},
+}
+
+// RequiredByRecord is an AUR package depending, makedepending or
+// optdepending on a package, either by name or through one of the
+// package's provides.
+type RequiredByRecord struct {
+	Package   string `json:"package"`
+	Name      string `json:"name"`
+	Base      string `json:"base"`
+	Version   string `json:"version"`
+	Kind      string `json:"kind"`
+	Via       string `json:"via"`
+	Installed bool   `json:"installed"`
+}
+
+func (r RequiredByRecord) Fields() []string {
+	return []string{r.Package, r.Name, r.Base, r.Version, r.Kind, r.Via, strconv.FormatBool(r.Installed)}
+}
+
+// RequiredBy searches the AUR for the packages requiring pkgName or one of
+// the names it provides. Results are sorted by name and kind.
+func RequiredBy(ctx context.Context, aurClient aur.QueryClient, dbExecutor db.Executor,
+	pkgName string, provides []string,
+) ([]RequiredByRecord, error) {
+	needles := []string{pkgName}
+	for _, provide := range provides {
+		if provide != pkgName {
+			needles = append(needles, provide)
+		}
+	}
+
+	records := make([]RequiredByRecord, 0)
+	seen := make(map[string]bool)
+
+	for _, kind := range requiredByKinds {
+		pkgs, err := aurClient.Get(ctx, &aur.Query{
+			Needles:  needles,
+			By:       kind.by,
+			Contains: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range pkgs {
+			pkg := &pkgs[i]
+			key := pkg.Name + "/" + kind.kind
+
+			if seen[key] || pkg.Name == pkgName {
+				continue
+			}
+
+			needle := requiredNeedle(pkg, kind.by, needles)
+			if needle == "" {
+				continue
+			}
+
+			seen[key] = true
+			record := RequiredByRecord{
+				Package:   pkgName,
+				Name:      pkg.Name,
+				Base:      pkg.PackageBase,
+				Version:   pkg.Version,
+				Kind:      kind.kind,
+				Installed: dbExecutor.LocalPackage(pkg.Name) != nil,
+			}
+
+			if needle != pkgName {
+				record.Via = needle
+			}
+
+			records = append(records, record)
+		}
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Name < records[j].Name
+	})
+
+	return records, nil
+}
+
+// requiredNeedle returns the first of needles an AUR package requires with
+// the given kind of dependency, or "" if it requires none of them.
+func requiredNeedle(pkg *aur.Pkg, by aur.By, needles []string) string {
+	for _, needle := range needles {
+		if requires(pkg, by, needle) {
+			return needle
+		}
+	}
+
+	return ""
+}
+
+// requires reports whether an AUR package lists name as a dependency of the
+// given kind. Search results without dependency lists are trusted, since
+// the AUR already matched them by dependency.
+func requires(pkg *aur.Pkg, by aur.By, name string) bool {
+	var deps []string
+
+	switch by {
+	case aur.Depends:
+		deps = pkg.Depends
+	case aur.MakeDepends:
+		deps = pkg.MakeDepends
+	case aur.OptDepends:
+		deps = pkg.OptDepends
+	}
+
+	if len(pkg.Depends)+len(pkg.MakeDepends)+len(pkg.OptDepends) == 0 {
+		return true
+	}
+
+	q := qualifier{value: name}
+
+	return q.matchDepends(deps)
+}
+
+// ProvideNames strips the versions of provides such as "libfoo.so=1-64".
+func ProvideNames(provides []string) []string {
+	names := make([]string, 0, len(provides))
+
+	for _, provide := range provides {
+		if i := strings.IndexAny(provide, "<>="); i != -1 {
+			provide = provide[:i]
+		}
+
+		names = append(names, provide)
+	}
+
+	return names
+}
@@ -0,0 +1,62 @@
+//go:build !integration
+// +build !integration
+
+package query
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Jguer/aur"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yay/v12/pkg/db/mock"
+	mockaur "github.com/Jguer/yay/v12/pkg/dep/mock"
+)
+
+func TestRequiredBy(t *testing.T) {
+	t.Parallel()
+
+	aurPkgs := []aur.Pkg{
+		{Name: "foo-gui", PackageBase: "foo-gui", Version: "1.0-1", Depends: []string{"foo>=2"}},
+		{Name: "foo-plugins", PackageBase: "foo-plugins", Version: "0.3-1", MakeDepends: []string{"foo"}},
+		{Name: "libfoo-tools", PackageBase: "libfoo-tools", Version: "2.1-1", Depends: []string{"libfoo-extra"}},
+		{Name: "bar", PackageBase: "bar", Version: "5-1", OptDepends: []string{"libfoo.so: foo support"}},
+		{Name: "foo", PackageBase: "foo", Version: "2.0-1", Provides: []string{"libfoo.so=2-64"}},
+	}
+
+	queries := []aur.Query{}
+	mockAUR := &mockaur.MockAUR{
+		GetFn: func(ctx context.Context, query *aur.Query) ([]aur.Pkg, error) {
+			queries = append(queries, *query)
+			return aurPkgs, nil
+		},
+	}
+
+	mockDB := &mock.DBExecutor{
+		LocalPackageFn: func(name string) mock.IPackage {
+			if name == "foo-gui" {
+				return &mock.Package{PName: name, PVersion: "1.0-1"}
+			}
+
+			return nil
+		},
+	}
+
+	records, err := RequiredBy(context.Background(), mockAUR, mockDB, "foo",
+		ProvideNames([]string{"libfoo.so=2-64", "foo"}))
+	require.NoError(t, err)
+
+	assert.Equal(t, []RequiredByRecord{
+		{Package: "foo", Name: "bar", Base: "bar", Version: "5-1", Kind: KindOptDepends, Via: "libfoo.so"},
+		{Package: "foo", Name: "foo-gui", Base: "foo-gui", Version: "1.0-1", Kind: KindDepends, Installed: true},
+		{Package: "foo", Name: "foo-plugins", Base: "foo-plugins", Version: "0.3-1", Kind: KindMakeDepends},
+	}, records)
+
+	assert.Equal(t, []aur.Query{
+		{Needles: []string{"foo", "libfoo.so"}, By: aur.Depends, Contains: true},
+		{Needles: []string{"foo", "libfoo.so"}, By: aur.MakeDepends, Contains: true},
+		{Needles: []string{"foo", "libfoo.so"}, By: aur.OptDepends, Contains: true},
+	}, queries)
+}
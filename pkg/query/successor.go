@@ -0,0 +1,155 @@
+package query
+
+import (
+	"context"
+	"sort"
+
+	"github.com/Jguer/aur"
+	"github.com/Jguer/go-alpm/v2"
+
+	"github.com/Jguer/yay/v12/pkg/aurrequest"
+)
+
+// How a package left the AUR.
+const (
+	SuccessorReplaced = "replaced"
+	SuccessorMerged   = "merged"
+	SuccessorDeleted  = "deleted"
+)
+
+// Successor is what became of an installed foreign package that left the
+// AUR.
+type Successor struct {
+	Name         string
+	LocalVersion string
+	Reason       alpm.PkgReason
+	// Kind is SuccessorReplaced, SuccessorMerged or SuccessorDeleted.
+	Kind string
+	// Package is the AUR package taking over, nil for deletions.
+	Package *aur.Pkg
+	// Comment is the comment of the merge or deletion request.
+	Comment string
+}
+
+// CalculateSuccessors looks up what became of the missing packages: an AUR
+// package listing one of them in its Replaces takes over, otherwise the
+// accepted merge or deletion request of its package base in feed, which may
+// be nil, tells. Packages found are no longer missing.
+func (warnings *AURWarnings) CalculateSuccessors(ctx context.Context, aurClient aur.QueryClient,
+	feed *aurrequest.Feed, remote map[string]alpm.IPackage,
+) error {
+	missing := make([]string, 0, len(warnings.Missing))
+
+	for _, name := range warnings.Missing {
+		pkg := remote[name]
+
+		successor, err := findSuccessor(ctx, aurClient, feed, pkg)
+		if err != nil {
+			return err
+		}
+
+		if successor == nil {
+			missing = append(missing, name)
+			continue
+		}
+
+		warnings.Successors = append(warnings.Successors, *successor)
+	}
+
+	warnings.Missing = missing
+
+	return nil
+}
+
+func findSuccessor(ctx context.Context, aurClient aur.QueryClient,
+	feed *aurrequest.Feed, pkg alpm.IPackage,
+) (*Successor, error) {
+	successor := &Successor{
+		Name:         pkg.Name(),
+		LocalVersion: pkg.Version(),
+		Reason:       pkg.Reason(),
+	}
+
+	replacing, err := aurClient.Get(ctx, &aur.Query{
+		Needles:  []string{pkg.Name()},
+		By:       aur.Replaces,
+		Contains: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	replacing = filterReplacing(replacing, pkg.Name())
+	if len(replacing) > 0 {
+		successor.Kind = SuccessorReplaced
+		successor.Package = &replacing[0]
+
+		return successor, nil
+	}
+
+	base := pkg.Base()
+	if base == "" {
+		base = pkg.Name()
+	}
+
+	req := feed.Find(base)
+	if req == nil {
+		return nil, nil
+	}
+
+	successor.Comment = req.Comment
+
+	if req.Type == aurrequest.TypeDeletion || req.MergeInto == "" {
+		successor.Kind = SuccessorDeleted
+		return successor, nil
+	}
+
+	merged, err := aurClient.Get(ctx, &aur.Query{
+		Needles: []string{req.MergeInto},
+		By:      aur.Name,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	successor.Kind = SuccessorMerged
+
+	// the merged package is the one of the target base named like the
+	// base, or else any package of it
+	for i := range merged {
+		if merged[i].PackageBase != req.MergeInto {
+			continue
+		}
+
+		if successor.Package == nil || merged[i].Name == req.MergeInto {
+			successor.Package = &merged[i]
+		}
+	}
+
+	if successor.Package == nil {
+		successor.Kind = SuccessorDeleted
+	}
+
+	return successor, nil
+}
+
+// filterReplacing keeps the AUR packages replacing a package, the most voted
+// first.
+func filterReplacing(pkgs []aur.Pkg, name string) []aur.Pkg {
+	replacing := make([]aur.Pkg, 0, len(pkgs))
+
+	for i := range pkgs {
+		for _, replace := range pkgs[i].Replaces {
+			if edgeName(replace) == name {
+				replacing = append(replacing, pkgs[i])
+				break
+			}
+		}
+	}
+
+	sort.SliceStable(replacing, func(i, j int) bool {
+		return replacing[i].NumVotes > replacing[j].NumVotes
+	})
+
+	return replacing
+}
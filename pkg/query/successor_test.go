@@ -0,0 +1,71 @@
+//go:build !integration
+// +build !integration
+
+package query
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Jguer/aur"
+	"github.com/Jguer/go-alpm/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yay/v12/pkg/aurrequest"
+	"github.com/Jguer/yay/v12/pkg/db/mock"
+	mockaur "github.com/Jguer/yay/v12/pkg/dep/mock"
+)
+
+func TestAURWarnings_CalculateSuccessors(t *testing.T) {
+	t.Parallel()
+
+	remote := map[string]alpm.IPackage{
+		"foo-old":    &mock.Package{PName: "foo-old", PBase: "foo-old", PVersion: "1-1", PReason: alpm.PkgReasonExplicit},
+		"python-foo": &mock.Package{PName: "python-foo", PBase: "python-foo", PVersion: "2-1", PReason: alpm.PkgReasonDepend},
+		"old-tool":   &mock.Package{PName: "old-tool", PBase: "old-tool", PVersion: "3-1"},
+		"unknown":    &mock.Package{PName: "unknown", PBase: "unknown", PVersion: "4-1"},
+	}
+
+	fooNew := aur.Pkg{Name: "foo-new", PackageBase: "foo-new", Version: "2-1", NumVotes: 30, Replaces: []string{"foo-old<2"}}
+	fooFork := aur.Pkg{Name: "foo-fork", PackageBase: "foo-fork", Version: "1-1", NumVotes: 3, Replaces: []string{"foo-old"}}
+	fooNG := aur.Pkg{Name: "python-foo-ng", PackageBase: "python-foo-ng", Version: "3-1"}
+
+	mockAUR := &mockaur.MockAUR{
+		GetFn: func(ctx context.Context, query *aur.Query) ([]aur.Pkg, error) {
+			switch {
+			case query.By == aur.Replaces && query.Needles[0] == "foo-old":
+				return []aur.Pkg{fooFork, fooNew, {Name: "foo-oldish", Replaces: []string{"foo-oldish-bin"}}}, nil
+			case query.By == aur.Name && query.Needles[0] == "python-foo-ng":
+				return []aur.Pkg{fooNG}, nil
+			}
+
+			return []aur.Pkg{}, nil
+		},
+	}
+
+	feed := aurrequest.NewFeed([]aurrequest.Request{
+		{ID: 1, Type: aurrequest.TypeMerge, Package: "python-foo", MergeInto: "python-foo-ng", Status: aurrequest.StatusAccepted},
+		{ID: 2, Type: aurrequest.TypeDeletion, Package: "old-tool", Status: aurrequest.StatusAccepted, Comment: "abandoned"},
+	})
+
+	warnings := NewWarnings(nil)
+	warnings.Missing = []string{"foo-old", "old-tool", "python-foo", "unknown"}
+
+	require.NoError(t, warnings.CalculateSuccessors(context.Background(), mockAUR, feed, remote))
+
+	assert.Equal(t, []string{"unknown"}, warnings.Missing)
+	assert.Equal(t, []Successor{
+		{Name: "foo-old", LocalVersion: "1-1", Reason: alpm.PkgReasonExplicit, Kind: SuccessorReplaced, Package: &fooNew},
+		{Name: "old-tool", LocalVersion: "3-1", Kind: SuccessorDeleted, Comment: "abandoned"},
+		{Name: "python-foo", LocalVersion: "2-1", Reason: alpm.PkgReasonDepend, Kind: SuccessorMerged, Package: &fooNG},
+	}, warnings.Successors)
+
+	// without a feed only Replaces are known
+	warnings = NewWarnings(nil)
+	warnings.Missing = []string{"old-tool", "python-foo"}
+
+	require.NoError(t, warnings.CalculateSuccessors(context.Background(), mockAUR, nil, remote))
+	assert.Equal(t, []string{"old-tool", "python-foo"}, warnings.Missing)
+	assert.Empty(t, warnings.Successors)
+}
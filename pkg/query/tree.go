@@ -0,0 +1,353 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yay/v12/pkg/db"
+	"github.com/Jguer/yay/v12/pkg/text"
+)
+
+// Formats a dependency tree can be written in.
+const (
+	TreeFormatText = "tree"
+	TreeFormatDOT  = "dot"
+	TreeFormatJSON = "json"
+)
+
+// TreeOptions controls how a dependency tree is built.
+type TreeOptions struct {
+	// Depth limits the levels below the root, a negative depth has no limit.
+	Depth int
+	// Reverse lists the packages requiring each node instead of its
+	// dependencies.
+	Reverse bool
+	// Optional follows optional dependencies as well.
+	Optional bool
+	// Full expands every occurrence of a package. By default a package is
+	// expanded only once and later occurrences are marked as repeated.
+	Full bool
+}
+
+// TreeNode is an installed package, or a dependency no installed package
+// satisfies, in a dependency tree.
+type TreeNode struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	// Via is the dependency linking the node to its parent when it is not
+	// the name of the package, such as a name it provides.
+	Via      string `json:"via,omitempty"`
+	Foreign  bool   `json:"foreign"`
+	Optional bool   `json:"optional,omitempty"`
+	Missing  bool   `json:"missing,omitempty"`
+	// Repeated nodes are expanded elsewhere in the tree or are part of a
+	// dependency cycle.
+	Repeated bool        `json:"repeated,omitempty"`
+	Children []*TreeNode `json:"children,omitempty"`
+}
+
+// treeEdge is a dependency of an installed package on another.
+type treeEdge struct {
+	pkg      db.IPackage
+	via      string
+	optional bool
+}
+
+type treeBuilder struct {
+	dbExecutor db.Executor
+	opts       TreeOptions
+	byName     map[string]db.IPackage
+	providers  map[string]db.IPackage
+	foreign    mapset.Set[string]
+	requiredBy map[string][]treeEdge
+	expanded   mapset.Set[string]
+}
+
+// BuildTree builds the dependency tree of an installed package from the
+// local database. Dependencies are resolved to the installed package of that
+// name or else to an installed package providing it, whatever its version.
+func BuildTree(dbExecutor db.Executor, pkgName string, opts TreeOptions) (*TreeNode, error) {
+	builder := &treeBuilder{
+		dbExecutor: dbExecutor,
+		opts:       opts,
+		byName:     make(map[string]db.IPackage),
+		providers:  make(map[string]db.IPackage),
+		foreign:    mapset.NewThreadUnsafeSet(dbExecutor.InstalledRemotePackageNames()...),
+		requiredBy: make(map[string][]treeEdge),
+		expanded:   mapset.NewThreadUnsafeSet[string](),
+	}
+
+	localPkgs := dbExecutor.LocalPackages()
+	for _, pkg := range localPkgs {
+		builder.byName[pkg.Name()] = pkg
+	}
+
+	for _, pkg := range localPkgs {
+		for _, provide := range dbExecutor.PackageProvides(pkg) {
+			if _, ok := builder.providers[provide.Name]; !ok {
+				builder.providers[provide.Name] = pkg
+			}
+		}
+	}
+
+	if opts.Reverse {
+		builder.indexRequiredBy(localPkgs)
+	}
+
+	root := builder.resolve(pkgName)
+	if root == nil {
+		return nil, ErrNotInstalled{pkgName}
+	}
+
+	node := builder.node(root, pkgName, root.Name(), false)
+	builder.expand(node, root, 0, mapset.NewThreadUnsafeSet[string]())
+
+	return node, nil
+}
+
+func (b *treeBuilder) resolve(depName string) db.IPackage {
+	if pkg, ok := b.byName[depName]; ok {
+		return pkg
+	}
+
+	return b.providers[depName]
+}
+
+// node creates the node of a package reached through the dependency via
+// on the package named target.
+func (b *treeBuilder) node(pkg db.IPackage, via, target string, optional bool) *TreeNode {
+	node := &TreeNode{
+		Name:     pkg.Name(),
+		Version:  pkg.Version(),
+		Foreign:  b.foreign.Contains(pkg.Name()),
+		Optional: optional,
+	}
+
+	if via = edgeName(via); via != target {
+		node.Via = via
+	}
+
+	return node
+}
+
+// dependencies lists the dependencies of a package, optional ones last.
+func (b *treeBuilder) dependencies(pkg db.IPackage) []treeEdge {
+	edges := []treeEdge{}
+
+	for _, dep := range b.dbExecutor.PackageDepends(pkg) {
+		edges = append(edges, treeEdge{pkg: b.resolve(dep.Name), via: dep.String()})
+	}
+
+	if b.opts.Optional {
+		for _, dep := range b.dbExecutor.PackageOptionalDepends(pkg) {
+			edges = append(edges, treeEdge{pkg: b.resolve(dep.Name), via: dep.String(), optional: true})
+		}
+	}
+
+	return edges
+}
+
+// indexRequiredBy maps each installed package to the installed packages
+// depending on it.
+func (b *treeBuilder) indexRequiredBy(localPkgs []db.IPackage) {
+	for _, pkg := range localPkgs {
+		for _, edge := range b.dependencies(pkg) {
+			if edge.pkg == nil {
+				continue
+			}
+
+			b.requiredBy[edge.pkg.Name()] = append(b.requiredBy[edge.pkg.Name()],
+				treeEdge{pkg: pkg, via: edge.via, optional: edge.optional})
+		}
+	}
+
+	for name := range b.requiredBy {
+		edges := b.requiredBy[name]
+		sort.SliceStable(edges, func(i, j int) bool {
+			return edges[i].pkg.Name() < edges[j].pkg.Name()
+		})
+	}
+}
+
+func (b *treeBuilder) expand(node *TreeNode, pkg db.IPackage, depth int, path mapset.Set[string]) {
+	if b.opts.Depth >= 0 && depth >= b.opts.Depth {
+		return
+	}
+
+	b.expanded.Add(pkg.Name())
+	path.Add(pkg.Name())
+
+	defer path.Remove(pkg.Name())
+
+	edges := b.requiredBy[pkg.Name()]
+	if !b.opts.Reverse {
+		edges = b.dependencies(pkg)
+	}
+
+	for _, edge := range edges {
+		if edge.pkg == nil {
+			node.Children = append(node.Children,
+				&TreeNode{Name: edge.via, Optional: edge.optional, Missing: true})
+
+			continue
+		}
+
+		// in reverse the child depends on the node rather than the other way
+		target := edge.pkg.Name()
+		if b.opts.Reverse {
+			target = pkg.Name()
+		}
+
+		child := b.node(edge.pkg, edge.via, target, edge.optional)
+		node.Children = append(node.Children, child)
+
+		if path.Contains(child.Name) || (!b.opts.Full && b.expanded.Contains(child.Name)) {
+			child.Repeated = true
+			continue
+		}
+
+		b.expand(child, edge.pkg, depth+1, path)
+	}
+}
+
+// edgeName strips the version constraint of a dependency.
+func edgeName(dep string) string {
+	if i := strings.IndexAny(dep, "<>="); i != -1 {
+		return dep[:i]
+	}
+
+	return dep
+}
+
+// ParseTreeDepth parses the --depth of a dependency tree.
+func ParseTreeDepth(value string) (int, error) {
+	depth, err := strconv.Atoi(value)
+	if err != nil || depth < 0 {
+		return 0, ErrInvalidTreeDepth{value}
+	}
+
+	return depth, nil
+}
+
+// WriteTree writes a dependency tree in one of the tree formats.
+func WriteTree(w io.Writer, root *TreeNode, format string) error {
+	switch format {
+	case TreeFormatText:
+		writeTreeText(w, root, "", "")
+		return nil
+	case TreeFormatDOT:
+		return writeTreeDOT(w, root)
+	case TreeFormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+
+		return enc.Encode(root)
+	}
+
+	return ErrInvalidTreeFormat{format}
+}
+
+func treeLabel(node *TreeNode) string {
+	if node.Missing {
+		return text.Red(node.Name) + " " + text.Bold(text.Red(gotext.Get("[not installed]")))
+	}
+
+	label := text.Bold(node.Name) + " " + text.Cyan(node.Version)
+
+	if node.Via != "" {
+		label += " " + gotext.Get("(via %s)", node.Via)
+	}
+
+	if node.Foreign {
+		label += " " + text.Bold(text.Magenta(gotext.Get("[foreign]")))
+	}
+
+	if node.Optional {
+		label += " " + gotext.Get("[optional]")
+	}
+
+	if node.Repeated {
+		label += " ..."
+	}
+
+	return label
+}
+
+func writeTreeText(w io.Writer, node *TreeNode, prefix, childPrefix string) {
+	fmt.Fprintln(w, prefix+treeLabel(node))
+
+	for i, child := range node.Children {
+		if i == len(node.Children)-1 {
+			writeTreeText(w, child, childPrefix+"└─", childPrefix+"  ")
+		} else {
+			writeTreeText(w, child, childPrefix+"├─", childPrefix+"│ ")
+		}
+	}
+}
+
+// writeTreeDOT writes a dependency tree as a graphviz digraph, with an edge
+// from each node to its children. Foreign nodes are filled and missing
+// dependencies dashed.
+func writeTreeDOT(w io.Writer, root *TreeNode) error {
+	var out strings.Builder
+
+	nodes := mapset.NewThreadUnsafeSet[string]()
+	edges := mapset.NewThreadUnsafeSet[string]()
+
+	fmt.Fprintf(&out, "digraph %q {\n", root.Name)
+
+	var walk func(node *TreeNode)
+	walk = func(node *TreeNode) {
+		if nodes.Add(node.Name) {
+			switch {
+			case node.Missing:
+				fmt.Fprintf(&out, "\t%q [style=dashed];\n", node.Name)
+			case node.Foreign:
+				fmt.Fprintf(&out, "\t%q [label=%q, style=filled, fillcolor=lightblue];\n",
+					node.Name, node.Name+"\n"+node.Version)
+			default:
+				fmt.Fprintf(&out, "\t%q [label=%q];\n", node.Name, node.Name+"\n"+node.Version)
+			}
+		}
+
+		for _, child := range node.Children {
+			edge := fmt.Sprintf("\t%q -> %q", node.Name, child.Name)
+			if !edges.Add(edge) {
+				continue
+			}
+
+			attrs := []string{}
+			if child.Via != "" {
+				attrs = append(attrs, fmt.Sprintf("label=%q", child.Via))
+			}
+
+			if child.Optional {
+				attrs = append(attrs, "style=dotted")
+			}
+
+			if len(attrs) > 0 {
+				edge += " [" + strings.Join(attrs, ", ") + "]"
+			}
+
+			out.WriteString(edge + ";\n")
+		}
+
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+
+	walk(root)
+	out.WriteString("}\n")
+
+	_, err := io.WriteString(w, out.String())
+
+	return err
+}
@@ -0,0 +1,161 @@
+//go:build !integration
+// +build !integration
+
+package query
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Jguer/go-alpm/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yay/v12/pkg/db/mock"
+)
+
+func newTreeDB() *mock.DBExecutor {
+	pkgs := []mock.IPackage{
+		&mock.Package{PName: "foo", PVersion: "1.0-1"},
+		&mock.Package{PName: "bar", PVersion: "2.0-1"},
+		&mock.Package{PName: "bash", PVersion: "5.2-1"},
+		&mock.Package{PName: "glibc", PVersion: "2.39-1"},
+		&mock.Package{PName: "doc-viewer", PVersion: "3-1"},
+	}
+
+	depends := map[string][]alpm.Depend{
+		"foo":        {{Name: "bar"}, {Name: "sh"}, {Name: "libmissing", Version: "2", Mod: alpm.DepModGE}},
+		"bar":        {{Name: "glibc"}, {Name: "bash"}},
+		"bash":       {{Name: "glibc"}},
+		"doc-viewer": {{Name: "foo"}},
+	}
+
+	return &mock.DBExecutor{
+		LocalPackagesFn: func() []mock.IPackage { return pkgs },
+		InstalledRemotePackageNamesFn: func() []string {
+			return []string{"foo", "doc-viewer"}
+		},
+		PackageDependsFn: func(pkg mock.IPackage) []alpm.Depend {
+			return depends[pkg.Name()]
+		},
+		PackageOptionalDependsFn: func(pkg mock.IPackage) []alpm.Depend {
+			if pkg.Name() == "foo" {
+				return []alpm.Depend{{Name: "doc-viewer"}}
+			}
+
+			return nil
+		},
+		PackageProvidesFn: func(pkg mock.IPackage) []alpm.Depend {
+			if pkg.Name() == "bash" {
+				return []alpm.Depend{{Name: "sh"}}
+			}
+
+			return nil
+		},
+	}
+}
+
+func TestBuildTree(t *testing.T) {
+	t.Parallel()
+
+	root, err := BuildTree(newTreeDB(), "foo", TreeOptions{Depth: -1})
+	require.NoError(t, err)
+
+	assert.Equal(t, &TreeNode{
+		Name: "foo", Version: "1.0-1", Foreign: true,
+		Children: []*TreeNode{
+			{Name: "bar", Version: "2.0-1", Children: []*TreeNode{
+				{Name: "glibc", Version: "2.39-1"},
+				{Name: "bash", Version: "5.2-1", Children: []*TreeNode{
+					{Name: "glibc", Version: "2.39-1", Repeated: true},
+				}},
+			}},
+			{Name: "bash", Version: "5.2-1", Via: "sh", Repeated: true},
+			{Name: "libmissing>=2", Missing: true},
+		},
+	}, root)
+
+	_, err = BuildTree(newTreeDB(), "baz", TreeOptions{Depth: -1})
+	assert.EqualError(t, err, "package 'baz' was not found")
+}
+
+func TestBuildTree_DepthAndCycles(t *testing.T) {
+	t.Parallel()
+
+	root, err := BuildTree(newTreeDB(), "foo", TreeOptions{Depth: 1})
+	require.NoError(t, err)
+	require.Len(t, root.Children, 3)
+	assert.Empty(t, root.Children[0].Children)
+
+	// doc-viewer depends back on foo
+	root, err = BuildTree(newTreeDB(), "foo", TreeOptions{Depth: -1, Optional: true})
+	require.NoError(t, err)
+	require.Len(t, root.Children, 4)
+	assert.Equal(t, &TreeNode{
+		Name: "doc-viewer", Version: "3-1", Foreign: true, Optional: true,
+		Children: []*TreeNode{{Name: "foo", Version: "1.0-1", Foreign: true, Repeated: true}},
+	}, root.Children[3])
+}
+
+func TestBuildTree_Reverse(t *testing.T) {
+	t.Parallel()
+
+	root, err := BuildTree(newTreeDB(), "sh", TreeOptions{Depth: -1, Reverse: true, Full: true})
+	require.NoError(t, err)
+
+	assert.Equal(t, &TreeNode{
+		Name: "bash", Version: "5.2-1", Via: "sh",
+		Children: []*TreeNode{
+			{Name: "bar", Version: "2.0-1", Children: []*TreeNode{
+				{Name: "foo", Version: "1.0-1", Foreign: true, Children: []*TreeNode{
+					{Name: "doc-viewer", Version: "3-1", Foreign: true},
+				}},
+			}},
+			{Name: "foo", Version: "1.0-1", Via: "sh", Foreign: true, Children: []*TreeNode{
+				{Name: "doc-viewer", Version: "3-1", Foreign: true},
+			}},
+		},
+	}, root)
+
+	root, err = BuildTree(newTreeDB(), "sh", TreeOptions{Depth: -1, Reverse: true})
+	require.NoError(t, err)
+	require.Len(t, root.Children, 2)
+	assert.Equal(t, &TreeNode{Name: "foo", Version: "1.0-1", Via: "sh", Foreign: true, Repeated: true},
+		root.Children[1])
+}
+
+func TestWriteTree(t *testing.T) {
+	t.Parallel()
+
+	root, err := BuildTree(newTreeDB(), "bar", TreeOptions{Depth: -1})
+	require.NoError(t, err)
+
+	var out strings.Builder
+	require.NoError(t, WriteTree(&out, root, TreeFormatText))
+	assert.Equal(t, "\x1b[1mbar\x1b[0m \x1b[36m2.0-1\x1b[0m\n"+
+		"├─\x1b[1mglibc\x1b[0m \x1b[36m2.39-1\x1b[0m\n"+
+		"└─\x1b[1mbash\x1b[0m \x1b[36m5.2-1\x1b[0m\n"+
+		"  └─\x1b[1mglibc\x1b[0m \x1b[36m2.39-1\x1b[0m ...\n", out.String())
+
+	root, err = BuildTree(newTreeDB(), "foo", TreeOptions{Depth: 1})
+	require.NoError(t, err)
+
+	out.Reset()
+	require.NoError(t, WriteTree(&out, root, TreeFormatDOT))
+	assert.Equal(t, `digraph "foo" {
+	"foo" [label="foo\n1.0-1", style=filled, fillcolor=lightblue];
+	"foo" -> "bar";
+	"foo" -> "bash" [label="sh"];
+	"foo" -> "libmissing>=2";
+	"bar" [label="bar\n2.0-1"];
+	"bash" [label="bash\n5.2-1"];
+	"libmissing>=2" [style=dashed];
+}
+`, out.String())
+
+	out.Reset()
+	require.NoError(t, WriteTree(&out, &TreeNode{Name: "glibc", Version: "2.39-1"}, TreeFormatJSON))
+	assert.Equal(t, "{\n\t\"name\": \"glibc\",\n\t\"version\": \"2.39-1\",\n\t\"foreign\": false\n}\n", out.String())
+
+	assert.EqualError(t, WriteTree(&out, root, "svg"), "invalid --tree format 'svg', expected tree, dot or json")
+}
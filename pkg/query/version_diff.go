@@ -74,6 +74,8 @@ func isDevelName(name string) bool {
 	return strings.Contains(name, "-always-")
 }
 
-func isDevelPackage(pkg alpm.IPackage) bool {
+// IsDevelPackage reports whether a package builds from a VCS, going by the
+// suffix of its name or base such as -git.
+func IsDevelPackage(pkg alpm.IPackage) bool {
 	return isDevelName(pkg.Name()) || isDevelName(pkg.Base())
 }
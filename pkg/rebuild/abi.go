@@ -0,0 +1,74 @@
+package rebuild
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/Jguer/yay/v12/pkg/db"
+)
+
+// ABI is a language runtime whose modules are installed into a directory
+// named after its version, so packages must be rebuilt when it moves on.
+type ABI struct {
+	// Name is the name of the package providing the runtime.
+	Name string
+	// Current is the installed version of the runtime, as in the directory.
+	Current string
+
+	dir *regexp.Regexp
+}
+
+// knownABIs maps runtime packages to the pattern of their module directory
+// and the number of version components in its name.
+var knownABIs = []struct {
+	name       string
+	dir        *regexp.Regexp
+	components int
+}{
+	{"python", regexp.MustCompile(`^usr/lib/python(3\.\d+)/`), 2},
+	{"perl", regexp.MustCompile(`^usr/lib/perl5/(5\.\d+)/`), 2},
+}
+
+// InstalledABIs returns the language runtimes installed on the system.
+func InstalledABIs(dbExecutor db.Executor) []ABI {
+	abis := make([]ABI, 0, len(knownABIs))
+
+	for _, known := range knownABIs {
+		pkg := dbExecutor.LocalPackage(known.name)
+		if pkg == nil {
+			continue
+		}
+
+		abis = append(abis, ABI{
+			Name:    known.name,
+			Current: abiVersion(pkg.Version(), known.components),
+			dir:     known.dir,
+		})
+	}
+
+	return abis
+}
+
+// abiVersion keeps the leading components of a package version, such as 3.12
+// for python 3.12.4-1.
+func abiVersion(version string, components int) string {
+	if _, after, ok := strings.Cut(version, ":"); ok {
+		version = after
+	}
+
+	version, _, _ = strings.Cut(version, "-")
+	parts := strings.Split(version, ".")
+
+	return strings.Join(parts[:min(components, len(parts))], ".")
+}
+
+// outdated returns the runtime version of a module directory when it is not
+// the installed one.
+func (a *ABI) outdated(dir string) (string, bool) {
+	match := a.dir.FindStringSubmatch(dir)
+	if match == nil || match[1] == a.Current {
+		return "", false
+	}
+
+	return match[1], true
+}
@@ -0,0 +1,256 @@
+// Package rebuild finds installed foreign packages that need to be rebuilt
+// because a repo upgrade removed a library they link against or moved the
+// language runtime they install modules for.
+package rebuild
+
+import (
+	"bufio"
+	"debug/elf"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Jguer/yay/v12/pkg/db"
+)
+
+// Kinds of problems found in a package.
+const (
+	KindMissingLibrary = "missing-library"
+	KindABI            = "abi"
+)
+
+// Problem is a reason a package needs a rebuild.
+type Problem struct {
+	Kind string `json:"kind"`
+	// File is the installed file with the problem, relative to the root.
+	File string `json:"file"`
+	// Detail is the missing library or the outdated runtime version.
+	Detail string `json:"detail"`
+}
+
+// Result lists the problems of an installed package.
+type Result struct {
+	Name     string    `json:"name"`
+	Base     string    `json:"base"`
+	Problems []Problem `json:"problems"`
+}
+
+// Checker inspects the files of installed packages.
+type Checker struct {
+	// Root is the file system root the package files are installed under.
+	Root string
+	// LibraryPaths are the directories searched for libraries of 64 and 32
+	// bit ELF files in addition to their RUNPATH.
+	LibraryPaths   []string
+	LibraryPaths32 []string
+	// ABIs are the language runtimes installed on the system.
+	ABIs []ABI
+}
+
+// NewChecker creates a checker for the system at root. The library search
+// path is completed from the ld.so.conf of the system.
+func NewChecker(root string, abis []ABI) *Checker {
+	return &Checker{
+		Root:           root,
+		LibraryPaths:   append([]string{"/usr/lib", "/lib", "/lib64", "/usr/lib64"}, ldSoConfPaths(root, "/etc/ld.so.conf")...),
+		LibraryPaths32: []string{"/usr/lib32", "/lib32"},
+		ABIs:           abis,
+	}
+}
+
+// Check returns the foreign packages with problems, sorted by base and name.
+func (c *Checker) Check(pkgs []db.IPackage) []Result {
+	results := make([]Result, 0)
+
+	for _, pkg := range pkgs {
+		if problems := c.checkPackage(pkg); len(problems) > 0 {
+			results = append(results, Result{Name: pkg.Name(), Base: pkg.Base(), Problems: problems})
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Base != results[j].Base {
+			return results[i].Base < results[j].Base
+		}
+
+		return results[i].Name < results[j].Name
+	})
+
+	return results
+}
+
+func (c *Checker) checkPackage(pkg db.IPackage) []Problem {
+	files := pkg.Files()
+
+	// libraries shipped by the package itself are often found through a
+	// wrapper setting LD_LIBRARY_PATH
+	shipped := make(map[string]bool, len(files))
+	for _, file := range files {
+		shipped[path.Base(file.Name)] = true
+	}
+
+	problems := make([]Problem, 0)
+	seen := make(map[string]bool)
+
+	add := func(problem Problem) {
+		key := problem.Kind + "\x00" + problem.Detail
+		if !seen[key] {
+			seen[key] = true
+			problems = append(problems, problem)
+		}
+	}
+
+	for _, file := range files {
+		if strings.HasSuffix(file.Name, "/") {
+			for i := range c.ABIs {
+				if version, ok := c.ABIs[i].outdated(file.Name); ok {
+					add(Problem{Kind: KindABI, File: file.Name, Detail: c.ABIs[i].Name + " " + version})
+				}
+			}
+
+			continue
+		}
+
+		for _, lib := range c.missingLibraries(file.Name, shipped) {
+			add(Problem{Kind: KindMissingLibrary, File: file.Name, Detail: lib})
+		}
+	}
+
+	return problems
+}
+
+// missingLibraries returns the libraries needed by an ELF file that can not
+// be found. Files that are not dynamically linked ELF files need nothing.
+func (c *Checker) missingLibraries(name string, shipped map[string]bool) []string {
+	fullPath := filepath.Join(c.Root, name)
+
+	stat, err := os.Lstat(fullPath)
+	if err != nil || !stat.Mode().IsRegular() || !isELF(fullPath) {
+		return nil
+	}
+
+	file, err := elf.Open(fullPath)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	needed, err := file.DynString(elf.DT_NEEDED)
+	if err != nil || len(needed) == 0 {
+		return nil
+	}
+
+	dirs := c.LibraryPaths
+	if file.Class == elf.ELFCLASS32 {
+		dirs = c.LibraryPaths32
+	}
+
+	dirs = append(runPaths(file, "/"+path.Dir(name)), dirs...)
+
+	missing := make([]string, 0)
+
+	for _, lib := range needed {
+		if strings.Contains(lib, "/") || shipped[lib] || c.findLibrary(lib, dirs) {
+			continue
+		}
+
+		missing = append(missing, lib)
+	}
+
+	return missing
+}
+
+func (c *Checker) findLibrary(lib string, dirs []string) bool {
+	for _, dir := range dirs {
+		if _, err := os.Stat(filepath.Join(c.Root, dir, lib)); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// runPaths returns the RUNPATH and RPATH directories of an ELF file, with
+// $ORIGIN expanded to the directory of the file.
+func runPaths(file *elf.File, origin string) []string {
+	dirs := make([]string, 0)
+
+	for _, tag := range []elf.DynTag{elf.DT_RUNPATH, elf.DT_RPATH} {
+		values, err := file.DynString(tag)
+		if err != nil {
+			continue
+		}
+
+		for _, value := range values {
+			for _, dir := range strings.Split(value, ":") {
+				dir = strings.ReplaceAll(dir, "${ORIGIN}", origin)
+				dir = strings.ReplaceAll(dir, "$ORIGIN", origin)
+
+				if dir != "" {
+					dirs = append(dirs, dir)
+				}
+			}
+		}
+	}
+
+	return dirs
+}
+
+func isELF(name string) bool {
+	file, err := os.Open(name)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	magic := make([]byte, len(elf.ELFMAG))
+	if _, err := io.ReadFull(file, magic); err != nil {
+		return false
+	}
+
+	return string(magic) == elf.ELFMAG
+}
+
+// ldSoConfPaths returns the library directories of an ld.so.conf file,
+// following its include directives.
+func ldSoConfPaths(root, conf string) []string {
+	file, err := os.Open(filepath.Join(root, conf))
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	dirs := make([]string, 0)
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		line = strings.TrimSpace(line)
+
+		if pattern, ok := strings.CutPrefix(line, "include "); ok {
+			pattern = strings.TrimSpace(pattern)
+			if !path.IsAbs(pattern) {
+				pattern = path.Join(path.Dir(conf), pattern)
+			}
+
+			matches, _ := filepath.Glob(filepath.Join(root, pattern))
+			for _, match := range matches {
+				rel, err := filepath.Rel(root, match)
+				if err == nil {
+					dirs = append(dirs, ldSoConfPaths(root, "/"+rel)...)
+				}
+			}
+
+			continue
+		}
+
+		if line != "" {
+			dirs = append(dirs, line)
+		}
+	}
+
+	return dirs
+}
@@ -0,0 +1,82 @@
+package rebuild
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Queue holds the packages the next sysupgrade rebuilds.
+type Queue struct {
+	FilePath string   `json:"-"`
+	Packages []string `json:"packages"`
+}
+
+func NewQueue(filePath string) *Queue {
+	return &Queue{
+		FilePath: filePath,
+		Packages: []string{},
+	}
+}
+
+// Load reads the queue from disk. A missing file is an empty queue.
+func (q *Queue) Load() error {
+	content, err := os.ReadFile(q.FilePath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to open rebuild queue '%s': %w", q.FilePath, err)
+	}
+
+	if err := json.Unmarshal(content, q); err != nil {
+		return fmt.Errorf("failed to read rebuild queue '%s': %w", q.FilePath, err)
+	}
+
+	return nil
+}
+
+// Add queues the packages of check results.
+func (q *Queue) Add(results []Result) {
+	queued := make(map[string]bool, len(q.Packages))
+	for _, name := range q.Packages {
+		queued[name] = true
+	}
+
+	for i := range results {
+		if !queued[results[i].Name] {
+			queued[results[i].Name] = true
+			q.Packages = append(q.Packages, results[i].Name)
+		}
+	}
+
+	sort.Strings(q.Packages)
+}
+
+// Save writes the queue to disk.
+func (q *Queue) Save() error {
+	if err := os.MkdirAll(filepath.Dir(q.FilePath), 0o755); err != nil {
+		return err
+	}
+
+	content, err := json.MarshalIndent(q, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(q.FilePath, content, 0o644)
+}
+
+// Clear removes the queue once its packages were rebuilt.
+func (q *Queue) Clear() error {
+	q.Packages = []string{}
+
+	if err := os.Remove(q.FilePath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
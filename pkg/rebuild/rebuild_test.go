@@ -0,0 +1,134 @@
+//go:build !integration
+// +build !integration
+
+package rebuild
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	alpm "github.com/Jguer/go-alpm/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yay/v12/pkg/db"
+	"github.com/Jguer/yay/v12/pkg/db/mock"
+)
+
+func writeFile(t *testing.T, root, name string, content []byte) {
+	t.Helper()
+
+	path := filepath.Join(root, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, content, 0o755))
+}
+
+// testdata/needs.elf needs libicuuc.so.73, libpresent.so.1, libprivate.so.2
+// and libshipped.so, with a RUNPATH of $ORIGIN/../private.
+func TestChecker_Check(t *testing.T) {
+	t.Parallel()
+
+	elfFile, err := os.ReadFile("testdata/needs.elf")
+	require.NoError(t, err)
+
+	root := t.TempDir()
+	writeFile(t, root, "opt/foo/bin/foo", elfFile)
+	writeFile(t, root, "opt/foo/private/libprivate.so.2", nil)
+	writeFile(t, root, "opt/foo/lib/libshipped.so", nil)
+	writeFile(t, root, "opt/foo/README", []byte("not an ELF file"))
+	writeFile(t, root, "usr/lib/libpresent.so.1", nil)
+	writeFile(t, root, "usr/bin/bar", elfFile)
+
+	foo := &mock.Package{PName: "foo", PBase: "foo-base", PFiles: []alpm.File{
+		{Name: "opt/"}, {Name: "opt/foo/"}, {Name: "opt/foo/bin/foo"},
+		{Name: "opt/foo/lib/libshipped.so"}, {Name: "opt/foo/README"},
+		{Name: "usr/lib/python3.11/"}, {Name: "usr/lib/python3.11/site-packages/"},
+	}}
+	// the runpath of bar points to a missing directory and it ships nothing
+	bar := &mock.Package{PName: "bar", PBase: "bar", PFiles: []alpm.File{
+		{Name: "usr/bin/bar"}, {Name: "usr/lib/python3.12/"},
+	}}
+	clean := &mock.Package{PName: "clean", PBase: "clean", PFiles: []alpm.File{
+		{Name: "opt/foo/README"},
+	}}
+
+	checker := NewChecker(root, []ABI{{Name: "python", Current: "3.12", dir: knownABIs[0].dir}})
+	checker.LibraryPaths = []string{"/usr/lib"}
+
+	results := checker.Check([]db.IPackage{foo, bar, clean})
+
+	assert.Equal(t, []Result{
+		{Name: "bar", Base: "bar", Problems: []Problem{
+			{Kind: KindMissingLibrary, File: "usr/bin/bar", Detail: "libicuuc.so.73"},
+			{Kind: KindMissingLibrary, File: "usr/bin/bar", Detail: "libprivate.so.2"},
+			{Kind: KindMissingLibrary, File: "usr/bin/bar", Detail: "libshipped.so"},
+		}},
+		{Name: "foo", Base: "foo-base", Problems: []Problem{
+			{Kind: KindMissingLibrary, File: "opt/foo/bin/foo", Detail: "libicuuc.so.73"},
+			{Kind: KindABI, File: "usr/lib/python3.11/", Detail: "python 3.11"},
+		}},
+	}, results)
+}
+
+func TestInstalledABIs(t *testing.T) {
+	t.Parallel()
+
+	dbExecutor := &mock.DBExecutor{LocalPackageFn: func(name string) mock.IPackage {
+		if name == "python" {
+			return &mock.Package{PName: "python", PVersion: "3.12.4-1"}
+		}
+
+		return nil
+	}}
+
+	abis := InstalledABIs(dbExecutor)
+	require.Len(t, abis, 1)
+	assert.Equal(t, "python", abis[0].Name)
+	assert.Equal(t, "3.12", abis[0].Current)
+
+	_, outdated := abis[0].outdated("usr/lib/python3.12/site-packages/")
+	assert.False(t, outdated)
+
+	version, outdated := abis[0].outdated("usr/lib/python3.9/")
+	assert.True(t, outdated)
+	assert.Equal(t, "3.9", version)
+}
+
+func Test_abiVersion(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "3.12", abiVersion("3.12.4-1", 2))
+	assert.Equal(t, "5.38", abiVersion("1:5.38.2-2", 2))
+	assert.Equal(t, "3", abiVersion("3-1", 2))
+}
+
+func Test_ldSoConfPaths(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	writeFile(t, root, "etc/ld.so.conf", []byte("# comment\ninclude ld.so.conf.d/*.conf\n/usr/local/lib\n"))
+	writeFile(t, root, "etc/ld.so.conf.d/foo.conf", []byte("/opt/foo/lib\n"))
+
+	assert.Equal(t, []string{"/opt/foo/lib", "/usr/local/lib"}, ldSoConfPaths(root, "/etc/ld.so.conf"))
+}
+
+func TestQueue(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state", "rebuild-queue.json")
+
+	queue := NewQueue(path)
+	require.NoError(t, queue.Load())
+	queue.Add([]Result{{Name: "foo"}, {Name: "bar"}})
+	queue.Add([]Result{{Name: "foo"}})
+	require.NoError(t, queue.Save())
+
+	loaded := NewQueue(path)
+	require.NoError(t, loaded.Load())
+	assert.Equal(t, []string{"bar", "foo"}, loaded.Packages)
+
+	require.NoError(t, loaded.Clear())
+	assert.NoFileExists(t, path)
+	require.NoError(t, loaded.Clear())
+}
@@ -0,0 +1,230 @@
+// Package review keeps a ledger of the PKGBUILD revisions that have been
+// reviewed, so that a review survives clean builds and can be shared.
+package review
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Entry records the review of a package base at a given AUR commit.
+type Entry struct {
+	Base     string    `json:"base"`
+	Commit   string    `json:"commit"`
+	Reviewer string    `json:"reviewer"`
+	Time     time.Time `json:"time"`
+	Note     string    `json:"note,omitempty"`
+}
+
+// Ledger is the collection of reviews indexed by package base and commit.
+type Ledger struct {
+	FilePath string
+
+	mux     sync.Mutex
+	entries map[string]map[string]Entry
+	staged  map[string]Entry
+}
+
+func NewLedger(filePath string) *Ledger {
+	return &Ledger{
+		FilePath: filePath,
+		entries:  map[string]map[string]Entry{},
+		staged:   map[string]Entry{},
+	}
+}
+
+// Get returns the review of base at commit if one was recorded.
+func (l *Ledger) Get(base, commit string) (Entry, bool) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	entry, ok := l.entries[base][commit]
+
+	return entry, ok
+}
+
+// Latest returns the most recent review of base at any commit.
+func (l *Ledger) Latest(base string) (Entry, bool) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	var (
+		latest Entry
+		found  bool
+	)
+
+	for _, entry := range l.entries[base] {
+		if !found || entry.Time.After(latest.Time) {
+			latest, found = entry, true
+		}
+	}
+
+	return latest, found
+}
+
+// Record adds a review to the ledger. Existing reviews of the same commit are kept.
+func (l *Ledger) Record(entry Entry) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	l.add(entry)
+}
+
+// Stage holds a review back until Commit records it, so that a review only
+// counts once the reviewed base has been installed.
+func (l *Ledger) Stage(entry Entry) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	l.staged[entry.Base] = entry
+}
+
+// Commit records the staged reviews of the bases accepted by keep and drops
+// the others. It returns the number of new reviews.
+func (l *Ledger) Commit(keep func(base string) bool) int {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	added := 0
+
+	for base, entry := range l.staged {
+		if keep(base) && l.add(entry) {
+			added++
+		}
+	}
+
+	l.staged = map[string]Entry{}
+
+	return added
+}
+
+func (l *Ledger) add(entry Entry) bool {
+	if entry.Base == "" || entry.Commit == "" {
+		return false
+	}
+
+	if _, ok := l.entries[entry.Base]; !ok {
+		l.entries[entry.Base] = map[string]Entry{}
+	}
+
+	if _, ok := l.entries[entry.Base][entry.Commit]; ok {
+		return false
+	}
+
+	l.entries[entry.Base][entry.Commit] = entry
+
+	return true
+}
+
+// Entries returns every review sorted by base and time.
+func (l *Ledger) Entries() []Entry {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	entries := make([]Entry, 0, len(l.entries))
+
+	for _, byCommit := range l.entries {
+		for _, entry := range byCommit {
+			entries = append(entries, entry)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Base != entries[j].Base {
+			return entries[i].Base < entries[j].Base
+		}
+
+		return entries[i].Time.Before(entries[j].Time)
+	})
+
+	return entries
+}
+
+// Export writes the ledger as JSON to w.
+func (l *Ledger) Export(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+
+	return enc.Encode(l.Entries())
+}
+
+// Import merges the reviews read from r into the ledger and returns
+// the number of new reviews.
+func (l *Ledger) Import(r io.Reader) (int, error) {
+	var entries []Entry
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return 0, err
+	}
+
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	added := 0
+
+	for _, entry := range entries {
+		if l.add(entry) {
+			added++
+		}
+	}
+
+	return added, nil
+}
+
+// Load reads the ledger from disk. A missing file is not an error.
+func (l *Ledger) Load() error {
+	file, err := os.Open(l.FilePath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to open review ledger '%s': %w", l.FilePath, err)
+	}
+
+	defer file.Close()
+
+	if _, err := l.Import(file); err != nil {
+		return fmt.Errorf("failed to read review ledger '%s': %w", l.FilePath, err)
+	}
+
+	return nil
+}
+
+// Save atomically replaces the ledger on disk.
+func (l *Ledger) Save() error {
+	if err := os.MkdirAll(filepath.Dir(l.FilePath), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(l.FilePath), filepath.Base(l.FilePath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if err := l.Export(tmp); err != nil {
+		return err
+	}
+
+	if err := tmp.Chmod(0o644); err != nil {
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), l.FilePath)
+}
@@ -0,0 +1,118 @@
+//go:build !integration
+// +build !integration
+
+package review
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLedger_SaveLoad(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state", "review.json")
+	ledger := NewLedger(path)
+
+	reviewed := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	ledger.Record(Entry{Base: "yay", Commit: "abc", Reviewer: "alice", Time: reviewed, Note: "looks fine"})
+	ledger.Record(Entry{Base: "yay", Commit: "abc", Reviewer: "bob", Time: reviewed.Add(time.Hour)})
+	ledger.Record(Entry{Base: "", Commit: "def", Reviewer: "bob", Time: reviewed})
+
+	require.NoError(t, ledger.Save())
+
+	loaded := NewLedger(path)
+	require.NoError(t, loaded.Load())
+
+	entry, ok := loaded.Get("yay", "abc")
+	require.True(t, ok)
+	assert.Equal(t, "alice", entry.Reviewer)
+	assert.Equal(t, "looks fine", entry.Note)
+	assert.True(t, reviewed.Equal(entry.Time))
+
+	_, ok = loaded.Get("yay", "def")
+	assert.False(t, ok)
+	assert.Len(t, loaded.Entries(), 1)
+
+	files, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+}
+
+func TestLedger_StageCommit(t *testing.T) {
+	t.Parallel()
+
+	ledger := NewLedger("")
+	ledger.Stage(Entry{Base: "yay", Commit: "abc", Reviewer: "alice"})
+	ledger.Stage(Entry{Base: "failed", Commit: "def", Reviewer: "alice"})
+
+	_, ok := ledger.Get("yay", "abc")
+	assert.False(t, ok)
+
+	added := ledger.Commit(func(base string) bool { return base != "failed" })
+	assert.Equal(t, 1, added)
+
+	_, ok = ledger.Get("yay", "abc")
+	assert.True(t, ok)
+
+	_, ok = ledger.Get("failed", "def")
+	assert.False(t, ok)
+
+	assert.Zero(t, ledger.Commit(func(string) bool { return true }))
+}
+
+func TestLedger_LoadMissing(t *testing.T) {
+	t.Parallel()
+
+	ledger := NewLedger(filepath.Join(t.TempDir(), "review.json"))
+	require.NoError(t, ledger.Load())
+	assert.Empty(t, ledger.Entries())
+}
+
+func TestLedger_ExportImport(t *testing.T) {
+	t.Parallel()
+
+	source := NewLedger("")
+	source.Record(Entry{Base: "yay", Commit: "abc", Reviewer: "alice"})
+	source.Record(Entry{Base: "paru", Commit: "123", Reviewer: "alice"})
+
+	var buf bytes.Buffer
+	require.NoError(t, source.Export(&buf))
+
+	target := NewLedger("")
+	target.Record(Entry{Base: "yay", Commit: "abc", Reviewer: "bob"})
+
+	added, err := target.Import(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, 1, added)
+
+	entry, ok := target.Get("yay", "abc")
+	require.True(t, ok)
+	assert.Equal(t, "bob", entry.Reviewer)
+
+	_, ok = target.Get("paru", "123")
+	assert.True(t, ok)
+}
+
+func TestLedger_Latest(t *testing.T) {
+	t.Parallel()
+
+	ledger := NewLedger(filepath.Join(t.TempDir(), "review.json"))
+
+	reviewed := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	ledger.Record(Entry{Base: "yay", Commit: "new", Reviewer: "bob", Time: reviewed.Add(time.Hour)})
+	ledger.Record(Entry{Base: "yay", Commit: "old", Reviewer: "alice", Time: reviewed})
+
+	entry, ok := ledger.Latest("yay")
+	require.True(t, ok)
+	assert.Equal(t, "new", entry.Commit)
+
+	_, ok = ledger.Latest("paru")
+	assert.False(t, ok)
+}
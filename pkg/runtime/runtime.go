@@ -6,13 +6,17 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/leonelquinteros/gotext"
 
+	"github.com/Jguer/yay/v12/pkg/aurcache"
 	"github.com/Jguer/yay/v12/pkg/query"
+	"github.com/Jguer/yay/v12/pkg/review"
 	"github.com/Jguer/yay/v12/pkg/settings"
 	"github.com/Jguer/yay/v12/pkg/settings/exe"
 	"github.com/Jguer/yay/v12/pkg/settings/parser"
+	"github.com/Jguer/yay/v12/pkg/sync/srcinfo/pgp"
 	"github.com/Jguer/yay/v12/pkg/text"
 	"github.com/Jguer/yay/v12/pkg/vcs"
 
@@ -28,14 +32,23 @@ type Runtime struct {
 	QueryBuilder query.Builder
 	PacmanConf   *pacmanconf.Config
 	VCSStore     vcs.Store
+	ReviewLedger *review.Ledger
 	CmdBuilder   exe.ICmdBuilder
 	HTTPClient   *http.Client
 	VoteClient   *vote.Client
 	AURClient    aur.QueryClient
-	Logger       *text.Logger
+	// AURInfoClient answers -Si from the AUR metadata dump when the AUR can
+	// not be reached.
+	AURInfoClient aur.QueryClient
+	AURCache      *aurcache.Cache
+	Logger        *text.Logger
 }
 
 func NewRuntime(cfg *settings.Configuration, cmdArgs *parser.Arguments, version string) (*Runtime, error) {
+	if errLookup := pgp.ValidateLookup(cfg.PGPKeyLookup); errLookup != nil {
+		return nil, errLookup
+	}
+
 	logger := text.NewLogger(os.Stdout, os.Stderr, os.Stdin, cfg.Debug, "runtime")
 	runner := exe.NewOSRunner(logger.Child("runner"))
 
@@ -61,10 +74,14 @@ func NewRuntime(cfg *settings.Configuration, cmdArgs *parser.Arguments, version
 		return nil
 	}
 
+	aurCachePath := filepath.Join(cfg.BuildDir, "aur.json")
+	aurMetadata := aurcache.New(aurCachePath, cfg.AURURL, httpClient, logger.Debugln,
+		func(msg string) { fmt.Fprintln(os.Stderr, logger.SprintWarn(msg)) }, userAgentFn)
+
 	var aurCache aur.QueryClient
 	aurCache, errAURCache := metadata.New(
 		metadata.WithHTTPClient(httpClient),
-		metadata.WithCacheFilePath(filepath.Join(cfg.BuildDir, "aur.json")),
+		metadata.WithCacheFilePath(aurCachePath),
 		metadata.WithRequestEditorFn(userAgentFn),
 		metadata.WithBaseURL(cfg.AURURL),
 		metadata.WithDebugLogger(logger.Debugln),
@@ -73,6 +90,7 @@ func NewRuntime(cfg *settings.Configuration, cmdArgs *parser.Arguments, version
 		return nil, fmt.Errorf(gotext.Get("failed to retrieve aur Cache")+": %w", errAURCache)
 	}
 
+	var aurClient aur.QueryClient
 	aurClient, errAUR := rpc.NewClient(
 		rpc.WithHTTPClient(httpClient),
 		rpc.WithBaseURL(cfg.AURRPCURL),
@@ -86,6 +104,15 @@ func NewRuntime(cfg *settings.Configuration, cmdArgs *parser.Arguments, version
 		aurCache = aurClient
 	}
 
+	// searches and info keep working from the dump when the AUR can not be
+	// reached, completion falls back to it on its own
+	aurInfoClient := aurMetadata.Fallback(aurClient)
+	if cfg.Offline {
+		aurCache = aurMetadata.Offline()
+		aurClient = aurCache
+		aurInfoClient = aurCache
+	}
+
 	pacmanConf, useColor, err := retrievePacmanConfig(cmdArgs, cfg.PacmanConf)
 	if err != nil {
 		return nil, err
@@ -99,27 +126,51 @@ func NewRuntime(cfg *settings.Configuration, cmdArgs *parser.Arguments, version
 	vcsStore := vcs.NewInfoStore(
 		cfg.VCSFilePath, cmdBuilder,
 		logger.Child("vcs"))
+	vcsStore.TagTracking = &vcs.TagTracking{
+		All:      cfg.DevelTags,
+		Pattern:  cfg.DevelTagPattern,
+		Packages: cfg.DevelTagPackages,
+	}
+	vcsStore.CloneDir = filepath.Join(filepath.Dir(cfg.VCSFilePath), ".vcs-clones")
+
+	if cfg.DevelCheckTTL != "" {
+		ttl, err := time.ParseDuration(cfg.DevelCheckTTL)
+		if err != nil {
+			return nil, fmt.Errorf(gotext.Get("invalid develcheckttl '%s'", cfg.DevelCheckTTL)+": %w", err)
+		}
+
+		vcsStore.CheckTTL = ttl
+	}
 
 	if err := vcsStore.Load(); err != nil {
 		return nil, err
 	}
 
+	reviewLedger := review.NewLedger(cfg.ReviewLedgerPath)
+	if err := reviewLedger.Load(); err != nil {
+		logger.Warnln(err)
+	}
+
 	queryBuilder := query.NewSourceQueryBuilder(
-		aurClient,
+		aurInfoClient,
 		logger.Child("mixed.querybuilder"), cfg.SortBy,
 		cfg.Mode, cfg.SearchBy,
-		cfg.BottomUp, cfg.SingleLineResults, cfg.SeparateSources)
+		cfg.BottomUp, cfg.SingleLineResults, cfg.SeparateSources,
+		cfg.RankWeights, cfg.ExplainRank)
 
 	run := &Runtime{
-		Cfg:          cfg,
-		QueryBuilder: queryBuilder,
-		PacmanConf:   pacmanConf,
-		VCSStore:     vcsStore,
-		CmdBuilder:   cmdBuilder,
-		HTTPClient:   &http.Client{},
-		VoteClient:   voteClient,
-		AURClient:    aurCache,
-		Logger:       logger,
+		Cfg:           cfg,
+		QueryBuilder:  queryBuilder,
+		PacmanConf:    pacmanConf,
+		VCSStore:      vcsStore,
+		ReviewLedger:  reviewLedger,
+		CmdBuilder:    cmdBuilder,
+		HTTPClient:    &http.Client{},
+		VoteClient:    voteClient,
+		AURClient:     aurCache,
+		AURInfoClient: aurInfoClient,
+		AURCache:      aurMetadata,
+		Logger:        logger,
 	}
 
 	return run, nil
@@ -4,6 +4,7 @@
 package runtime_test
 
 import (
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -50,3 +51,36 @@ func TestBuildRuntime(t *testing.T) {
 	assert.NotNil(t, run.AURClient)
 	assert.NotNil(t, run.Logger)
 }
+
+func TestBuildRuntimeUnknownPGPKeyLookup(t *testing.T) {
+	t.Parallel()
+
+	cfg := &settings.Configuration{
+		BuildDir:     "/tmp",
+		PGPKeyLookup: []string{"wkd", "ldap"},
+	}
+
+	_, err := runtime.NewRuntime(cfg, parser.MakeArguments(), "1.0.0")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ldap")
+}
+
+func TestBuildRuntimeCorruptReviewLedger(t *testing.T) {
+	t.Parallel()
+
+	absPath, err := filepath.Abs("../../testdata/pacman.conf")
+	require.NoError(t, err)
+
+	ledgerPath := filepath.Join(t.TempDir(), "review.json")
+	require.NoError(t, os.WriteFile(ledgerPath, []byte("{not json"), 0o644))
+
+	cfg := &settings.Configuration{
+		BuildDir:         "/tmp",
+		ReviewLedgerPath: ledgerPath,
+		PacmanConf:       absPath,
+	}
+
+	run, err := runtime.NewRuntime(cfg, parser.MakeArguments(), "1.0.0")
+	require.NoError(t, err)
+	assert.Empty(t, run.ReviewLedger.Entries())
+}
@@ -0,0 +1,114 @@
+package sbom
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+type cycloneDXDocument struct {
+	BOMFormat   string               `json:"bomFormat"`
+	SpecVersion string               `json:"specVersion"`
+	Version     int                  `json:"version"`
+	Metadata    cycloneDXMetadata    `json:"metadata"`
+	Components  []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXMetadata struct {
+	Timestamp string          `json:"timestamp"`
+	Tools     []cycloneDXTool `json:"tools"`
+}
+
+type cycloneDXTool struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type cycloneDXComponent struct {
+	Type               string               `json:"type"`
+	BOMRef             string               `json:"bom-ref"`
+	Name               string               `json:"name"`
+	Version            string               `json:"version"`
+	PURL               string               `json:"purl"`
+	Licenses           []cycloneDXLicense   `json:"licenses,omitempty"`
+	ExternalReferences []cycloneDXReference `json:"externalReferences,omitempty"`
+	Properties         []cycloneDXProperty  `json:"properties"`
+}
+
+type cycloneDXLicense struct {
+	License struct {
+		Name string `json:"name"`
+	} `json:"license"`
+}
+
+type cycloneDXReference struct {
+	Type    string `json:"type"`
+	URL     string `json:"url"`
+	Comment string `json:"comment,omitempty"`
+}
+
+type cycloneDXProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+func writeCycloneDX(w io.Writer, doc *Document) error {
+	out := cycloneDXDocument{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: "1.5",
+		Version:     1,
+		Metadata: cycloneDXMetadata{
+			Timestamp: doc.Created.UTC().Format(time.RFC3339),
+			Tools:     []cycloneDXTool{{Name: doc.Tool, Version: doc.ToolVersion}},
+		},
+		Components: make([]cycloneDXComponent, 0, len(doc.Components)),
+	}
+
+	for i := range doc.Components {
+		component := &doc.Components[i]
+		ref := purl(component)
+
+		entry := cycloneDXComponent{
+			Type:       "application",
+			BOMRef:     ref,
+			Name:       component.Name,
+			Version:    component.Version,
+			PURL:       ref,
+			Properties: []cycloneDXProperty{{Name: "alpm:repository", Value: component.Repository}},
+		}
+
+		for _, name := range component.Licenses {
+			license := cycloneDXLicense{}
+			license.License.Name = name
+			entry.Licenses = append(entry.Licenses, license)
+		}
+
+		if component.URL != "" {
+			entry.ExternalReferences = append(entry.ExternalReferences,
+				cycloneDXReference{Type: "website", URL: component.URL})
+		}
+
+		if component.Foreign() {
+			entry.ExternalReferences = append(entry.ExternalReferences,
+				cycloneDXReference{Type: "vcs", URL: aurGitURL(doc, component), Comment: "AUR"})
+			entry.Properties = append(entry.Properties, cycloneDXProperty{Name: "aur:base", Value: component.Base})
+
+			if component.AURCommit != "" {
+				entry.Properties = append(entry.Properties,
+					cycloneDXProperty{Name: "aur:commit", Value: component.AURCommit})
+			}
+
+			for _, source := range component.Sources {
+				entry.ExternalReferences = append(entry.ExternalReferences,
+					cycloneDXReference{Type: "distribution", URL: source, Comment: "upstream source"})
+			}
+		}
+
+		out.Components = append(out.Components, entry)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+
+	return enc.Encode(out)
+}
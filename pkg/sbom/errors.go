@@ -0,0 +1,11 @@
+package sbom
+
+import "github.com/leonelquinteros/gotext"
+
+type ErrUnknownFormat struct {
+	format string
+}
+
+func (e *ErrUnknownFormat) Error() string {
+	return gotext.Get("unknown SBOM format '%s', expected '%s' or '%s'", e.format, FormatSPDX, FormatCycloneDX)
+}
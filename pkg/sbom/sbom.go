@@ -0,0 +1,187 @@
+// Package sbom builds software bills of materials for the installed packages.
+package sbom
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	alpm "github.com/Jguer/go-alpm/v2"
+	gosrc "github.com/Morganamilo/go-srcinfo"
+
+	"github.com/Jguer/yay/v12/pkg/db"
+	"github.com/Jguer/yay/v12/pkg/settings/exe"
+	"github.com/Jguer/yay/v12/pkg/text"
+)
+
+const (
+	FormatSPDX      = "spdx"
+	FormatCycloneDX = "cyclonedx"
+
+	// aurRepository is the origin reported for foreign packages.
+	aurRepository = "aur"
+)
+
+// Component is a single installed package of the bill of materials.
+type Component struct {
+	Name       string
+	Version    string
+	Licenses   []string
+	URL        string
+	Repository string
+
+	// Foreign package information, empty for repository packages.
+	Base      string
+	AURCommit string
+	Sources   []string
+}
+
+// Foreign reports whether the package was not installed from a sync repository.
+func (c *Component) Foreign() bool {
+	return c.Repository == aurRepository
+}
+
+// Document is a bill of materials ready to be written in any format.
+type Document struct {
+	Name        string
+	Tool        string
+	ToolVersion string
+	AURURL      string
+	Created     time.Time
+	Components  []Component
+}
+
+// Collector gathers the components of the bill of materials.
+type Collector struct {
+	dbExecutor db.Executor
+	cmdBuilder exe.ICmdBuilder
+	buildDir   string
+	log        *text.Logger
+}
+
+func NewCollector(dbExecutor db.Executor, cmdBuilder exe.ICmdBuilder,
+	buildDir string, logger *text.Logger,
+) *Collector {
+	return &Collector{
+		dbExecutor: dbExecutor,
+		cmdBuilder: cmdBuilder,
+		buildDir:   buildDir,
+		log:        logger,
+	}
+}
+
+// Components enumerates all installed packages.
+func (c *Collector) Components(ctx context.Context) []Component {
+	pkgs := c.dbExecutor.LocalPackages()
+	components := make([]Component, 0, len(pkgs))
+
+	for _, pkg := range pkgs {
+		component := Component{
+			Name:       pkg.Name(),
+			Version:    pkg.Version(),
+			Licenses:   c.dbExecutor.PackageLicenses(pkg),
+			URL:        pkg.URL(),
+			Repository: aurRepository,
+		}
+
+		if syncPkg := c.dbExecutor.SyncPackage(pkg.Name()); syncPkg != nil && !locallyBuilt(pkg) {
+			component.Repository = syncPkg.DB().Name()
+		} else {
+			c.addForeignInfo(ctx, &component, pkg.Base())
+		}
+
+		components = append(components, component)
+	}
+
+	return components
+}
+
+// locallyBuilt reports whether pacman installed pkg without validating it,
+// as it does for packages built with makepkg. Such a package is foreign even
+// when a sync repository has a package of the same name.
+func locallyBuilt(pkg alpm.IPackage) bool {
+	return pkg.Validation()&alpm.ValidationNone != 0
+}
+
+// addForeignInfo fills in the AUR information available in the build directory.
+func (c *Collector) addForeignInfo(ctx context.Context, component *Component, base string) {
+	component.Base = base
+	dir := filepath.Join(c.buildDir, base)
+
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		stdout, stderr, err := c.cmdBuilder.Capture(
+			c.cmdBuilder.BuildGitCmd(ctx, dir, "rev-parse", "HEAD"))
+		if err != nil {
+			c.log.Debugln("unable to read AUR commit of", base, stderr, err)
+		} else {
+			component.AURCommit = strings.TrimSpace(stdout)
+		}
+	}
+
+	pkgbuild, err := gosrc.ParseFile(filepath.Join(dir, ".SRCINFO"))
+	if err != nil {
+		c.log.Debugln("no cached .SRCINFO for", base, err)
+		return
+	}
+
+	component.Sources = sourceURLs(pkgbuild)
+}
+
+// sourceURLs returns the remote sources of a .SRCINFO, dropping local files
+// and the "name::" rename prefix.
+func sourceURLs(pkgbuild *gosrc.Srcinfo) []string {
+	urls := make([]string, 0, len(pkgbuild.Source))
+
+	for _, source := range pkgbuild.Source {
+		value := source.Value
+		if _, after, found := strings.Cut(value, "::"); found {
+			value = after
+		}
+
+		if strings.Contains(value, "://") {
+			urls = append(urls, value)
+		}
+	}
+
+	return urls
+}
+
+// CheckFormat returns an error if format is not a supported SBOM format.
+func CheckFormat(format string) error {
+	if format != FormatSPDX && format != FormatCycloneDX {
+		return &ErrUnknownFormat{format: format}
+	}
+
+	return nil
+}
+
+// Write encodes the document in the given format.
+func Write(w io.Writer, doc *Document, format string) error {
+	switch format {
+	case FormatSPDX:
+		return writeSPDX(w, doc)
+	case FormatCycloneDX:
+		return writeCycloneDX(w, doc)
+	}
+
+	return &ErrUnknownFormat{format: format}
+}
+
+// purl returns the package URL identifying a component.
+func purl(component *Component) string {
+	namespace := "arch"
+	if component.Foreign() {
+		namespace = aurRepository
+	}
+
+	return "pkg:alpm/" + namespace + "/" + component.Name + "@" +
+		strings.ReplaceAll(component.Version, ":", "%3A")
+}
+
+// aurGitURL returns the AUR git repository of a foreign component.
+func aurGitURL(doc *Document, component *Component) string {
+	return strings.TrimSuffix(doc.AURURL, "/") + "/" + component.Base + ".git"
+}
@@ -0,0 +1,250 @@
+//go:build !integration
+// +build !integration
+
+package sbom
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	alpm "github.com/Jguer/go-alpm/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yay/v12/pkg/db/mock"
+	"github.com/Jguer/yay/v12/pkg/settings/exe"
+	"github.com/Jguer/yay/v12/pkg/text"
+)
+
+const fooSrcinfo = `pkgbase = foo
+	pkgver = 1.0
+	pkgrel = 1
+	url = https://foo.example.org
+	arch = x86_64
+	license = MIT
+	source = foo-1.0.tar.gz::https://foo.example.org/foo-1.0.tar.gz
+	source = git+https://github.com/foo/foo-assets.git
+	source = foo.patch
+	sha256sums = SKIP
+	sha256sums = SKIP
+	sha256sums = SKIP
+
+pkgname = foo
+`
+
+func testCollector(t *testing.T) *Collector {
+	t.Helper()
+
+	buildDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(buildDir, "foo", ".git"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(buildDir, "foo", ".SRCINFO"), []byte(fooSrcinfo), 0o644))
+
+	dbExe := &mock.DBExecutor{
+		LocalPackagesFn: func() []mock.IPackage {
+			return []mock.IPackage{
+				&mock.Package{
+					PName: "bash", PBase: "bash", PVersion: "5.2.015-1", PURL: "https://www.gnu.org/software/bash/",
+					PValidation: alpm.ValidationSignature,
+				},
+				&mock.Package{
+					PName: "foo", PBase: "foo", PVersion: "1:1.0-1", PURL: "https://foo.example.org",
+					PValidation: alpm.ValidationNone,
+				},
+			}
+		},
+		SyncPackageFn: func(name string) mock.IPackage {
+			switch name {
+			case "bash":
+				return &mock.Package{PName: "bash", PDB: mock.NewDB("core")}
+			case "foo":
+				// a repo package of the same name must not hide the AUR one
+				return &mock.Package{PName: "foo", PDB: mock.NewDB("extra")}
+			}
+			return nil
+		},
+		PackageLicensesFn: func(pkg mock.IPackage) []string {
+			if pkg.Name() == "bash" {
+				return []string{"GPL-3.0-or-later"}
+			}
+			return []string{"MIT", "custom:foo"}
+		},
+	}
+
+	cmdBuilder := &exe.MockBuilder{Runner: &exe.MockRunner{
+		CaptureFn: func(cmd *exec.Cmd) (string, string, error) {
+			return "0123456789abcdef\n", "", nil
+		},
+	}}
+
+	logger := text.NewLogger(io.Discard, io.Discard, strings.NewReader(""), false, "test")
+
+	return NewCollector(dbExe, cmdBuilder, buildDir, logger)
+}
+
+func TestCollector_Components(t *testing.T) {
+	t.Parallel()
+
+	components := testCollector(t).Components(context.Background())
+
+	assert.Equal(t, []Component{
+		{
+			Name: "bash", Version: "5.2.015-1", Licenses: []string{"GPL-3.0-or-later"},
+			URL: "https://www.gnu.org/software/bash/", Repository: "core",
+		},
+		{
+			Name: "foo", Version: "1:1.0-1", Licenses: []string{"MIT", "custom:foo"},
+			URL: "https://foo.example.org", Repository: "aur",
+			Base: "foo", AURCommit: "0123456789abcdef",
+			Sources: []string{"https://foo.example.org/foo-1.0.tar.gz", "git+https://github.com/foo/foo-assets.git"},
+		},
+	}, components)
+}
+
+func TestCollector_ForeignSharingSyncName(t *testing.T) {
+	t.Parallel()
+
+	dbExe := &mock.DBExecutor{
+		LocalPackagesFn: func() []mock.IPackage {
+			return []mock.IPackage{
+				&mock.Package{PName: "zlib", PBase: "zlib", PValidation: alpm.ValidationSignature | alpm.ValidationSHA256Sum},
+				&mock.Package{PName: "linux", PBase: "linux-custom", PValidation: alpm.ValidationNone},
+				&mock.Package{PName: "legacy", PBase: "legacy", PValidation: alpm.ValidationUnkown},
+			}
+		},
+		SyncPackageFn: func(name string) mock.IPackage {
+			switch name {
+			case "zlib":
+				return &mock.Package{PName: "zlib", PDB: mock.NewDB("core")}
+			case "linux":
+				return &mock.Package{PName: "linux", PDB: mock.NewDB("core")}
+			}
+			return nil
+		},
+		PackageLicensesFn: func(pkg mock.IPackage) []string { return nil },
+	}
+
+	logger := text.NewLogger(io.Discard, io.Discard, strings.NewReader(""), false, "test")
+	collector := NewCollector(dbExe, &exe.MockBuilder{Runner: &exe.MockRunner{}}, t.TempDir(), logger)
+
+	repositories := map[string]string{}
+	bases := map[string]string{}
+	for _, component := range collector.Components(context.Background()) {
+		repositories[component.Name] = component.Repository
+		bases[component.Name] = component.Base
+	}
+
+	assert.Equal(t, map[string]string{"zlib": "core", "linux": "aur", "legacy": "aur"}, repositories)
+	assert.Equal(t, "linux-custom", bases["linux"])
+}
+
+func testDocument(t *testing.T) *Document {
+	t.Helper()
+
+	return &Document{
+		Name:        "host",
+		Tool:        "yay",
+		ToolVersion: "12.0.0",
+		AURURL:      "https://aur.archlinux.org",
+		Created:     time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC),
+		Components:  testCollector(t).Components(context.Background()),
+	}
+}
+
+func TestWrite_SPDX(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, testDocument(t), FormatSPDX))
+
+	var doc spdxDocument
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+
+	assert.Equal(t, "SPDX-2.3", doc.SPDXVersion)
+	assert.Equal(t, "2023-05-01T12:00:00Z", doc.CreationInfo.Created)
+	require.Len(t, doc.Packages, 2)
+	require.Len(t, doc.Relationships, 2)
+
+	assert.Equal(t, "GPL-3.0-or-later", doc.Packages[0].LicenseDeclared)
+	assert.Equal(t, "NOASSERTION", doc.Packages[0].DownloadLocation)
+	assert.Equal(t, "pkg:alpm/arch/bash@5.2.015-1", doc.Packages[0].ExternalRefs[0].ReferenceLocator)
+
+	foo := doc.Packages[1]
+	assert.Equal(t, "(MIT AND LicenseRef-custom-foo)", foo.LicenseDeclared)
+	assert.Equal(t, "git+https://aur.archlinux.org/foo.git@0123456789abcdef", foo.DownloadLocation)
+	assert.Equal(t, "pkg:alpm/aur/foo@1%3A1.0-1", foo.ExternalRefs[0].ReferenceLocator)
+	assert.Contains(t, foo.SourceInfo, "AUR base: foo")
+	assert.Contains(t, foo.SourceInfo, "https://foo.example.org/foo-1.0.tar.gz")
+}
+
+func TestWrite_SPDXUniqueIDs(t *testing.T) {
+	t.Parallel()
+
+	doc := &Document{
+		Name:    "host",
+		Created: time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC),
+		Components: []Component{
+			{Name: "lib_foo", Repository: "extra"},
+			{Name: "lib-foo", Repository: "extra"},
+			{Name: "lib+foo", Repository: "extra"},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, doc, FormatSPDX))
+
+	var out spdxDocument
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+
+	ids := make([]string, 0, len(out.Packages))
+	for _, pkg := range out.Packages {
+		ids = append(ids, pkg.SPDXID)
+	}
+
+	assert.Equal(t, []string{
+		"SPDXRef-Package-lib-foo", "SPDXRef-Package-lib-foo-2", "SPDXRef-Package-lib-foo-3",
+	}, ids)
+}
+
+func TestWrite_CycloneDX(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, testDocument(t), FormatCycloneDX))
+
+	var doc cycloneDXDocument
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+
+	assert.Equal(t, "CycloneDX", doc.BOMFormat)
+	require.Len(t, doc.Components, 2)
+
+	bash := doc.Components[0]
+	assert.Equal(t, []cycloneDXProperty{{Name: "alpm:repository", Value: "core"}}, bash.Properties)
+	assert.Equal(t, []cycloneDXReference{{Type: "website", URL: "https://www.gnu.org/software/bash/"}}, bash.ExternalReferences)
+
+	foo := doc.Components[1]
+	assert.Equal(t, []cycloneDXProperty{
+		{Name: "alpm:repository", Value: "aur"},
+		{Name: "aur:base", Value: "foo"},
+		{Name: "aur:commit", Value: "0123456789abcdef"},
+	}, foo.Properties)
+	assert.Contains(t, foo.ExternalReferences,
+		cycloneDXReference{Type: "vcs", URL: "https://aur.archlinux.org/foo.git", Comment: "AUR"})
+	assert.Contains(t, foo.ExternalReferences,
+		cycloneDXReference{Type: "distribution", URL: "git+https://github.com/foo/foo-assets.git", Comment: "upstream source"})
+}
+
+func TestWrite_UnknownFormat(t *testing.T) {
+	t.Parallel()
+
+	err := Write(io.Discard, &Document{}, "xml")
+	assert.ErrorContains(t, err, "unknown SBOM format 'xml'")
+	assert.NoError(t, CheckFormat(FormatCycloneDX))
+}
@@ -0,0 +1,161 @@
+package sbom
+
+import (
+	"encoding/json"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const spdxNoAssertion = "NOASSERTION"
+
+var (
+	spdxIDInvalid      = regexp.MustCompile(`[^A-Za-z0-9.-]`)
+	spdxLicenseInvalid = regexp.MustCompile(`[^A-Za-z0-9.+-]`)
+)
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name             string            `json:"name"`
+	SPDXID           string            `json:"SPDXID"`
+	VersionInfo      string            `json:"versionInfo"`
+	DownloadLocation string            `json:"downloadLocation"`
+	Homepage         string            `json:"homepage,omitempty"`
+	LicenseConcluded string            `json:"licenseConcluded"`
+	LicenseDeclared  string            `json:"licenseDeclared"`
+	CopyrightText    string            `json:"copyrightText"`
+	SourceInfo       string            `json:"sourceInfo,omitempty"`
+	ExternalRefs     []spdxExternalRef `json:"externalRefs"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+func writeSPDX(w io.Writer, doc *Document) error {
+	out := spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              doc.Name,
+		DocumentNamespace: "https://spdx.org/spdxdocs/" + doc.Name + "-" + doc.Created.UTC().Format("20060102T150405Z"),
+		CreationInfo: spdxCreationInfo{
+			Created:  doc.Created.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: " + doc.Tool + "-" + doc.ToolVersion},
+		},
+		Packages:      make([]spdxPackage, 0, len(doc.Components)),
+		Relationships: make([]spdxRelationship, 0, len(doc.Components)),
+	}
+
+	ids := make(map[string]bool, len(doc.Components))
+
+	for i := range doc.Components {
+		component := &doc.Components[i]
+		id := spdxPackageID(ids, component.Name)
+
+		pkg := spdxPackage{
+			Name:             component.Name,
+			SPDXID:           id,
+			VersionInfo:      component.Version,
+			DownloadLocation: spdxNoAssertion,
+			Homepage:         component.URL,
+			LicenseConcluded: spdxNoAssertion,
+			LicenseDeclared:  spdxLicenseExpression(component.Licenses),
+			CopyrightText:    spdxNoAssertion,
+			SourceInfo:       "repository: " + component.Repository,
+			ExternalRefs: []spdxExternalRef{{
+				ReferenceCategory: "PACKAGE-MANAGER",
+				ReferenceType:     "purl",
+				ReferenceLocator:  purl(component),
+			}},
+		}
+
+		if component.Foreign() {
+			pkg.DownloadLocation = "git+" + aurGitURL(doc, component)
+			if component.AURCommit != "" {
+				pkg.DownloadLocation += "@" + component.AURCommit
+			}
+
+			pkg.SourceInfo += ", AUR base: " + component.Base
+			if len(component.Sources) > 0 {
+				pkg.SourceInfo += ", upstream sources: " + strings.Join(component.Sources, " ")
+			}
+		}
+
+		out.Packages = append(out.Packages, pkg)
+		out.Relationships = append(out.Relationships, spdxRelationship{
+			SPDXElementID:      out.SPDXID,
+			RelationshipType:   "DESCRIBES",
+			RelatedSPDXElement: id,
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+
+	return enc.Encode(out)
+}
+
+// spdxPackageID returns an SPDX identifier for a package name not in used,
+// suffixing the sanitized name with a counter when names such as lib_foo and
+// lib-foo collide.
+func spdxPackageID(used map[string]bool, name string) string {
+	base := "SPDXRef-Package-" + spdxIDInvalid.ReplaceAllString(name, "-")
+	id := base
+
+	for n := 2; used[id]; n++ {
+		id = base + "-" + strconv.Itoa(n)
+	}
+
+	used[id] = true
+
+	return id
+}
+
+// spdxLicenseExpression joins package licenses into an SPDX license
+// expression. Non SPDX identifiers such as "custom:foo" become LicenseRefs.
+func spdxLicenseExpression(licenses []string) string {
+	if len(licenses) == 0 {
+		return spdxNoAssertion
+	}
+
+	ids := make([]string, 0, len(licenses))
+	for _, license := range licenses {
+		if spdxLicenseInvalid.MatchString(license) {
+			license = "LicenseRef-" + spdxIDInvalid.ReplaceAllString(license, "-")
+		}
+
+		ids = append(ids, license)
+	}
+
+	if len(ids) == 1 {
+		return ids[0]
+	}
+
+	return "(" + strings.Join(ids, " AND ") + ")"
+}
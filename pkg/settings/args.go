@@ -63,6 +63,10 @@ func (c *Configuration) handleOption(option, value string) bool {
 		return !boolValue
 	case "devel":
 		c.Devel = boolValue
+	case "develtags":
+		c.DevelTags = boolValue
+	case "noupgradedelay":
+		c.NoUpgradeDelay = boolValue
 	case "timeupdate":
 		c.TimeUpdate = boolValue
 	case "topdown":
@@ -82,6 +86,12 @@ func (c *Configuration) handleOption(option, value string) bool {
 		c.SortBy = value
 	case "searchby":
 		c.SearchBy = value
+	case "explain-rank":
+		c.ExplainRank = boolValue
+	case "offline":
+		c.Offline = boolValue
+	case "migrate":
+		c.Migrate = boolValue
 	case "noconfirm":
 		NoConfirm = boolValue
 	case "config":
@@ -140,6 +150,14 @@ func (c *Configuration) handleOption(option, value string) bool {
 		c.PacmanBin = value
 	case "git":
 		c.GitBin = value
+	case "hg":
+		c.HgBin = value
+	case "svn":
+		c.SvnBin = value
+	case "bzr":
+		c.BzrBin = value
+	case "fossil":
+		c.FossilBin = value
 	case "gpg":
 		c.GpgBin = value
 	case "sudo":
@@ -181,6 +199,13 @@ func (c *Configuration) handleOption(option, value string) bool {
 		c.RemoveMake = "askyes"
 	case "separatesources":
 		c.SeparateSources = boolValue
+	case "reviewnote":
+		c.ReviewNote = value
+	case "unattended":
+		c.Unattended = boolValue
+		NoConfirm = NoConfirm || boolValue
+	case "report":
+		c.ReportPath = value
 	default:
 		return false
 	}
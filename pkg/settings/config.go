@@ -38,6 +38,10 @@ type Configuration struct {
 	AnswerEdit             string `json:"answeredit"`
 	AnswerUpgrade          string `json:"answerupgrade"`
 	GitBin                 string `json:"gitbin"`
+	HgBin                  string `json:"hgbin"`
+	SvnBin                 string `json:"svnbin"`
+	BzrBin                 string `json:"bzrbin"`
+	FossilBin              string `json:"fossilbin"`
 	GpgBin                 string `json:"gpgbin"`
 	GpgFlags               string `json:"gpgflags"`
 	MFlags                 string `json:"mflags"`
@@ -71,8 +75,42 @@ type Configuration struct {
 	UseRPC                 bool   `json:"rpc"`
 	DoubleConfirm          bool   `json:"doubleconfirm"` // confirm install before and after build
 
-	CompletionPath string `json:"-"`
-	VCSFilePath    string `json:"-"`
+	PGPKeyPins      map[string][]string `json:"pgpkeypins"`
+	PGPKeyLookup    []string            `json:"pgpkeylookup"`
+	PGPKeyservers   []string            `json:"pgpkeyservers"`
+	PGPKeyAddresses map[string]string   `json:"pgpkeyaddresses"`
+
+	ChecksumPolicy      []string `json:"checksumpolicy"`
+	ChecksumPolicyAllow []string `json:"checksumpolicyallow"`
+	Reviewer            string   `json:"reviewer"`
+	AdvisoryFeed        string   `json:"advisoryfeed"`
+	AURRequestFeed      string   `json:"aurrequestfeed"`
+
+	DevelTags               bool              `json:"develtags"`
+	DevelTagPattern         string            `json:"develtagpattern"`
+	DevelTagPackages        map[string]string `json:"develtagpackages"`
+	DevelCommits            int               `json:"develcommits"`
+	AURCommits              int               `json:"aurcommits"`
+	DevelCheckTTL           string            `json:"develcheckttl"`
+	AURUpgradeDelay         string            `json:"aurupgradedelay"`
+	AURUpgradeDelayPackages map[string]string `json:"aurupgradedelaypackages"`
+	UnattendedTimer         string            `json:"unattendedtimer"`
+
+	RankWeights RankWeights `json:"rankweights"`
+
+	CompletionPath   string `json:"-"`
+	VCSFilePath      string `json:"-"`
+	ReviewLedgerPath string `json:"-"`
+	ReviewNote       string `json:"-"`
+	NoUpgradeDelay   bool   `json:"-"`
+	Unattended       bool   `json:"-"`
+	UnattendedPath   string `json:"-"`
+	ReportPath       string `json:"-"`
+	RebuildQueuePath string `json:"-"`
+	HistoryPath      string `json:"-"`
+	ExplainRank      bool   `json:"-"`
+	Offline          bool   `json:"-"`
+	Migrate          bool   `json:"-"`
 	// ConfigPath     string `json:"-"`
 	SaveConfig bool               `json:"-"`
 	Mode       parser.TargetMode  `json:"-"`
@@ -126,6 +164,10 @@ func (c *Configuration) expandEnv() {
 	c.SortBy = os.ExpandEnv(c.SortBy)
 	c.SearchBy = os.ExpandEnv(c.SearchBy)
 	c.GitBin = expandEnvOrHome(c.GitBin)
+	c.HgBin = expandEnvOrHome(c.HgBin)
+	c.SvnBin = expandEnvOrHome(c.SvnBin)
+	c.BzrBin = expandEnvOrHome(c.BzrBin)
+	c.FossilBin = expandEnvOrHome(c.FossilBin)
 	c.GpgBin = expandEnvOrHome(c.GpgBin)
 	c.SudoBin = expandEnvOrHome(c.SudoBin)
 	c.SudoFlags = os.ExpandEnv(c.SudoFlags)
@@ -136,6 +178,8 @@ func (c *Configuration) expandEnv() {
 	c.AnswerEdit = os.ExpandEnv(c.AnswerEdit)
 	c.AnswerUpgrade = os.ExpandEnv(c.AnswerUpgrade)
 	c.RemoveMake = os.ExpandEnv(c.RemoveMake)
+	c.AdvisoryFeed = expandEnvOrHome(c.AdvisoryFeed)
+	c.AURRequestFeed = expandEnvOrHome(c.AURRequestFeed)
 }
 
 func expandEnvOrHome(path string) string {
@@ -202,6 +246,12 @@ func DefaultConfig(version string) *Configuration {
 		MakepkgConf:            "",
 		PacmanBin:              "pacman",
 		PGPFetch:               true,
+		DevelTagPattern:        "*",
+		DevelCommits:           5,
+		AURCommits:             0,
+		UnattendedTimer:        "daily",
+		RankWeights:            DefaultRankWeights(),
+		PGPKeyLookup:           []string{"wkd", "keyserver", "local"},
 		PacmanConf:             "/etc/pacman.conf",
 		GpgFlags:               "",
 		MFlags:                 "",
@@ -213,6 +263,10 @@ func DefaultConfig(version string) *Configuration {
 		SearchBy:               "name-desc",
 		SudoLoop:               false,
 		GitBin:                 "git",
+		HgBin:                  "hg",
+		SvnBin:                 "svn",
+		BzrBin:                 "bzr",
+		FossilBin:              "fossil",
 		GpgBin:                 "gpg",
 		SudoBin:                "sudo",
 		SudoFlags:              "",
@@ -252,6 +306,17 @@ func NewConfig(logger *text.Logger, configPath, version string) (*Configuration,
 	newConfig.BuildDir = cacheHome
 	newConfig.CompletionPath = filepath.Join(cacheHome, completionFileName)
 	newConfig.VCSFilePath = filepath.Join(cacheHome, vcsFileName)
+
+	stateHome, errState := getStateHome()
+	if errState != nil && logger != nil {
+		logger.Errorln(errState)
+	}
+
+	newConfig.ReviewLedgerPath = filepath.Join(stateHome, reviewFileName)
+	newConfig.UnattendedPath = filepath.Join(stateHome, unattendedFileName)
+	newConfig.ReportPath = filepath.Join(stateHome, reportFileName)
+	newConfig.RebuildQueuePath = filepath.Join(stateHome, rebuildQueueFileName)
+	newConfig.HistoryPath = filepath.Join(stateHome, historyFileName)
 	newConfig.load(configPath)
 
 	if aurdest := os.Getenv("AURDEST"); aurdest != "" {
@@ -6,10 +6,16 @@ import (
 )
 
 const (
-	configFileName     string = "config.json" // configFileName holds the name of the config file.
-	vcsFileName        string = "vcs.json"    // vcsFileName holds the name of the vcs file.
-	completionFileName string = "completion.cache"
-	systemdCache       string = "/var/cache/yay" // systemd should handle cache creation
+	configFileName       string = "config.json" // configFileName holds the name of the config file.
+	vcsFileName          string = "vcs.json"    // vcsFileName holds the name of the vcs file.
+	completionFileName   string = "completion.cache"
+	reviewFileName       string = "review.json" // reviewFileName holds the name of the review ledger.
+	unattendedFileName   string = "unattended.json"
+	reportFileName       string = "unattended-report.json"
+	rebuildQueueFileName string = "rebuild-queue.json"
+	historyFileName      string = "history.jsonl"
+	systemdCache         string = "/var/cache/yay" // systemd should handle cache creation
+	rootState            string = "/var/lib/yay"
 )
 
 func GetConfigPath() string {
@@ -56,6 +62,33 @@ func getCacheHome() (string, error) {
 	return tmpDir, initDir(tmpDir)
 }
 
+// getStateHome returns the directory used for data that must survive cache cleaning.
+func getStateHome() (string, error) {
+	uid := os.Geteuid()
+
+	if stateHome := os.Getenv("XDG_STATE_HOME"); stateHome != "" && uid != 0 {
+		stateDir := filepath.Join(stateHome, "yay")
+		if err := initDir(stateDir); err == nil {
+			return stateDir, nil
+		}
+	}
+
+	if stateHome := os.Getenv("HOME"); stateHome != "" && uid != 0 {
+		stateDir := filepath.Join(stateHome, ".local", "state", "yay")
+		if err := initDir(stateDir); err == nil {
+			return stateDir, nil
+		}
+	}
+
+	if uid == 0 {
+		return rootState, initDir(rootState)
+	}
+
+	tmpDir := filepath.Join(os.TempDir(), "yay")
+
+	return tmpDir, initDir(tmpDir)
+}
+
 func initDir(dir string) error {
 	if _, err := os.Stat(dir); os.IsNotExist(err) {
 		if err = os.MkdirAll(dir, 0o755); err != nil {
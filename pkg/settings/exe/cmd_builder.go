@@ -32,6 +32,14 @@ type GitCmdBuilder interface {
 	BuildGitCmd(ctx context.Context, dir string, extraArgs ...string) *exec.Cmd
 }
 
+type VCSCmdBuilder interface {
+	GitCmdBuilder
+	BuildHgCmd(ctx context.Context, dir string, extraArgs ...string) *exec.Cmd
+	BuildSvnCmd(ctx context.Context, dir string, extraArgs ...string) *exec.Cmd
+	BuildBzrCmd(ctx context.Context, dir string, extraArgs ...string) *exec.Cmd
+	BuildFossilCmd(ctx context.Context, dir string, extraArgs ...string) *exec.Cmd
+}
+
 type ICmdBuilder interface {
 	Runner
 	BuildGitCmd(ctx context.Context, dir string, extraArgs ...string) *exec.Cmd
@@ -46,6 +54,10 @@ type ICmdBuilder interface {
 type CmdBuilder struct {
 	GitBin           string
 	GitFlags         []string
+	HgBin            string
+	SvnBin           string
+	BzrBin           string
+	FossilBin        string
 	GPGBin           string
 	GPGFlags         []string
 	MakepkgFlags     []string
@@ -66,6 +78,10 @@ func NewCmdBuilder(cfg *settings.Configuration, runner Runner, logger *text.Logg
 	return &CmdBuilder{
 		GitBin:           cfg.GitBin,
 		GitFlags:         strings.Fields(cfg.GitFlags),
+		HgBin:            cfg.HgBin,
+		SvnBin:           cfg.SvnBin,
+		BzrBin:           cfg.BzrBin,
+		FossilBin:        cfg.FossilBin,
 		GPGBin:           cfg.GpgBin,
 		GPGFlags:         strings.Fields(cfg.GpgFlags),
 		MakepkgFlags:     strings.Fields(cfg.MFlags),
@@ -134,6 +150,43 @@ func (c *CmdBuilder) BuildGitCmd(ctx context.Context, dir string, extraArgs ...s
 	return cmd
 }
 
+func (c *CmdBuilder) BuildHgCmd(ctx context.Context, dir string, extraArgs ...string) *exec.Cmd {
+	args := make([]string, 0, len(extraArgs)+2)
+
+	if dir != "" {
+		args = append(args, "--cwd", dir)
+	}
+
+	args = append(args, extraArgs...)
+
+	return c.deElevateCommand(ctx, exec.CommandContext(ctx, c.HgBin, args...))
+}
+
+func (c *CmdBuilder) BuildSvnCmd(ctx context.Context, dir string, extraArgs ...string) *exec.Cmd {
+	args := make([]string, 0, len(extraArgs)+1)
+	args = append(args, "--non-interactive")
+	args = append(args, extraArgs...)
+
+	cmd := exec.CommandContext(ctx, c.SvnBin, args...)
+	cmd.Dir = dir
+
+	return c.deElevateCommand(ctx, cmd)
+}
+
+func (c *CmdBuilder) BuildBzrCmd(ctx context.Context, dir string, extraArgs ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, c.BzrBin, extraArgs...)
+	cmd.Dir = dir
+
+	return c.deElevateCommand(ctx, cmd)
+}
+
+func (c *CmdBuilder) BuildFossilCmd(ctx context.Context, dir string, extraArgs ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, c.FossilBin, extraArgs...)
+	cmd.Dir = dir
+
+	return c.deElevateCommand(ctx, cmd)
+}
+
 func (c *CmdBuilder) AddMakepkgFlag(flag string) {
 	c.MakepkgFlags = append(c.MakepkgFlags, flag)
 }
@@ -67,7 +67,38 @@ func (m *MockBuilder) AddMakepkgFlag(flag string) {
 }
 
 func (m *MockBuilder) BuildGitCmd(ctx context.Context, dir string, extraArgs ...string) *exec.Cmd {
-	return exec.CommandContext(ctx, "git", extraArgs...)
+	cmd := exec.CommandContext(ctx, "git", extraArgs...)
+	cmd.Dir = dir
+
+	return cmd
+}
+
+func (m *MockBuilder) BuildHgCmd(ctx context.Context, dir string, extraArgs ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "hg", extraArgs...)
+	cmd.Dir = dir
+
+	return cmd
+}
+
+func (m *MockBuilder) BuildSvnCmd(ctx context.Context, dir string, extraArgs ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "svn", extraArgs...)
+	cmd.Dir = dir
+
+	return cmd
+}
+
+func (m *MockBuilder) BuildBzrCmd(ctx context.Context, dir string, extraArgs ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "bzr", extraArgs...)
+	cmd.Dir = dir
+
+	return cmd
+}
+
+func (m *MockBuilder) BuildFossilCmd(ctx context.Context, dir string, extraArgs ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "fossil", extraArgs...)
+	cmd.Dir = dir
+
+	return cmd
 }
 
 func (m *MockBuilder) BuildPacmanCmd(ctx context.Context, args *parser.Arguments, mode parser.TargetMode, noConfirm bool) *exec.Cmd {
@@ -380,12 +380,17 @@ func isArg(arg string) bool {
 	case "afterclean", "cleanafter":
 	case "keepsrc":
 	case "devel":
+	case "develtags":
+	case "noupgradedelay":
 	case "timeupdate":
 	case "topdown":
 	case "bottomup":
 	case "completioninterval":
 	case "sortby":
 	case "searchby":
+	case "explain-rank":
+	case "offline":
+	case "migrate":
 	case "redownload":
 	case "redownloadall":
 	case "noredownload":
@@ -413,6 +418,10 @@ func isArg(arg string) bool {
 	case "nomakepkgconf":
 	case "pacman":
 	case "git":
+	case "hg":
+	case "svn":
+	case "bzr":
+	case "fossil":
 	case "gpg":
 	case "sudo":
 	case "sudoflags":
@@ -434,7 +443,29 @@ func isArg(arg string) bool {
 	case "complete":
 	case "stats":
 	case "news":
+	case "advisories":
+	case "sbom":
+	case "format":
+	case "history":
+	case "package":
+	case "since":
+	case "required-by":
+	case "tree":
+	case "depth":
+	case "reverse":
+	case "optional":
+	case "nodedup":
 	case "gendb":
+	case "local":
+	case "count":
+	case "exportreviews":
+	case "importreviews":
+	case "reviewnote":
+	case "unattended":
+	case "report":
+	case "install-timer":
+	case "rebuild-check":
+	case "rebuild-queue":
 	case "currentconfig":
 	case "defaultconfig":
 	case "singlelineresults":
@@ -523,6 +554,10 @@ func hasParam(arg string) bool {
 	case "makepkgconf":
 	case "pacman":
 	case "git":
+	case "hg":
+	case "svn":
+	case "bzr":
+	case "fossil":
 	case "gpg":
 	case "sudo":
 	case "sudoflags":
@@ -534,6 +569,12 @@ func hasParam(arg string) bool {
 	case "completioninterval":
 	case "sortby":
 	case "searchby":
+	case "reviewnote":
+	case "report":
+	case "format":
+	case "package":
+	case "since":
+	case "depth":
 	default:
 		return false
 	}
@@ -0,0 +1,29 @@
+package settings
+
+// RankWeights weigh the parts of the score search results are sorted by.
+// Repo packages always get the full votes, popularity and recency weights.
+type RankWeights struct {
+	Name        float64 `json:"name"`
+	Description float64 `json:"description"`
+	Votes       float64 `json:"votes"`
+	Popularity  float64 `json:"popularity"`
+	Recency     float64 `json:"recency"`
+	// Provides is added to packages providing exactly the search.
+	Provides float64 `json:"provides"`
+	// OutOfDate and Orphaned are subtracted from flagged AUR packages.
+	OutOfDate float64 `json:"outofdate"`
+	Orphaned  float64 `json:"orphaned"`
+}
+
+func DefaultRankWeights() RankWeights {
+	return RankWeights{
+		Name:        0.5,
+		Description: 0.2,
+		Votes:       0.15,
+		Popularity:  0.15,
+		Recency:     0.1,
+		Provides:    0.2,
+		OutOfDate:   0.2,
+		Orphaned:    0.1,
+	}
+}
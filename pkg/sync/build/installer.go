@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/Jguer/yay/v12/pkg/db"
 	"github.com/Jguer/yay/v12/pkg/dep"
@@ -24,11 +25,13 @@ type (
 		dbExecutor       db.Executor
 		postInstallHooks []PostInstallHookFunc
 		failedAndIgnored map[string]error
+		buildTimes       map[string]time.Duration
 		exeCmd           exe.ICmdBuilder
 		vcsStore         vcs.Store
 		targetMode       parser.TargetMode
 		rebuildMode      parser.RebuildMode
 		origTargets      mapset.Set[string]
+		rebuildBases     mapset.Set[string]
 		downloadOnly     bool
 		log              *text.Logger
 
@@ -44,10 +47,12 @@ func NewInstaller(dbExecutor db.Executor,
 		dbExecutor:            dbExecutor,
 		postInstallHooks:      []PostInstallHookFunc{},
 		failedAndIgnored:      map[string]error{},
+		buildTimes:            map[string]time.Duration{},
 		exeCmd:                exeCmd,
 		vcsStore:              vcsStore,
 		targetMode:            targetMode,
 		rebuildMode:           rebuildMode,
+		rebuildBases:          mapset.NewThreadUnsafeSet[string](),
 		downloadOnly:          downloadOnly,
 		log:                   logger,
 		manualConfirmRequired: true,
@@ -64,6 +69,18 @@ func (installer *Installer) CompileFailedAndIgnored() (map[string]error, error)
 	}
 }
 
+// AddRebuildBases marks package bases that are built again even when a
+// package of the same version was already made, whatever the rebuild mode.
+func (installer *Installer) AddRebuildBases(bases ...string) {
+	installer.rebuildBases.Append(bases...)
+}
+
+// BuildTime returns how long makepkg took to build a package base, or zero
+// if it was not built or its build failed.
+func (installer *Installer) BuildTime(base string) time.Duration {
+	return installer.buildTimes[base]
+}
+
 func (installer *Installer) AddPostInstallHook(hook PostInstallHookFunc) {
 	if hook == nil {
 		return
@@ -146,6 +163,7 @@ func (installer *Installer) handleLayer(ctx context.Context,
 ) error {
 	// Install layer
 	nameToBaseMap := make(map[string]string, 0)
+	replaced := make(map[string]string, 0)
 	syncDeps, syncExp, syncGroups := mapset.NewThreadUnsafeSet[string](),
 		mapset.NewThreadUnsafeSet[string](), mapset.NewThreadUnsafeSet[string]()
 	aurDeps, aurExp := mapset.NewThreadUnsafeSet[string](), mapset.NewThreadUnsafeSet[string]()
@@ -155,6 +173,9 @@ func (installer *Installer) handleLayer(ctx context.Context,
 		switch info.Source {
 		case dep.AUR, dep.SrcInfo:
 			nameToBaseMap[name] = *info.AURBase
+			if info.Replaces != "" {
+				replaced[name] = info.Replaces
+			}
 
 			switch info.Reason {
 			case dep.Explicit:
@@ -201,7 +222,7 @@ func (installer *Installer) handleLayer(ctx context.Context,
 	}
 
 	errAur := installer.installAURPackages(ctx, cmdArgs, aurDeps, aurExp,
-		nameToBaseMap, pkgBuildDirs, true, lastLayer, installer.appendNoConfirm())
+		nameToBaseMap, replaced, pkgBuildDirs, true, lastLayer, installer.appendNoConfirm())
 
 	return errAur
 }
@@ -209,7 +230,7 @@ func (installer *Installer) handleLayer(ctx context.Context,
 func (installer *Installer) installAURPackages(ctx context.Context,
 	cmdArgs *parser.Arguments,
 	aurDepNames, aurExpNames mapset.Set[string],
-	nameToBase, pkgBuildDirsByBase map[string]string,
+	nameToBase, replaced, pkgBuildDirsByBase map[string]string,
 	installIncompatible bool,
 	lastLayer bool,
 	noConfirm bool,
@@ -221,6 +242,7 @@ func (installer *Installer) installAURPackages(ctx context.Context,
 
 	deps, exps := make([]string, 0, aurDepNames.Cardinality()), make([]string, 0, aurExpNames.Cardinality())
 	pkgArchives := make([]string, 0, len(exps)+len(deps))
+	removals := make([]string, 0, len(replaced))
 
 	for _, name := range all {
 		base := nameToBase[name]
@@ -259,6 +281,10 @@ func (installer *Installer) installAURPackages(ctx context.Context,
 		if hasDebug {
 			deps = append(deps, name+"-debug")
 		}
+
+		if foreign, ok := replaced[name]; ok {
+			removals = append(removals, foreign)
+		}
 	}
 
 	if err := installPkgArchive(ctx, installer.exeCmd, installer.targetMode,
@@ -270,6 +296,11 @@ func (installer *Installer) installAURPackages(ctx context.Context,
 		return fmt.Errorf("%s - %w", fmt.Sprintf(gotext.Get("error installing:")+" %v", pkgArchives), err)
 	}
 
+	// the successors are installed, a package left behind is not an error
+	if err := removeReplaced(ctx, installer.exeCmd, installer.targetMode, cmdArgs, removals, noConfirm); err != nil {
+		installer.log.Warnln(gotext.Get("unable to remove the replaced packages %v:", removals), err)
+	}
+
 	return nil
 }
 
@@ -277,6 +308,7 @@ func (installer *Installer) buildPkg(ctx context.Context,
 	dir, base string,
 	installIncompatible, needed, isTarget bool,
 ) (map[string]string, error) {
+	started := time.Now()
 	args := []string{"--nobuild", "-f"}
 
 	if !installer.exeCmd.GetKeepSrc() {
@@ -298,15 +330,18 @@ func (installer *Installer) buildPkg(ctx context.Context,
 		return nil, errList
 	}
 
+	built := false
+
 	switch {
 	case needed && installer.pkgsAreAlreadyInstalled(pkgdests, pkgVersion) || installer.downloadOnly:
 		args = []string{"--nobuild", "--noextract", "--ignorearch"}
 		pkgdests = map[string]string{}
 		installer.log.Warnln(gotext.Get("%s is up to date -- skipping", text.Cyan(base+"-"+pkgVersion)))
-	case installer.skipAlreadyBuiltPkg(isTarget, pkgdests):
+	case installer.skipAlreadyBuiltPkg(base, isTarget, pkgdests):
 		args = []string{"--nobuild", "--noextract", "--ignorearch"}
 		installer.log.Warnln(gotext.Get("%s already made -- skipping build", text.Cyan(base+"-"+pkgVersion)))
 	default:
+		built = true
 		args = []string{"-f", "--noconfirm", "--noextract", "--noprepare", "--holdver"}
 		if installIncompatible {
 			args = append(args, "--ignorearch")
@@ -324,6 +359,11 @@ func (installer *Installer) buildPkg(ctx context.Context,
 		return nil, errMake
 	}
 
+	// skipped builds would record a near zero build time
+	if built {
+		installer.buildTimes[base] = time.Since(started)
+	}
+
 	if installer.downloadOnly {
 		return map[string]string{}, nil
 	}
@@ -352,8 +392,13 @@ func pkgsAreBuilt(logger *text.Logger, pkgdests map[string]string) bool {
 	return true
 }
 
-func (installer *Installer) skipAlreadyBuiltPkg(isTarget bool, pkgdests map[string]string) bool {
-	switch installer.rebuildMode {
+func (installer *Installer) skipAlreadyBuiltPkg(base string, isTarget bool, pkgdests map[string]string) bool {
+	rebuildMode := installer.rebuildMode
+	if installer.rebuildBases.Contains(base) {
+		rebuildMode = parser.RebuildModeYes
+	}
+
+	switch rebuildMode {
 	case parser.RebuildModeNo:
 		return pkgsAreBuilt(installer.log, pkgdests)
 	case parser.RebuildModeYes:
@@ -46,6 +46,7 @@ func TestInstaller_InstallNeeded(t *testing.T) {
 		desc        string
 		isInstalled bool
 		isBuilt     bool
+		wantBuilt   bool
 		wantShow    []string
 		wantCapture []string
 	}
@@ -55,6 +56,7 @@ func TestInstaller_InstallNeeded(t *testing.T) {
 			desc:        "not installed and not built",
 			isInstalled: false,
 			isBuilt:     false,
+			wantBuilt:   true,
 			wantShow: []string{
 				"makepkg --nobuild -f -C --ignorearch",
 				"makepkg -f -c --noconfirm --noextract --noprepare --holdver --ignorearch",
@@ -159,6 +161,9 @@ func TestInstaller_InstallNeeded(t *testing.T) {
 			errI := installer.Install(context.Background(), cmdArgs, targets, pkgBuildDirs, []string{}, false)
 			require.NoError(td, errI)
 
+			// skipped builds must not be recorded as fast builds
+			assert.Equal(td, tc.wantBuilt, installer.BuildTime("yay") > 0)
+
 			require.Len(td, mockRunner.ShowCalls, len(tc.wantShow))
 			require.Len(td, mockRunner.CaptureCalls, len(tc.wantCapture))
 
@@ -614,6 +619,7 @@ func TestInstaller_CompileFailed(t *testing.T) {
 				require.Error(td, err)
 				assert.ErrorContains(td, err, "yay")
 				assert.Len(t, failed, len(tc.targets))
+				assert.Zero(td, installer.BuildTime("yay"))
 			} else {
 				require.NoError(td, err)
 			}
@@ -1059,6 +1065,7 @@ func TestInstaller_InstallRebuild(t *testing.T) {
 	type testCase struct {
 		desc          string
 		rebuildOption parser.RebuildMode
+		rebuildBases  []string
 		isInstalled   bool
 		isBuilt       bool
 		wantShow      []string
@@ -1093,6 +1100,31 @@ func TestInstaller_InstallRebuild(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc:          "--norebuild when built and base queued for rebuild",
+			rebuildOption: parser.RebuildModeNo,
+			rebuildBases:  []string{"yay"},
+			isBuilt:       true,
+			isInstalled:   false,
+			wantShow: []string{
+				"makepkg --nobuild -f -C --ignorearch",
+				"makepkg -f -c --noconfirm --noextract --noprepare --holdver --ignorearch",
+				"pacman -U --config  -- /testdir/yay-91.0.0-1-x86_64.pkg.tar.zst",
+				"pacman -D -q --asexplicit --config  -- yay",
+			},
+			wantCapture: []string{"makepkg --packagelist"},
+			targets: []map[string]*dep.InstallInfo{
+				{
+					"yay": {
+						Source:      dep.AUR,
+						Reason:      dep.Explicit,
+						Version:     "91.0.0-1",
+						SrcinfoPath: ptrString(tmpDir + "/.SRCINFO"),
+						AURBase:     ptrString("yay"),
+					},
+				},
+			},
+		},
 		{
 			desc:          "--rebuild when built and not installed",
 			rebuildOption: parser.RebuildModeYes,
@@ -1215,6 +1247,7 @@ func TestInstaller_InstallRebuild(t *testing.T) {
 
 			installer := NewInstaller(mockDB, cmdBuilder, &vcs.Mock{}, parser.ModeAny,
 				tc.rebuildOption, false, newTestLogger())
+			installer.AddRebuildBases(tc.rebuildBases...)
 
 			cmdArgs := parser.MakeArguments()
 			cmdArgs.AddTarget("yay")
@@ -1441,3 +1474,92 @@ func TestInstaller_KeepSrc(t *testing.T) {
 		})
 	}
 }
+
+func TestInstaller_InstallReplacing(t *testing.T) {
+	t.Parallel()
+
+	makepkgBin := t.TempDir() + "/makepkg"
+	pacmanBin := t.TempDir() + "/pacman"
+	f, err := os.OpenFile(makepkgBin, os.O_RDONLY|os.O_CREATE, 0o755)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	f, err = os.OpenFile(pacmanBin, os.O_RDONLY|os.O_CREATE, 0o755)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	tmpDir := t.TempDir()
+	pkgTar := tmpDir + "/python-foo-ng-3-1-any.pkg.tar.zst"
+
+	captureOverride := func(cmd *exec.Cmd) (stdout string, stderr string, err error) {
+		return pkgTar, "", nil
+	}
+
+	i := 0
+	showOverride := func(cmd *exec.Cmd) error {
+		i++
+		if i == 2 {
+			f, err := os.OpenFile(pkgTar, os.O_RDONLY|os.O_CREATE, 0o666)
+			require.NoError(t, err)
+			require.NoError(t, f.Close())
+		}
+
+		// the successor is installed even if the replaced package stays
+		if cmd.Args[1] == "-R" {
+			return errors.New("target not found")
+		}
+
+		return nil
+	}
+
+	mockDB := &mock.DBExecutor{IsCorrectVersionInstalledFn: func(string, string) bool { return false }}
+	mockRunner := &exe.MockRunner{CaptureFn: captureOverride, ShowFn: showOverride}
+	cmdBuilder := &exe.CmdBuilder{
+		MakepkgBin:      makepkgBin,
+		SudoBin:         "su",
+		PacmanBin:       pacmanBin,
+		Runner:          mockRunner,
+		SudoLoopEnabled: false,
+	}
+
+	installer := NewInstaller(mockDB, cmdBuilder, &vcs.Mock{}, parser.ModeAny,
+		parser.RebuildModeNo, false, newTestLogger())
+
+	cmdArgs := parser.MakeArguments()
+
+	targets := []map[string]*dep.InstallInfo{
+		{
+			"python-foo-ng": {
+				Source:       dep.AUR,
+				Reason:       dep.Dep,
+				Version:      "3-1",
+				LocalVersion: "2-1",
+				AURBase:      ptrString("python-foo-ng"),
+				Replaces:     "python-foo",
+			},
+		},
+	}
+
+	errI := installer.Install(context.Background(), cmdArgs, targets,
+		map[string]string{"python-foo-ng": tmpDir}, []string{}, false)
+	require.NoError(t, errI)
+
+	wantShow := []string{
+		"makepkg --nobuild -f -C --ignorearch",
+		"makepkg -f -c --noconfirm --noextract --noprepare --holdver --ignorearch",
+		"pacman -U --config  -- /testdir/python-foo-ng-3-1-any.pkg.tar.zst",
+		"pacman -D -q --asdeps --config  -- python-foo-ng",
+		"pacman -R -d -d --config  -- python-foo",
+	}
+
+	require.Len(t, mockRunner.ShowCalls, len(wantShow))
+
+	for i, call := range mockRunner.ShowCalls {
+		show := call.Args[0].(*exec.Cmd).String()
+		show = strings.ReplaceAll(show, tmpDir, "/testdir")
+		show = strings.ReplaceAll(show, makepkgBin, "makepkg")
+		show = strings.ReplaceAll(show, pacmanBin, "pacman")
+
+		assert.Subset(t, strings.Split(show, " "), strings.Split(wantShow[i], " "), show)
+	}
+}
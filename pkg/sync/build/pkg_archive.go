@@ -56,6 +56,27 @@ func installPkgArchive(ctx context.Context,
 	return nil
 }
 
+// removeReplaced removes the foreign packages replaced by the packages just
+// installed. These provide them, so dependency checks are skipped.
+func removeReplaced(ctx context.Context,
+	cmdBuilder exe.ICmdBuilder, mode parser.TargetMode,
+	cmdArgs *parser.Arguments, pkgs []string, noConfirm bool,
+) error {
+	if len(pkgs) == 0 {
+		return nil
+	}
+
+	arguments := cmdArgs.CopyGlobal()
+	arguments.Op = "R"
+	if err := arguments.AddArg("d", "d"); err != nil {
+		return err
+	}
+
+	arguments.AddTarget(pkgs...)
+
+	return cmdBuilder.Show(cmdBuilder.BuildPacmanCmd(ctx, arguments, mode, noConfirm))
+}
+
 func setInstallReason(ctx context.Context,
 	cmdBuilder exe.ICmdBuilder, mode parser.TargetMode,
 	cmdArgs *parser.Arguments, deps, exps []string,
@@ -0,0 +1,120 @@
+package sync
+
+import (
+	"os"
+	"sort"
+	"time"
+
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yay/v12/pkg/db"
+	"github.com/Jguer/yay/v12/pkg/dep"
+	"github.com/Jguer/yay/v12/pkg/history"
+	"github.com/Jguer/yay/v12/pkg/sync/build"
+	"github.com/Jguer/yay/v12/pkg/text"
+)
+
+// historySources are the stable names of the sources written to the history.
+var historySources = map[dep.Source]string{
+	dep.AUR:     "AUR",
+	dep.Sync:    "Sync",
+	dep.SrcInfo: "SrcInfo",
+}
+
+// newTransaction describes the targets of a transaction for the history.
+// Packages that failed to build, or that are not installed after a failed
+// transaction, are recorded as failed and the others as successful.
+func newTransaction(started time.Time, targets []map[string]*dep.InstallInfo,
+	pkgBuildDirs map[string]string, installer *build.Installer, dbExecutor db.Executor,
+	failedAndIgnored map[string]error, err error,
+) *history.Transaction {
+	transaction := &history.Transaction{
+		Time:     started,
+		Command:  os.Args,
+		Packages: []history.Package{},
+	}
+	transaction.Finish(err)
+
+	for _, layer := range targets {
+		for name, info := range layer {
+			source, ok := historySources[info.Source]
+			if !ok || info.IsGroup {
+				continue
+			}
+
+			pkg := history.Package{
+				Name:       name,
+				Source:     source,
+				OldVersion: info.LocalVersion,
+				NewVersion: info.Version,
+				Result:     history.ResultSuccess,
+			}
+
+			if info.AURBase != nil {
+				pkg.Commit = history.GitHead(pkgBuildDirs[*info.AURBase])
+				pkg.BuildSeconds = installer.BuildTime(*info.AURBase).Seconds()
+			}
+
+			if errPkg, failed := failedAndIgnored[name]; failed {
+				pkg.Result = history.ResultFailed
+				pkg.Error = errPkg.Error()
+			} else if err != nil && !isInstalled(dbExecutor, name, info) {
+				pkg.Result = history.ResultFailed
+				pkg.Error = transaction.Error
+			}
+
+			transaction.Packages = append(transaction.Packages, pkg)
+		}
+	}
+
+	sort.Slice(transaction.Packages, func(i, j int) bool {
+		return transaction.Packages[i].Name < transaction.Packages[j].Name
+	})
+
+	return transaction
+}
+
+// isInstalled reports whether a target was installed, at its new version
+// or at any version other than the one it had before the transaction.
+func isInstalled(dbExecutor db.Executor, name string, info *dep.InstallInfo) bool {
+	local := dbExecutor.LocalPackage(name)
+	if local == nil {
+		return false
+	}
+
+	return local.Version() == info.Version || local.Version() != info.LocalVersion
+}
+
+// estimateBuildTime sums the last build times of the AUR bases about to be
+// built, as recorded in the history.
+func (o *OperationService) estimateBuildTime(transactions []history.Transaction,
+	targets []map[string]*dep.InstallInfo,
+) {
+	var total time.Duration
+
+	known := 0
+	seen := make(map[string]bool)
+
+	for _, layer := range targets {
+		for name, info := range layer {
+			if info.AURBase == nil || seen[*info.AURBase] {
+				continue
+			}
+
+			seen[*info.AURBase] = true
+
+			if estimate, ok := history.EstimateBuild(transactions, name); ok {
+				total += estimate
+				known++
+			}
+		}
+	}
+
+	if known == 0 {
+		return
+	}
+
+	o.logger.OperationInfoln(gotext.GetN("Estimated build time: %s (from %d previous build)",
+		"Estimated build time: %s (from %d previous builds)", known,
+		text.Cyan(total.Round(time.Second).String()), known))
+}
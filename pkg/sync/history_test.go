@@ -0,0 +1,75 @@
+//go:build !integration
+// +build !integration
+
+package sync
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Jguer/yay/v12/pkg/db/mock"
+	"github.com/Jguer/yay/v12/pkg/dep"
+	"github.com/Jguer/yay/v12/pkg/history"
+	"github.com/Jguer/yay/v12/pkg/settings/parser"
+	"github.com/Jguer/yay/v12/pkg/sync/build"
+	"github.com/Jguer/yay/v12/pkg/text"
+	"github.com/Jguer/yay/v12/pkg/vcs"
+)
+
+func TestNewTransaction(t *testing.T) {
+	t.Parallel()
+
+	local := map[string]string{"foo": "2-1", "bar": "1-1"}
+	dbExecutor := &mock.DBExecutor{
+		LocalPackageFn: func(name string) mock.IPackage {
+			if version, ok := local[name]; ok {
+				return &mock.Package{PName: name, PVersion: version}
+			}
+
+			return nil
+		},
+	}
+
+	targets := []map[string]*dep.InstallInfo{
+		{
+			"foo": {Source: dep.Sync, LocalVersion: "1-1", Version: "2-1"},
+			"bar": {Source: dep.Sync, LocalVersion: "1-1", Version: "2-1"},
+			"baz": {Source: dep.Sync, Version: "1-1"},
+			"qux": {Source: dep.AUR, Version: "1-1"},
+		},
+	}
+
+	logger := text.NewLogger(io.Discard, io.Discard, strings.NewReader(""), false, "test")
+	installer := build.NewInstaller(dbExecutor, nil, &vcs.Mock{}, parser.ModeAny,
+		parser.RebuildModeNo, false, logger)
+	failed := map[string]error{"qux": errors.New("build failed")}
+
+	results := func(transaction *history.Transaction) map[string]history.Result {
+		got := map[string]history.Result{}
+		for _, pkg := range transaction.Packages {
+			got[pkg.Name] = pkg.Result
+		}
+
+		return got
+	}
+
+	transaction := newTransaction(time.Now(), targets, nil, installer, dbExecutor, failed, nil)
+	assert.Equal(t, history.ResultSuccess, transaction.Result)
+	assert.Equal(t, map[string]history.Result{
+		"bar": history.ResultSuccess, "baz": history.ResultSuccess,
+		"foo": history.ResultSuccess, "qux": history.ResultFailed,
+	}, results(transaction))
+
+	transaction = newTransaction(time.Now(), targets, nil, installer, dbExecutor, failed,
+		errors.New("pacman failed"))
+	assert.Equal(t, history.ResultFailed, transaction.Result)
+	assert.Equal(t, map[string]history.Result{
+		"bar": history.ResultFailed, "baz": history.ResultFailed,
+		"foo": history.ResultSuccess, "qux": history.ResultFailed,
+	}, results(transaction))
+}
@@ -0,0 +1,265 @@
+package srcinfo
+
+import (
+	"context"
+	"path"
+	"sort"
+	"strings"
+
+	gosrc "github.com/Morganamilo/go-srcinfo"
+	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yay/v12/pkg/text"
+)
+
+// Checksum policy rules understood by the checksumpolicy setting.
+const (
+	// ChecksumNoSkip rejects SKIP checksums for sources that are not VCS checkouts.
+	ChecksumNoSkip = "noskip"
+	// ChecksumStrong rejects PKGBUILDs that only provide md5sums and/or sha1sums.
+	ChecksumStrong = "strong"
+	// ChecksumSigned requires validpgpkeys for PKGBUILDs that ship signature files.
+	ChecksumSigned = "signed"
+)
+
+var (
+	checksumRules = mapset.NewThreadUnsafeSet(ChecksumNoSkip, ChecksumStrong, ChecksumSigned)
+	vcsSchemes    = mapset.NewThreadUnsafeSet("git", "hg", "svn", "bzr", "fossil")
+	signatureExts = mapset.NewThreadUnsafeSet(".sig", ".asc", ".sign")
+	weakChecksums = mapset.NewThreadUnsafeSet("md5sums", "sha1sums")
+)
+
+// ChecksumViolation describes a source of a package base breaking a checksum rule.
+type ChecksumViolation struct {
+	Base   string
+	Rule   string
+	Source string
+}
+
+func (v ChecksumViolation) String() string {
+	switch v.Rule {
+	case ChecksumNoSkip:
+		return gotext.Get("%s uses a SKIP checksum", v.Source)
+	case ChecksumStrong:
+		return gotext.Get("only weak checksums (%s) are provided", v.Source)
+	case ChecksumSigned:
+		return gotext.Get("%s is a signature but validpgpkeys is empty", v.Source)
+	}
+
+	return v.Rule
+}
+
+// ValidateChecksumPolicy returns an error for the first rule of rules that is
+// not a checksum policy rule.
+func ValidateChecksumPolicy(rules []string) error {
+	for _, rule := range rules {
+		if !checksumRules.Contains(strings.ToLower(rule)) {
+			return &ErrUnknownChecksumRule{rule: rule}
+		}
+	}
+
+	return nil
+}
+
+// CheckChecksumPolicy verifies every PKGBUILD against the configured checksum
+// policy and reports violations per package base.
+func (s *Service) CheckChecksumPolicy(ctx context.Context) error {
+	if len(s.cfg.ChecksumPolicy) == 0 {
+		return nil
+	}
+
+	if err := ValidateChecksumPolicy(s.cfg.ChecksumPolicy); err != nil {
+		return err
+	}
+
+	violations := ChecksumViolations(s.srcInfos, s.cfg.ChecksumPolicy, s.cfg.ChecksumPolicyAllow)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	s.log.Errorln(gotext.Get("The following packages violate the checksum policy:"))
+
+	for _, v := range violations {
+		s.log.Println("  " + text.Cyan(v.Base) + ": " + v.String())
+	}
+
+	return &ErrChecksumPolicy{violations: len(violations)}
+}
+
+// ChecksumViolations returns the violations of rules found in srcinfos,
+// skipping the package bases in allowed. Results are sorted by base.
+func ChecksumViolations(srcinfos map[string]*gosrc.Srcinfo, rules, allowed []string) []ChecksumViolation {
+	violations := []ChecksumViolation{}
+	allowedBases := mapset.NewThreadUnsafeSet(allowed...)
+
+	for base, srcinfo := range srcinfos {
+		if allowedBases.Contains(base) {
+			continue
+		}
+
+		for _, rule := range rules {
+			switch strings.ToLower(rule) {
+			case ChecksumNoSkip:
+				violations = append(violations, skippedSources(base, srcinfo)...)
+			case ChecksumStrong:
+				violations = append(violations, weakOnly(base, srcinfo)...)
+			case ChecksumSigned:
+				violations = append(violations, unverifiedSignatures(base, srcinfo)...)
+			}
+		}
+	}
+
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].Base < violations[j].Base
+	})
+
+	return violations
+}
+
+func checksumsByName(srcinfo *gosrc.Srcinfo) map[string][]gosrc.ArchString {
+	return map[string][]gosrc.ArchString{
+		"md5sums":    srcinfo.MD5Sums,
+		"sha1sums":   srcinfo.SHA1Sums,
+		"sha224sums": srcinfo.SHA224Sums,
+		"sha256sums": srcinfo.SHA256Sums,
+		"sha384sums": srcinfo.SHA384Sums,
+		"sha512sums": srcinfo.SHA512Sums,
+		"b2sums":     srcinfo.B2Sums,
+	}
+}
+
+// filterArch returns the values of the entries set for arch, in order.
+func filterArch(values []gosrc.ArchString, arch string) []string {
+	filtered := make([]string, 0, len(values))
+
+	for _, v := range values {
+		if v.Arch == arch {
+			filtered = append(filtered, v.Value)
+		}
+	}
+
+	return filtered
+}
+
+func skippedSources(base string, srcinfo *gosrc.Srcinfo) []ChecksumViolation {
+	violations := []ChecksumViolation{}
+	checksums := checksumsByName(srcinfo)
+	seenArch := map[string]bool{}
+
+	for _, source := range srcinfo.Source {
+		if seenArch[source.Arch] {
+			continue
+		}
+
+		seenArch[source.Arch] = true
+		sources := filterArch(srcinfo.Source, source.Arch)
+
+	nextSource:
+		for i, value := range sources {
+			if isVCSSource(value) || (isSignature(value) && len(srcinfo.ValidPGPKeys) > 0) {
+				continue
+			}
+
+			for _, sums := range checksums {
+				archSums := filterArch(sums, source.Arch)
+				if i < len(archSums) && archSums[i] != "SKIP" {
+					continue nextSource
+				}
+			}
+
+			violations = append(violations, ChecksumViolation{Base: base, Rule: ChecksumNoSkip, Source: value})
+		}
+	}
+
+	return violations
+}
+
+// weakOnly reports, for each architecture with sources, when every checksum
+// array covering that architecture is weak. Sources of an architecture are
+// checked by the arrays of the same architecture only, as makepkg does.
+func weakOnly(base string, srcinfo *gosrc.Srcinfo) []ChecksumViolation {
+	violations := []ChecksumViolation{}
+	checksums := checksumsByName(srcinfo)
+	seenArch := map[string]bool{}
+
+nextArch:
+	for _, source := range srcinfo.Source {
+		if seenArch[source.Arch] {
+			continue
+		}
+
+		seenArch[source.Arch] = true
+		present := []string{}
+
+		for name, sums := range checksums {
+			if len(filterArch(sums, source.Arch)) == 0 {
+				continue
+			}
+
+			if !weakChecksums.Contains(name) {
+				continue nextArch
+			}
+
+			if source.Arch != "" {
+				name += "_" + source.Arch
+			}
+
+			present = append(present, name)
+		}
+
+		if len(present) == 0 {
+			continue
+		}
+
+		sort.Strings(present)
+
+		violations = append(violations,
+			ChecksumViolation{Base: base, Rule: ChecksumStrong, Source: strings.Join(present, ", ")})
+	}
+
+	return violations
+}
+
+func unverifiedSignatures(base string, srcinfo *gosrc.Srcinfo) []ChecksumViolation {
+	if len(srcinfo.ValidPGPKeys) > 0 {
+		return nil
+	}
+
+	violations := []ChecksumViolation{}
+
+	for _, source := range srcinfo.Source {
+		if isSignature(source.Value) {
+			violations = append(violations, ChecksumViolation{Base: base, Rule: ChecksumSigned, Source: source.Value})
+		}
+	}
+
+	return violations
+}
+
+// isVCSSource reports whether a source entry is a VCS checkout such as
+// git+https://... or svn://..., which cannot have a fixed checksum.
+func isVCSSource(source string) bool {
+	split := strings.Split(source, "::")
+	source = split[len(split)-1]
+
+	scheme, _, found := strings.Cut(source, "://")
+	if !found {
+		return false
+	}
+
+	for _, protocol := range strings.Split(scheme, "+") {
+		if vcsSchemes.Contains(protocol) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isSignature(source string) bool {
+	split := strings.Split(source, "::")
+	source = split[len(split)-1]
+
+	return signatureExts.Contains(path.Ext(source))
+}
@@ -0,0 +1,176 @@
+package srcinfo
+
+import (
+	"context"
+	"testing"
+
+	gosrc "github.com/Morganamilo/go-srcinfo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yay/v12/pkg/settings"
+)
+
+func srcinfoWithSources(sources, sha256sums, md5sums []gosrc.ArchString, keys ...string) *gosrc.Srcinfo {
+	return &gosrc.Srcinfo{
+		PackageBase: gosrc.PackageBase{
+			Source:       sources,
+			SHA256Sums:   sha256sums,
+			MD5Sums:      md5sums,
+			ValidPGPKeys: keys,
+		},
+	}
+}
+
+func archStrings(arch string, values ...string) []gosrc.ArchString {
+	res := make([]gosrc.ArchString, 0, len(values))
+	for _, v := range values {
+		res = append(res, gosrc.ArchString{Arch: arch, Value: v})
+	}
+
+	return res
+}
+
+func TestChecksumViolations(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		srcinfo  *gosrc.Srcinfo
+		rules    []string
+		allowed  []string
+		expected []ChecksumViolation
+	}{
+		{
+			name: "vcs source may skip",
+			srcinfo: srcinfoWithSources(
+				archStrings("", "yay::git+https://github.com/Jguer/yay.git", "svn://example.org/trunk"),
+				archStrings("", "SKIP", "SKIP"), nil),
+			rules:    []string{ChecksumNoSkip},
+			expected: []ChecksumViolation{},
+		},
+		{
+			name: "tarball skips",
+			srcinfo: srcinfoWithSources(
+				archStrings("", "https://example.org/foo-1.0.tar.gz", "foo.patch"),
+				archStrings("", "SKIP", "abcd"), nil),
+			rules: []string{ChecksumNoSkip},
+			expected: []ChecksumViolation{
+				{Base: "foo", Rule: ChecksumNoSkip, Source: "https://example.org/foo-1.0.tar.gz"},
+			},
+		},
+		{
+			name: "arch specific sources",
+			srcinfo: srcinfoWithSources(
+				append(archStrings("", "foo.patch"), archStrings("x86_64", "https://example.org/foo-x86_64.tar.gz")...),
+				append(archStrings("", "abcd"), archStrings("x86_64", "SKIP")...), nil),
+			rules: []string{ChecksumNoSkip},
+			expected: []ChecksumViolation{
+				{Base: "foo", Rule: ChecksumNoSkip, Source: "https://example.org/foo-x86_64.tar.gz"},
+			},
+		},
+		{
+			name: "verified signature may skip",
+			srcinfo: srcinfoWithSources(
+				archStrings("", "https://example.org/foo-1.0.tar.gz", "https://example.org/foo-1.0.tar.gz.sig"),
+				archStrings("", "abcd", "SKIP"), nil, "487EACC08557AD082088DABA1EB2638FF56C0C53"),
+			rules:    []string{ChecksumNoSkip, ChecksumSigned},
+			expected: []ChecksumViolation{},
+		},
+		{
+			name: "unverified signature",
+			srcinfo: srcinfoWithSources(
+				archStrings("", "https://example.org/foo-1.0.tar.gz", "https://example.org/foo-1.0.tar.gz.sig"),
+				archStrings("", "abcd", "efgh"), nil),
+			rules: []string{ChecksumSigned},
+			expected: []ChecksumViolation{
+				{Base: "foo", Rule: ChecksumSigned, Source: "https://example.org/foo-1.0.tar.gz.sig"},
+			},
+		},
+		{
+			name: "weak checksums only",
+			srcinfo: srcinfoWithSources(
+				archStrings("", "https://example.org/foo-1.0.tar.gz"),
+				nil, archStrings("", "abcd")),
+			rules: []string{ChecksumStrong},
+			expected: []ChecksumViolation{
+				{Base: "foo", Rule: ChecksumStrong, Source: "md5sums"},
+			},
+		},
+		{
+			name: "weak and strong checksums",
+			srcinfo: srcinfoWithSources(
+				archStrings("", "https://example.org/foo-1.0.tar.gz"),
+				archStrings("", "abcd"), archStrings("", "abcd")),
+			rules:    []string{ChecksumStrong},
+			expected: []ChecksumViolation{},
+		},
+		{
+			name: "strong checksums for another arch",
+			srcinfo: srcinfoWithSources(
+				append(archStrings("", "foo.patch"), archStrings("x86_64", "https://example.org/foo-x86_64.tar.gz")...),
+				archStrings("", "abcd"), archStrings("x86_64", "abcd")),
+			rules: []string{ChecksumStrong},
+			expected: []ChecksumViolation{
+				{Base: "foo", Rule: ChecksumStrong, Source: "md5sums_x86_64"},
+			},
+		},
+		{
+			name: "allowed package",
+			srcinfo: srcinfoWithSources(
+				archStrings("", "https://example.org/foo-1.0.tar.gz"),
+				nil, archStrings("", "SKIP")),
+			rules:    []string{ChecksumNoSkip, ChecksumStrong},
+			allowed:  []string{"foo"},
+			expected: []ChecksumViolation{},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := ChecksumViolations(map[string]*gosrc.Srcinfo{"foo": tc.srcinfo}, tc.rules, tc.allowed)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func TestService_CheckChecksumPolicy(t *testing.T) {
+	srv := &Service{
+		log: newTestLogger(),
+		cfg: &settings.Configuration{ChecksumPolicy: []string{ChecksumNoSkip}},
+		srcInfos: map[string]*gosrc.Srcinfo{
+			"foo": srcinfoWithSources(archStrings("", "https://example.org/foo-1.0.tar.gz"),
+				archStrings("", "SKIP"), nil),
+		},
+	}
+
+	err := srv.CheckChecksumPolicy(context.Background())
+	var errPolicy *ErrChecksumPolicy
+	require.ErrorAs(t, err, &errPolicy)
+
+	srv.cfg.ChecksumPolicyAllow = []string{"foo"}
+	require.NoError(t, srv.CheckChecksumPolicy(context.Background()))
+}
+
+func TestService_CheckChecksumPolicyUnknownRule(t *testing.T) {
+	srv := &Service{
+		log: newTestLogger(),
+		cfg: &settings.Configuration{ChecksumPolicy: []string{ChecksumStrong, "nosikp"}},
+		srcInfos: map[string]*gosrc.Srcinfo{
+			"foo": srcinfoWithSources(archStrings("", "https://example.org/foo-1.0.tar.gz"),
+				archStrings("", "SKIP"), nil),
+		},
+	}
+
+	err := srv.CheckChecksumPolicy(context.Background())
+	var errRule *ErrUnknownChecksumRule
+	require.ErrorAs(t, err, &errRule)
+	assert.Contains(t, err.Error(), "nosikp")
+
+	srv.cfg.ChecksumPolicy = []string{"NoSkip"}
+	var errPolicy *ErrChecksumPolicy
+	require.ErrorAs(t, srv.CheckChecksumPolicy(context.Background()), &errPolicy)
+}
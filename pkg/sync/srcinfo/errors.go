@@ -0,0 +1,22 @@
+package srcinfo
+
+import "github.com/leonelquinteros/gotext"
+
+// ErrChecksumPolicy means that one or more PKGBUILDs violate the checksum policy.
+type ErrChecksumPolicy struct {
+	violations int
+}
+
+func (e *ErrChecksumPolicy) Error() string {
+	return gotext.Get("checksum policy violations found: %d", e.violations)
+}
+
+// ErrUnknownChecksumRule means that the checksum policy names a rule that does not exist.
+type ErrUnknownChecksumRule struct {
+	rule string
+}
+
+func (e *ErrUnknownChecksumRule) Error() string {
+	return gotext.Get("unknown checksum policy rule '%s', expected one of: %s, %s, %s",
+		e.rule, ChecksumNoSkip, ChecksumStrong, ChecksumSigned)
+}
@@ -0,0 +1,26 @@
+package pgp
+
+import (
+	"strings"
+
+	"github.com/leonelquinteros/gotext"
+)
+
+// ErrUnpinnedKeys means that a PKGBUILD lists PGP keys that are not pinned for its pkgbase.
+type ErrUnpinnedKeys struct {
+	keys []string
+}
+
+func (e *ErrUnpinnedKeys) Error() string {
+	return gotext.Get("unexpected PGP keys: %s", strings.Join(e.keys, ", "))
+}
+
+// ErrUnknownLookup means that a configured key lookup method is not supported.
+type ErrUnknownLookup struct {
+	method string
+}
+
+func (e *ErrUnknownLookup) Error() string {
+	return gotext.Get("unknown PGP key lookup method '%s', expected one of: %s",
+		e.method, strings.Join(DefaultLookup(), ", "))
+}
@@ -49,8 +49,16 @@ type GPGCmdBuilder interface {
 // CheckPgpKeys iterates through the keys listed in the PKGBUILDs and if needed,
 // asks the user whether yay should try to import them.
 func CheckPgpKeys(ctx context.Context, logger *text.Logger, pkgbuildDirsByBase map[string]string, srcinfos map[string]*gosrc.Srcinfo,
-	cmdBuilder GPGCmdBuilder, noConfirm bool,
+	cmdBuilder GPGCmdBuilder, opts *KeyOptions, noConfirm bool,
 ) ([]string, error) {
+	if opts == nil {
+		opts = &KeyOptions{}
+	}
+
+	if err := checkPinnedKeys(logger, pkgbuildDirsByBase, srcinfos, opts.Pins, noConfirm); err != nil {
+		return nil, err
+	}
+
 	// Let's check the keys individually, and then we can offer to import
 	// the problematic ones.
 	problematic := make(pgpKeySet)
@@ -86,23 +94,150 @@ func CheckPgpKeys(ctx context.Context, logger *text.Logger, pkgbuildDirsByBase m
 	logger.Println("\n", str)
 
 	if logger.ContinueTask(gotext.Get("Import?"), true, noConfirm) {
-		return problematic.toSlice(), importKeys(ctx, logger, cmdBuilder, problematic.toSlice())
+		dirsByKey := make(map[string][]string, len(problematic))
+
+		for key, bases := range problematic {
+			for _, base := range bases {
+				dirsByKey[key] = append(dirsByKey[key], pkgbuildDirsByBase[base])
+			}
+		}
+
+		return problematic.toSlice(), importKeys(ctx, logger, cmdBuilder,
+			opts.fetchers(cmdBuilder, dirsByKey), problematic.toSlice())
 	}
 
 	return problematic.toSlice(), nil
 }
 
-// importKeys tries to import the list of keys specified in its argument.
-func importKeys(ctx context.Context, logger *text.Logger, cmdBuilder GPGCmdBuilder, keys []string) error {
+// checkPinnedKeys compares the validpgpkeys of each PKGBUILD against the
+// fingerprints pinned for its pkgbase and asks the user whether to continue
+// if unexpected keys are found.
+func checkPinnedKeys(logger *text.Logger, pkgbuildDirsByBase map[string]string,
+	srcinfos map[string]*gosrc.Srcinfo, pins map[string][]string, noConfirm bool,
+) error {
+	if len(pins) == 0 {
+		return nil
+	}
+
+	unexpected := make(pgpKeySet)
+
+	for base := range pkgbuildDirsByBase {
+		pinned, ok := pins[base]
+		if !ok {
+			continue
+		}
+
+		for _, key := range srcinfos[base].ValidPGPKeys {
+			if !containsKey(pinned, key) {
+				unexpected.set(key, base)
+			}
+		}
+	}
+
+	if len(unexpected) == 0 {
+		return nil
+	}
+
+	logger.Warnln(gotext.Get("PGP keys do not match the pinned fingerprints:"))
+
+	for key, bases := range unexpected {
+		logger.Println("  " + gotext.Get("%s, required by: %s", text.Cyan(key), text.Cyan(strings.Join(bases, "  "))))
+	}
+
+	if !logger.ContinueTask(gotext.Get("Continue anyway?"), false, noConfirm) {
+		return &ErrUnpinnedKeys{keys: unexpected.toSlice()}
+	}
+
+	return nil
+}
+
+// importKeys tries to import the list of keys specified in its argument using
+// each lookup method in order, then reports expired or revoked keys.
+func importKeys(ctx context.Context, logger *text.Logger, cmdBuilder GPGCmdBuilder,
+	fetchers []keyFetcher, keys []string,
+) error {
 	logger.OperationInfoln(gotext.Get("Importing keys with gpg..."))
 
-	if err := cmdBuilder.Show(cmdBuilder.BuildGPGCmd(ctx, append([]string{"--recv-keys"}, keys...)...)); err != nil {
+	missing := keys
+	for _, fetch := range fetchers {
+		if len(missing) == 0 {
+			break
+		}
+
+		missing = fetch(ctx, missing)
+	}
+
+	if len(missing) != 0 {
 		return errors.New(gotext.Get("problem importing keys"))
 	}
 
+	reportKeyValidity(ctx, logger, cmdBuilder, keys)
+
 	return nil
 }
 
+// reportKeyValidity warns about imported keys that are expired or revoked.
+func reportKeyValidity(ctx context.Context, logger *text.Logger, cmdBuilder GPGCmdBuilder, keys []string) {
+	stdout, _, _ := cmdBuilder.Capture(cmdBuilder.BuildGPGCmd(ctx,
+		append([]string{"--with-colons", "--list-keys"}, keys...)...))
+
+	validity := parseKeyValidity(stdout)
+
+	for _, key := range keys {
+		for fingerprint, status := range validity {
+			if !strings.HasSuffix(fingerprint, strings.ToUpper(key)) {
+				continue
+			}
+
+			switch status {
+			case "e":
+				logger.Warnln(gotext.Get("PGP key %s is expired", text.Cyan(key)))
+			case "r":
+				logger.Warnln(gotext.Get("PGP key %s is revoked", text.Cyan(key)))
+			}
+		}
+	}
+}
+
+// parseKeyValidity maps the fingerprint of each primary key in gpg's
+// --with-colons output to its validity field.
+func parseKeyValidity(output string) map[string]string {
+	validity := make(map[string]string)
+	current := ""
+	expectFpr := false
+
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(line, ":")
+		if len(fields) < 2 {
+			continue
+		}
+
+		switch fields[0] {
+		case "pub":
+			current = fields[1]
+			expectFpr = true
+		case "fpr":
+			if expectFpr && len(fields) > 9 {
+				validity[strings.ToUpper(fields[9])] = current
+			}
+
+			expectFpr = false
+		}
+	}
+
+	return validity
+}
+
+func containsKey(keys []string, key string) bool {
+	for _, k := range keys {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // formatKeysToImport receives a set of keys and returns a string containing the
 // question asking the user wants to import the problematic keys.
 func formatKeysToImport(logger *text.Logger, keys pgpKeySet) (string, error) {
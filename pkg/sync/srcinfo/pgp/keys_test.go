@@ -9,6 +9,7 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"sort"
 	"strings"
 	"testing"
@@ -61,7 +62,9 @@ func TestCheckPgpKeys(t *testing.T) {
 				"gpg --homedir /tmp --list-keys 487EACC08557AD082088DABA1EB2638FF56C0C53",
 				"gpg --homedir /tmp --recv-keys 487EACC08557AD082088DABA1EB2638FF56C0C53",
 			},
-			wantCapture: []string{},
+			wantCapture: []string{
+				"gpg --homedir /tmp --with-colons --list-keys 487EACC08557AD082088DABA1EB2638FF56C0C53",
+			},
 			showFn: func(cmd *exec.Cmd) error {
 				s := cmd.String()
 				if strings.Contains(s, "--list-keys") {
@@ -84,7 +87,9 @@ func TestCheckPgpKeys(t *testing.T) {
 				"gpg --homedir /tmp --list-keys B6C8F98282B944E3B0D5C2530FC3042E345AD05D",
 				"gpg --homedir /tmp --recv-keys 11E521D646982372EB577A1F8F0871F202119294 B6C8F98282B944E3B0D5C2530FC3042E345AD05D",
 			},
-			wantCapture: []string{},
+			wantCapture: []string{
+				"gpg --homedir /tmp --with-colons --list-keys 11E521D646982372EB577A1F8F0871F202119294 B6C8F98282B944E3B0D5C2530FC3042E345AD05D",
+			},
 			showFn: func(cmd *exec.Cmd) error {
 				s := cmd.String()
 				if strings.Contains(s, "--list-keys") {
@@ -103,9 +108,11 @@ func TestCheckPgpKeys(t *testing.T) {
 					"ABAF11C65A2970B130ABE3C479BE3E4300411886"),
 				"dummy-2": makeSrcinfo("dummy-2", "ABAF11C65A2970B130ABE3C479BE3E4300411886"),
 			},
-			wantError:   false,
-			expected:    []string{"ABAF11C65A2970B130ABE3C479BE3E4300411886"},
-			wantCapture: []string{},
+			wantError: false,
+			expected:  []string{"ABAF11C65A2970B130ABE3C479BE3E4300411886"},
+			wantCapture: []string{
+				"gpg --homedir /tmp --with-colons --list-keys ABAF11C65A2970B130ABE3C479BE3E4300411886",
+			},
 			wantShow: []string{
 				"gpg --homedir /tmp --list-keys ABAF11C65A2970B130ABE3C479BE3E4300411886",
 				"gpg --homedir /tmp --recv-keys ABAF11C65A2970B130ABE3C479BE3E4300411886",
@@ -129,9 +136,11 @@ func TestCheckPgpKeys(t *testing.T) {
 			srcinfos: map[string]*gosrc.Srcinfo{
 				"dummy-3": makeSrcinfo("dummy-3", "11E521D646982372EB577A1F8F0871F202119294", "C52048C0C0748FEE227D47A2702353E0F7E48EDB"),
 			},
-			wantError:   false,
-			expected:    []string{"C52048C0C0748FEE227D47A2702353E0F7E48EDB"},
-			wantCapture: []string{},
+			wantError: false,
+			expected:  []string{"C52048C0C0748FEE227D47A2702353E0F7E48EDB"},
+			wantCapture: []string{
+				"gpg --homedir /tmp --with-colons --list-keys C52048C0C0748FEE227D47A2702353E0F7E48EDB",
+			},
 			showFn: func(cmd *exec.Cmd) error {
 				s := cmd.String()
 				if strings.Contains(s, "--list-keys") &&
@@ -234,7 +243,7 @@ func TestCheckPgpKeys(t *testing.T) {
 				GPGFlags: []string{"--homedir /tmp"},
 				Runner:   mockRunner,
 			}
-			problematic, err := CheckPgpKeys(context.Background(), newTestLogger(), tt.pkgs, tt.srcinfos, &cmdBuilder, true)
+			problematic, err := CheckPgpKeys(context.Background(), newTestLogger(), tt.pkgs, tt.srcinfos, &cmdBuilder, nil, true)
 
 			require.Len(t, mockRunner.ShowCalls, len(tt.wantShow))
 			require.Len(t, mockRunner.CaptureCalls, len(tt.wantCapture))
@@ -267,3 +276,179 @@ func TestCheckPgpKeys(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckPgpKeysPinned(t *testing.T) {
+	srcinfos := map[string]*gosrc.Srcinfo{
+		"cower": makeSrcinfo("cower", "487EACC08557AD082088DABA1EB2638FF56C0C53"),
+	}
+
+	testcases := []struct {
+		name      string
+		pins      map[string][]string
+		wantError bool
+	}{
+		{
+			name:      "no pins",
+			pins:      nil,
+			wantError: false,
+		},
+		{
+			name:      "pinned key matches",
+			pins:      map[string][]string{"cower": {"487eacc08557ad082088daba1eb2638ff56c0c53"}},
+			wantError: false,
+		},
+		{
+			name:      "pinned key changed",
+			pins:      map[string][]string{"cower": {"11E521D646982372EB577A1F8F0871F202119294"}},
+			wantError: true,
+		},
+		{
+			name:      "other package pinned",
+			pins:      map[string][]string{"libc++": {"11E521D646982372EB577A1F8F0871F202119294"}},
+			wantError: false,
+		},
+	}
+
+	for _, tt := range testcases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			mockRunner := &exe.MockRunner{}
+			cmdBuilder := exe.CmdBuilder{GPGBin: "gpg", Runner: mockRunner}
+
+			_, err := CheckPgpKeys(context.Background(), newTestLogger(), map[string]string{"cower": ""},
+				srcinfos, &cmdBuilder, &KeyOptions{Pins: tt.pins}, true)
+			if tt.wantError {
+				var errPin *ErrUnpinnedKeys
+				require.ErrorAs(t, err, &errPin)
+				assert.Empty(t, mockRunner.ShowCalls)
+
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCheckPgpKeysLookup(t *testing.T) {
+	const key = "487EACC08557AD082088DABA1EB2638FF56C0C53"
+
+	gpgBin := t.TempDir() + "/gpg"
+
+	f, err := os.OpenFile(gpgBin, os.O_RDONLY|os.O_CREATE, 0o755)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	pkgDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(pkgDir, "keys", "pgp"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(pkgDir, "keys", "pgp", key+".asc"), []byte{}, 0o644))
+
+	testcases := []struct {
+		name      string
+		opts      *KeyOptions
+		failing   []string
+		wantError bool
+		wantShow  []string
+	}{
+		{
+			name: "wkd succeeds",
+			opts: &KeyOptions{Addresses: map[string]string{key: "dave@example.org"}},
+			wantShow: []string{
+				"gpg --list-keys " + key,
+				"gpg --auto-key-locate clear,nodefault,wkd --locate-external-keys dave@example.org",
+			},
+		},
+		{
+			name: "falls back to second keyserver",
+			opts: &KeyOptions{
+				Lookup:     []string{LookupKeyserver},
+				Keyservers: []string{"hkps://one.example.org", "hkps://two.example.org"},
+			},
+			failing: []string{"one.example.org"},
+			wantShow: []string{
+				"gpg --list-keys " + key,
+				"gpg --keyserver hkps://one.example.org --recv-keys " + key,
+				"gpg --keyserver hkps://two.example.org --recv-keys " + key,
+			},
+		},
+		{
+			name:    "falls back to local key file",
+			opts:    &KeyOptions{},
+			failing: []string{"--recv-keys"},
+			wantShow: []string{
+				"gpg --list-keys " + key,
+				"gpg --recv-keys " + key,
+				"gpg --import " + filepath.Join(pkgDir, "keys", "pgp", key+".asc"),
+			},
+		},
+		{
+			name:      "all methods fail",
+			opts:      &KeyOptions{Lookup: []string{LookupWKD, LookupKeyserver}},
+			failing:   []string{"--recv-keys"},
+			wantError: true,
+			wantShow: []string{
+				"gpg --list-keys " + key,
+				"gpg --recv-keys " + key,
+			},
+		},
+	}
+
+	for _, tt := range testcases {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			mockRunner := &exe.MockRunner{
+				ShowFn: func(cmd *exec.Cmd) error {
+					s := cmd.String()
+					if strings.Contains(s, "--list-keys") {
+						return fmt.Errorf("key not found")
+					}
+
+					for _, f := range tt.failing {
+						if strings.Contains(s, f) {
+							return fmt.Errorf("lookup failed")
+						}
+					}
+
+					return nil
+				},
+			}
+			cmdBuilder := exe.CmdBuilder{GPGBin: gpgBin, Runner: mockRunner}
+
+			_, err := CheckPgpKeys(context.Background(), newTestLogger(), map[string]string{"cower": pkgDir},
+				map[string]*gosrc.Srcinfo{"cower": makeSrcinfo("cower", key)}, &cmdBuilder, tt.opts, true)
+			if tt.wantError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Len(t, mockRunner.ShowCalls, len(tt.wantShow))
+
+			for i, call := range mockRunner.ShowCalls {
+				show := call.Args[0].(*exec.Cmd).String()
+				show = strings.ReplaceAll(show, gpgBin, "gpg")
+				assert.Subset(t, strings.Split(show, " "), strings.Split(tt.wantShow[i], " "), show)
+			}
+		})
+	}
+}
+
+func TestParseKeyValidity(t *testing.T) {
+	output := `tru::1:1700000000:0:3:1:5
+pub:e:4096:1:1EB2638FF56C0C53:1285513283:1600000000::-:::sc::::::23::0:
+fpr:::::::::487EACC08557AD082088DABA1EB2638FF56C0C53:
+uid:e::::1285513283::0AF0D1F2D5F6A5C7E71DCF7E2E0B6A9E2FBD3B5C::Dave Reisner <d@falconindy.com>::::::::::0:
+sub:e:4096:1:AAAAAAAAAAAAAAAA:1285513283:1600000000:::::e::::::23:
+fpr:::::::::BBBBBBBBBBBBBBBBBBBBBBBBAAAAAAAAAAAAAAAA:
+pub:r:4096:1:8F0871F202119294:1531339200:::-:::scESC::::::23::0:
+fpr:::::::::11E521D646982372EB577A1F8F0871F202119294:
+pub:-:4096:1:702353E0F7E48EDB:1531339200:::-:::scESC::::::23::0:
+fpr:::::::::C52048C0C0748FEE227D47A2702353E0F7E48EDB:
+`
+
+	assert.Equal(t, map[string]string{
+		"487EACC08557AD082088DABA1EB2638FF56C0C53": "e",
+		"11E521D646982372EB577A1F8F0871F202119294": "r",
+		"C52048C0C0748FEE227D47A2702353E0F7E48EDB": "-",
+	}, parseKeyValidity(output))
+}
@@ -0,0 +1,155 @@
+package pgp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Lookup methods understood by KeyOptions.Lookup.
+const (
+	LookupWKD       = "wkd"
+	LookupKeyserver = "keyserver"
+	LookupLocal     = "local"
+)
+
+// KeyOptions configures how PGP keys are verified and fetched.
+type KeyOptions struct {
+	// Pins maps a pkgbase to the fingerprints it is expected to list in validpgpkeys.
+	Pins map[string][]string
+	// Lookup is the ordered list of methods used to fetch missing keys.
+	Lookup []string
+	// Keyservers are tried in order by the keyserver method. An empty list
+	// uses gpg's configured keyserver.
+	Keyservers []string
+	// Addresses maps a fingerprint to the e-mail address used for WKD lookups.
+	Addresses map[string]string
+}
+
+// DefaultLookup is the lookup order used when none is configured.
+func DefaultLookup() []string {
+	return []string{LookupWKD, LookupKeyserver, LookupLocal}
+}
+
+// ValidateLookup checks that every method of a lookup order is supported.
+func ValidateLookup(lookup []string) error {
+	for _, method := range lookup {
+		switch strings.ToLower(method) {
+		case LookupWKD, LookupKeyserver, LookupLocal:
+		default:
+			return &ErrUnknownLookup{method}
+		}
+	}
+
+	return nil
+}
+
+// keyFetcher imports keys into the keyring using a single lookup method and
+// returns the keys it was unable to import.
+type keyFetcher func(ctx context.Context, keys []string) []string
+
+func (opts *KeyOptions) fetchers(cmdBuilder GPGCmdBuilder, dirsByKey map[string][]string) []keyFetcher {
+	lookup := opts.Lookup
+	if len(lookup) == 0 {
+		lookup = DefaultLookup()
+	}
+
+	fetchers := make([]keyFetcher, 0, len(lookup))
+
+	for _, method := range lookup {
+		switch strings.ToLower(method) {
+		case LookupWKD:
+			fetchers = append(fetchers, wkdFetcher(cmdBuilder, opts.Addresses))
+		case LookupKeyserver:
+			fetchers = append(fetchers, keyserverFetcher(cmdBuilder, opts.Keyservers))
+		case LookupLocal:
+			fetchers = append(fetchers, localFetcher(cmdBuilder, dirsByKey))
+		}
+	}
+
+	return fetchers
+}
+
+// wkdFetcher locates keys through the Web Key Directory of the address
+// associated with each fingerprint. Keys without a known address are skipped.
+func wkdFetcher(cmdBuilder GPGCmdBuilder, addresses map[string]string) keyFetcher {
+	return func(ctx context.Context, keys []string) []string {
+		missing := make([]string, 0, len(keys))
+
+		for _, key := range keys {
+			address := lookupAddress(addresses, key)
+			if address == "" {
+				missing = append(missing, key)
+				continue
+			}
+
+			if err := cmdBuilder.Show(cmdBuilder.BuildGPGCmd(ctx,
+				"--auto-key-locate", "clear,nodefault,wkd", "--locate-external-keys", address)); err != nil {
+				missing = append(missing, key)
+			}
+		}
+
+		return missing
+	}
+}
+
+// keyserverFetcher tries each keyserver in order until all keys are received.
+func keyserverFetcher(cmdBuilder GPGCmdBuilder, keyservers []string) keyFetcher {
+	if len(keyservers) == 0 {
+		keyservers = []string{""}
+	}
+
+	return func(ctx context.Context, keys []string) []string {
+		for _, keyserver := range keyservers {
+			args := make([]string, 0, len(keys)+3)
+			if keyserver != "" {
+				args = append(args, "--keyserver", keyserver)
+			}
+
+			args = append(args, "--recv-keys")
+			args = append(args, keys...)
+
+			if err := cmdBuilder.Show(cmdBuilder.BuildGPGCmd(ctx, args...)); err == nil {
+				return []string{}
+			}
+		}
+
+		return keys
+	}
+}
+
+// localFetcher imports keys shipped alongside the PKGBUILD in keys/pgp/<fingerprint>.asc.
+func localFetcher(cmdBuilder GPGCmdBuilder, dirsByKey map[string][]string) keyFetcher {
+	return func(ctx context.Context, keys []string) []string {
+		missing := make([]string, 0, len(keys))
+
+	nextKey:
+		for _, key := range keys {
+			for _, dir := range dirsByKey[key] {
+				keyFile := filepath.Join(dir, "keys", "pgp", key+".asc")
+				if _, err := os.Stat(keyFile); err != nil {
+					continue
+				}
+
+				if err := cmdBuilder.Show(cmdBuilder.BuildGPGCmd(ctx, "--import", keyFile)); err == nil {
+					continue nextKey
+				}
+			}
+
+			missing = append(missing, key)
+		}
+
+		return missing
+	}
+}
+
+func lookupAddress(addresses map[string]string, key string) string {
+	for fingerprint, address := range addresses {
+		if strings.EqualFold(fingerprint, key) {
+			return address
+		}
+	}
+
+	return ""
+}
@@ -69,7 +69,12 @@ nextpkg:
 }
 
 func (s *Service) CheckPGPKeys(ctx context.Context) error {
-	_, errCPK := pgp.CheckPgpKeys(ctx, s.log.Child("pgp"), s.pkgBuildDirs, s.srcInfos, s.cmdBuilder, settings.NoConfirm)
+	_, errCPK := pgp.CheckPgpKeys(ctx, s.log.Child("pgp"), s.pkgBuildDirs, s.srcInfos, s.cmdBuilder, &pgp.KeyOptions{
+		Pins:       s.cfg.PGPKeyPins,
+		Lookup:     s.cfg.PGPKeyLookup,
+		Keyservers: s.cfg.PGPKeyservers,
+		Addresses:  s.cfg.PGPKeyAddresses,
+	}, settings.NoConfirm)
 	return errCPK
 }
 
@@ -2,10 +2,12 @@ package sync
 
 import (
 	"context"
+	"time"
 
 	"github.com/Jguer/yay/v12/pkg/completion"
 	"github.com/Jguer/yay/v12/pkg/db"
 	"github.com/Jguer/yay/v12/pkg/dep"
+	"github.com/Jguer/yay/v12/pkg/history"
 	"github.com/Jguer/yay/v12/pkg/multierror"
 	"github.com/Jguer/yay/v12/pkg/runtime"
 	"github.com/Jguer/yay/v12/pkg/settings"
@@ -15,6 +17,7 @@ import (
 	"github.com/Jguer/yay/v12/pkg/sync/workdir"
 	"github.com/Jguer/yay/v12/pkg/text"
 
+	mapset "github.com/deckarep/golang-set/v2"
 	"github.com/leonelquinteros/gotext"
 )
 
@@ -23,6 +26,9 @@ type OperationService struct {
 	cfg        *settings.Configuration
 	dbExecutor db.Executor
 	logger     *text.Logger
+
+	// RebuildBases are package bases built again even if already made.
+	RebuildBases []string
 }
 
 func NewOperationService(ctx context.Context,
@@ -40,7 +46,7 @@ func NewOperationService(ctx context.Context,
 func (o *OperationService) Run(ctx context.Context, run *runtime.Runtime,
 	cmdArgs *parser.Arguments,
 	targets []map[string]*dep.InstallInfo, excluded []string,
-) error {
+) (err error) {
 	if len(targets) == 0 {
 		o.logger.Println("", gotext.Get("there is nothing to do"))
 		return nil
@@ -49,12 +55,41 @@ func (o *OperationService) Run(ctx context.Context, run *runtime.Runtime,
 	installer := build.NewInstaller(o.dbExecutor, run.CmdBuilder,
 		run.VCSStore, o.cfg.Mode, o.cfg.ReBuild,
 		cmdArgs.ExistsArg("w", "downloadonly"), run.Logger.Child("installer"))
+	installer.AddRebuildBases(o.RebuildBases...)
 
 	pkgBuildDirs, errInstall := preparer.Run(ctx, run, targets)
 	if errInstall != nil {
 		return errInstall
 	}
 
+	if o.cfg.HistoryPath != "" && !cmdArgs.ExistsArg("w", "downloadonly") {
+		log := history.NewLog(o.cfg.HistoryPath, o.logger.Child("history"))
+		started := time.Now()
+
+		if transactions, errRead := log.Read(); errRead == nil {
+			o.estimateBuildTime(transactions, targets)
+		} else {
+			o.logger.Debugln(errRead)
+		}
+
+		defer func() {
+			// the handle does not see what pacman installed before failing
+			if err != nil {
+				if errRefresh := o.dbExecutor.RefreshHandle(); errRefresh != nil {
+					o.logger.Debugln(errRefresh)
+				}
+			}
+
+			failedAndIgnored, _ := installer.CompileFailedAndIgnored()
+			transaction := newTransaction(started, targets, pkgBuildDirs, installer, o.dbExecutor,
+				failedAndIgnored, err)
+
+			if errLog := log.Append(transaction); errLog != nil {
+				o.logger.Warnln(gotext.Get("unable to write history:"), errLog)
+			}
+		}()
+	}
+
 	if cleanFunc := preparer.ShouldCleanMakeDeps(run, cmdArgs); cleanFunc != nil {
 		installer.AddPostInstallHook(cleanFunc)
 	}
@@ -65,7 +100,7 @@ func (o *OperationService) Run(ctx context.Context, run *runtime.Runtime,
 
 	go func() {
 		errComp := completion.Update(ctx, run.HTTPClient, o.dbExecutor,
-			o.cfg.AURURL, o.cfg.CompletionPath, o.cfg.CompletionInterval, false)
+			o.cfg.AURURL, o.cfg.CompletionPath, o.cfg.CompletionInterval, false, nil, false)
 		if errComp != nil {
 			o.logger.Warnln(errComp)
 		}
@@ -86,6 +121,10 @@ func (o *OperationService) Run(ctx context.Context, run *runtime.Runtime,
 		return errIncompatible
 	}
 
+	if errPolicy := srcInfo.CheckChecksumPolicy(ctx); errPolicy != nil {
+		return errPolicy
+	}
+
 	if errPGP := srcInfo.CheckPGPKeys(ctx); errPGP != nil {
 		return errPGP
 	}
@@ -106,6 +145,10 @@ func (o *OperationService) Run(ctx context.Context, run *runtime.Runtime,
 		if err := srcInfo.UpdateVCSStore(ctx, targets, failedAndIgnored); err != nil {
 			o.logger.Warnln(err)
 		}
+
+		if err := recordReviews(run, targets, failedAndIgnored); err != nil {
+			o.logger.Warnln(gotext.Get("failed to save review ledger: %s", err))
+		}
 	}
 
 	if err := installer.RunPostInstallHooks(ctx); err != nil {
@@ -115,6 +158,35 @@ func (o *OperationService) Run(ctx context.Context, run *runtime.Runtime,
 	return multiErr.Return()
 }
 
+// recordReviews records the reviews staged by the diff menu for the bases
+// that were installed.
+func recordReviews(run *runtime.Runtime, targets []map[string]*dep.InstallInfo,
+	failedAndIgnored map[string]error,
+) error {
+	if run.ReviewLedger == nil {
+		return nil
+	}
+
+	failedBases := mapset.NewThreadUnsafeSet[string]()
+
+	for _, layer := range targets {
+		for name, info := range layer {
+			if _, ok := failedAndIgnored[name]; ok && info.AURBase != nil {
+				failedBases.Add(*info.AURBase)
+			}
+		}
+	}
+
+	added := run.ReviewLedger.Commit(func(base string) bool {
+		return !failedBases.Contains(base)
+	})
+	if added == 0 {
+		return nil
+	}
+
+	return run.ReviewLedger.Save()
+}
+
 func (o *OperationService) manualConfirmRequired(cmdArgs *parser.Arguments) bool {
 	return (!cmdArgs.ExistsArg("u", "sysupgrade") && cmdArgs.Op != "Y") || o.cfg.DoubleConfirm
 }
@@ -0,0 +1,48 @@
+//go:build !integration
+// +build !integration
+
+package sync
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yay/v12/pkg/dep"
+	"github.com/Jguer/yay/v12/pkg/review"
+	"github.com/Jguer/yay/v12/pkg/runtime"
+)
+
+func TestRecordReviews(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "review.json")
+	ledger := review.NewLedger(path)
+	ledger.Stage(review.Entry{Base: "foo", Commit: "abc", Reviewer: "alice"})
+	ledger.Stage(review.Entry{Base: "bar", Commit: "def", Reviewer: "alice"})
+
+	fooBase, barBase := "foo", "bar"
+	targets := []map[string]*dep.InstallInfo{
+		{
+			"foo":     {Source: dep.AUR, AURBase: &fooBase},
+			"bar":     {Source: dep.AUR, AURBase: &barBase},
+			"bar-doc": {Source: dep.AUR, AURBase: &barBase},
+		},
+	}
+	failed := map[string]error{"bar-doc": errors.New("build failed")}
+
+	run := &runtime.Runtime{ReviewLedger: ledger}
+	require.NoError(t, recordReviews(run, targets, failed))
+
+	saved := review.NewLedger(path)
+	require.NoError(t, saved.Load())
+
+	_, ok := saved.Get("foo", "abc")
+	assert.True(t, ok)
+
+	_, ok = saved.Get("bar", "def")
+	assert.False(t, ok)
+}
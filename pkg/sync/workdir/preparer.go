@@ -78,6 +78,17 @@ func NewPreparer(dbExecutor db.Executor, cmdBuilder exe.ICmdBuilder,
 		})
 	}
 
+	// unattended runs can neither show diffs nor edit, they stop for a review
+	if cfg.Unattended {
+		preper.hooks = append(preper.hooks, Hook{
+			Name:   "review",
+			Hookfn: menus.ReviewGateFn,
+			Type:   PreDownloadSourcesHook,
+		})
+
+		return preper
+	}
+
 	if cfg.DiffMenu {
 		preper.hooks = append(preper.hooks, Hook{
 			Name:   "diff",
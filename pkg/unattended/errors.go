@@ -0,0 +1,26 @@
+package unattended
+
+import (
+	"strings"
+
+	"github.com/leonelquinteros/gotext"
+)
+
+// ErrReviewRequired stops an unattended run on changes that need a review.
+type ErrReviewRequired struct {
+	Conditions []Condition
+}
+
+func (e *ErrReviewRequired) Error() string {
+	details := make([]string, 0, len(e.Conditions))
+
+	for _, cond := range e.Conditions {
+		if cond.Package != "" {
+			details = append(details, cond.Package+": "+cond.Detail)
+		} else {
+			details = append(details, cond.Detail)
+		}
+	}
+
+	return gotext.Get("review required before upgrading unattended: %s", strings.Join(details, "; "))
+}
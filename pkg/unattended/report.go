@@ -0,0 +1,174 @@
+// Package unattended supports upgrades run without a user, such as from a
+// systemd timer. It records what a run did in a report, tracks the state
+// needed to detect changes that require a review, and generates the units
+// that schedule such runs.
+package unattended
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Status is the outcome of an unattended run.
+type Status string
+
+const (
+	StatusSuccess Status = "success"
+	// StatusStopped means the run stopped on a condition that needs a review.
+	StatusStopped Status = "stopped"
+	StatusFailed  Status = "failed"
+)
+
+// Kinds of conditions that stop an unattended run.
+const (
+	ConditionDiff       = "diff-changed"
+	ConditionMaintainer = "maintainer-changed"
+	ConditionNews       = "unread-news"
+)
+
+// Condition is a change that must be reviewed before upgrading.
+type Condition struct {
+	Kind    string `json:"kind"`
+	Package string `json:"package,omitempty"`
+	Detail  string `json:"detail"`
+}
+
+// Package is a package upgraded, installed or held back by the run.
+type Package struct {
+	Name          string `json:"name"`
+	Repository    string `json:"repository"`
+	LocalVersion  string `json:"localVersion,omitempty"`
+	RemoteVersion string `json:"remoteVersion"`
+	Note          string `json:"note,omitempty"`
+}
+
+// Report describes what an unattended run did.
+type Report struct {
+	Started    time.Time   `json:"started"`
+	Finished   time.Time   `json:"finished"`
+	Status     Status      `json:"status"`
+	Error      string      `json:"error,omitempty"`
+	Targets    []Package   `json:"targets"`
+	Held       []Package   `json:"held"`
+	Conditions []Condition `json:"conditions"`
+}
+
+func NewReport(started time.Time) *Report {
+	return &Report{
+		Started:    started,
+		Targets:    []Package{},
+		Held:       []Package{},
+		Conditions: []Condition{},
+	}
+}
+
+// Finish sets the outcome of the run from the error it returned.
+func (r *Report) Finish(finished time.Time, err error) {
+	r.Finished = finished
+	r.Status = StatusSuccess
+
+	if err == nil {
+		return
+	}
+
+	r.Error = err.Error()
+	r.Status = StatusFailed
+
+	var review *ErrReviewRequired
+	if errors.As(err, &review) {
+		r.Status = StatusStopped
+		r.Conditions = append(r.Conditions, review.Conditions...)
+	}
+}
+
+// IsMarkdown reports whether a report path asks for Markdown instead of JSON.
+func IsMarkdown(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".md" || ext == ".markdown"
+}
+
+// Write saves the report to path, as Markdown if the path ends in .md and
+// as JSON otherwise.
+func (r *Report) Write(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	defer out.Close()
+
+	if IsMarkdown(path) {
+		err = r.WriteMarkdown(out)
+	} else {
+		err = r.WriteJSON(out)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return out.Sync()
+}
+
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+
+	return enc.Encode(r)
+}
+
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# yay unattended upgrade: %s\n\n", r.Status)
+	fmt.Fprintf(&b, "- Started: %s\n", r.Started.Format(time.RFC3339))
+	fmt.Fprintf(&b, "- Finished: %s\n", r.Finished.Format(time.RFC3339))
+
+	if r.Error != "" {
+		fmt.Fprintf(&b, "- Error: %s\n", r.Error)
+	}
+
+	if len(r.Conditions) > 0 {
+		b.WriteString("\n## Review required\n\n")
+
+		for _, cond := range r.Conditions {
+			if cond.Package != "" {
+				fmt.Fprintf(&b, "- **%s** %s: %s\n", cond.Package, cond.Kind, cond.Detail)
+			} else {
+				fmt.Fprintf(&b, "- %s: %s\n", cond.Kind, cond.Detail)
+			}
+		}
+	}
+
+	writeTable := func(title string, pkgs []Package) {
+		if len(pkgs) == 0 {
+			return
+		}
+
+		fmt.Fprintf(&b, "\n## %s\n\n", title)
+		b.WriteString("| Package | Repository | From | To | Note |\n")
+		b.WriteString("|---|---|---|---|---|\n")
+
+		for _, pkg := range pkgs {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %s |\n",
+				pkg.Name, pkg.Repository, pkg.LocalVersion, pkg.RemoteVersion, pkg.Note)
+		}
+	}
+
+	writeTable("Targets", r.Targets)
+	writeTable("Held", r.Held)
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
@@ -0,0 +1,92 @@
+package unattended
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Maintainer is the AUR maintainer accepted for a package base. A different
+// maintainer seen by a run is kept as pending until the base is reviewed.
+type Maintainer struct {
+	Name     string    `json:"name"`
+	Pending  string    `json:"pending,omitempty"`
+	Detected time.Time `json:"detected,omitempty"`
+}
+
+// State is kept between unattended runs.
+type State struct {
+	FilePath    string                `json:"-"`
+	Maintainers map[string]Maintainer `json:"maintainers"`
+}
+
+func NewState(filePath string) *State {
+	return &State{
+		FilePath:    filePath,
+		Maintainers: map[string]Maintainer{},
+	}
+}
+
+// Load reads the state from disk. A missing file is not an error.
+func (s *State) Load() error {
+	content, err := os.ReadFile(s.FilePath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to open unattended state '%s': %w", s.FilePath, err)
+	}
+
+	if err := json.Unmarshal(content, s); err != nil {
+		return fmt.Errorf("failed to read unattended state '%s': %w", s.FilePath, err)
+	}
+
+	if s.Maintainers == nil {
+		s.Maintainers = map[string]Maintainer{}
+	}
+
+	return nil
+}
+
+// Save writes the state to disk.
+func (s *State) Save() error {
+	if err := os.MkdirAll(filepath.Dir(s.FilePath), 0o755); err != nil {
+		return err
+	}
+
+	content, err := json.MarshalIndent(s, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(s.FilePath, content, 0o644)
+}
+
+// CheckMaintainer compares the current maintainer of a base with the
+// accepted one and returns the previous maintainer if it changed. The first
+// maintainer seen is accepted. A change is accepted once the base was
+// reviewed after the change was first detected.
+func (s *State) CheckMaintainer(base, current string, lastReview, now time.Time) (previous string, changed bool) {
+	record, ok := s.Maintainers[base]
+	if !ok || record.Name == current {
+		s.Maintainers[base] = Maintainer{Name: current}
+		return "", false
+	}
+
+	if record.Pending != current {
+		record.Pending = current
+		record.Detected = now
+	}
+
+	if lastReview.After(record.Detected) {
+		s.Maintainers[base] = Maintainer{Name: current}
+		return "", false
+	}
+
+	s.Maintainers[base] = record
+
+	return record.Name, true
+}
@@ -0,0 +1,76 @@
+package unattended
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// UnitName is the name of the generated service and timer units.
+const UnitName = "yay-unattended"
+
+// UnitDir returns the directory systemd reads the units from.
+func UnitDir(system bool) (string, error) {
+	if system {
+		return "/etc/systemd/system", nil
+	}
+
+	if configHome := os.Getenv("XDG_CONFIG_HOME"); configHome != "" {
+		return filepath.Join(configHome, "systemd", "user"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, ".config", "systemd", "user"), nil
+}
+
+// ServiceUnit returns a oneshot service running an unattended upgrade.
+func ServiceUnit(execPath string) string {
+	return fmt.Sprintf(`[Unit]
+Description=Unattended system upgrade with yay
+Wants=network-online.target
+After=network-online.target
+
+[Service]
+Type=oneshot
+ExecStart=%s -Syu --unattended
+`, execPath)
+}
+
+// TimerUnit returns a timer starting the service on the given calendar.
+func TimerUnit(onCalendar string) string {
+	return fmt.Sprintf(`[Unit]
+Description=Scheduled unattended system upgrade with yay
+
+[Timer]
+OnCalendar=%s
+RandomizedDelaySec=1h
+Persistent=true
+
+[Install]
+WantedBy=timers.target
+`, onCalendar)
+}
+
+// InstallTimer writes the service and timer units to dir and returns
+// their paths.
+func InstallTimer(dir, execPath, onCalendar string) (servicePath, timerPath string, err error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", "", err
+	}
+
+	servicePath = filepath.Join(dir, UnitName+".service")
+	if err := os.WriteFile(servicePath, []byte(ServiceUnit(execPath)), 0o644); err != nil {
+		return "", "", err
+	}
+
+	timerPath = filepath.Join(dir, UnitName+".timer")
+	if err := os.WriteFile(timerPath, []byte(TimerUnit(onCalendar)), 0o644); err != nil {
+		return "", "", err
+	}
+
+	return servicePath, timerPath, nil
+}
@@ -0,0 +1,114 @@
+//go:build !integration
+// +build !integration
+
+package unattended
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReport_Finish(t *testing.T) {
+	t.Parallel()
+
+	started := time.Date(2024, 5, 1, 3, 0, 0, 0, time.UTC)
+
+	report := NewReport(started)
+	report.Finish(started.Add(time.Minute), nil)
+	assert.Equal(t, StatusSuccess, report.Status)
+
+	report = NewReport(started)
+	report.Finish(started, errors.New("build failed"))
+	assert.Equal(t, StatusFailed, report.Status)
+	assert.Equal(t, "build failed", report.Error)
+
+	cond := Condition{Kind: ConditionDiff, Package: "yay", Detail: "PKGBUILD changed"}
+	report = NewReport(started)
+	report.Finish(started, fmt.Errorf("wrapped: %w", &ErrReviewRequired{Conditions: []Condition{cond}}))
+	assert.Equal(t, StatusStopped, report.Status)
+	assert.Equal(t, []Condition{cond}, report.Conditions)
+}
+
+func TestReport_Write(t *testing.T) {
+	t.Parallel()
+
+	started := time.Date(2024, 5, 1, 3, 0, 0, 0, time.UTC)
+	report := NewReport(started)
+	report.Targets = append(report.Targets, Package{Name: "yay", Repository: "aur", LocalVersion: "12.0", RemoteVersion: "12.1"})
+	report.Held = append(report.Held, Package{Name: "fresh", Repository: "aur", RemoteVersion: "2", Note: "held (age 5h)"})
+	report.Finish(started.Add(time.Minute), &ErrReviewRequired{Conditions: []Condition{
+		{Kind: ConditionNews, Detail: "2024-04-30 Manual intervention"},
+	}})
+
+	dir := t.TempDir()
+	require.NoError(t, report.Write(filepath.Join(dir, "report.json")))
+	require.NoError(t, report.Write(filepath.Join(dir, "report.md")))
+
+	content, err := os.ReadFile(filepath.Join(dir, "report.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"status": "stopped"`)
+
+	var md bytes.Buffer
+	require.NoError(t, report.WriteMarkdown(&md))
+	assert.Contains(t, md.String(), "# yay unattended upgrade: stopped")
+	assert.Contains(t, md.String(), "- unread-news: 2024-04-30 Manual intervention")
+	assert.Contains(t, md.String(), "| yay | aur | 12.0 | 12.1 |  |")
+	assert.Contains(t, md.String(), "| fresh | aur |  | 2 | held (age 5h) |")
+
+	content, err = os.ReadFile(filepath.Join(dir, "report.md"))
+	require.NoError(t, err)
+	assert.Equal(t, md.String(), string(content))
+}
+
+func TestState_CheckMaintainer(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "unattended.json")
+	state := NewState(path)
+	now := time.Date(2024, 5, 1, 3, 0, 0, 0, time.UTC)
+
+	_, changed := state.CheckMaintainer("yay", "alice", time.Time{}, now)
+	assert.False(t, changed)
+
+	previous, changed := state.CheckMaintainer("yay", "mallory", time.Time{}, now)
+	assert.True(t, changed)
+	assert.Equal(t, "alice", previous)
+
+	require.NoError(t, state.Save())
+
+	loaded := NewState(path)
+	require.NoError(t, loaded.Load())
+
+	// a review older than the change does not accept it
+	_, changed = loaded.CheckMaintainer("yay", "mallory", now.Add(-time.Hour), now.Add(time.Hour))
+	assert.True(t, changed)
+
+	_, changed = loaded.CheckMaintainer("yay", "mallory", now.Add(time.Minute), now.Add(time.Hour))
+	assert.False(t, changed)
+	assert.Equal(t, Maintainer{Name: "mallory"}, loaded.Maintainers["yay"])
+}
+
+func TestInstallTimer(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	servicePath, timerPath, err := InstallTimer(dir, "/usr/bin/yay", "weekly")
+	require.NoError(t, err)
+
+	service, err := os.ReadFile(servicePath)
+	require.NoError(t, err)
+	assert.Contains(t, string(service), "ExecStart=/usr/bin/yay -Syu --unattended\n")
+
+	timer, err := os.ReadFile(timerPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(timer), "OnCalendar=weekly\n")
+	assert.Equal(t, filepath.Join(dir, "yay-unattended.timer"), timerPath)
+}
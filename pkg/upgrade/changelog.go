@@ -0,0 +1,295 @@
+package upgrade
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yay/v12/pkg/settings/exe"
+	"github.com/Jguer/yay/v12/pkg/text"
+)
+
+// maxSrcinfoCommits bounds the .SRCINFO history searched for the installed
+// version of a package.
+const maxSrcinfoCommits = 100
+
+// changelogToggle is the upgrade menu answer expanding or collapsing the AUR
+// changelogs.
+const changelogToggle = "?"
+
+// AURChangelog is the AUR git history of a package since its installed version.
+type AURChangelog struct {
+	// Count is the number of commits since the installed version.
+	Count int
+	// Subjects holds the subjects of the most recent commits, newest first.
+	Subjects []string
+	// PkgrelOnly is set when the upgrade only bumps pkgrel, a rebuild of the
+	// same upstream version.
+	PkgrelOnly bool
+}
+
+// ChangelogFetcher reads the AUR changelogs of upgrades from the clones in the
+// build directory, or fetches the history into bare clones in a cache directory
+// for packages that were never downloaded.
+type ChangelogFetcher struct {
+	cmdBuilder exe.GitCmdBuilder
+	aurURL     string
+	buildDir   string
+	cloneDir   string
+	limit      int
+	log        *text.Logger
+}
+
+// NewChangelogFetcher creates a fetcher keeping up to limit subjects per package.
+func NewChangelogFetcher(cmdBuilder exe.GitCmdBuilder, aurURL, buildDir, cloneDir string,
+	limit int, logger *text.Logger,
+) *ChangelogFetcher {
+	return &ChangelogFetcher{
+		cmdBuilder: cmdBuilder,
+		aurURL:     aurURL,
+		buildDir:   buildDir,
+		cloneDir:   cloneDir,
+		limit:      limit,
+		log:        logger,
+	}
+}
+
+// Changelogs returns the changelogs of the AUR upgrades, keyed by package name.
+// Upgrades whose installed version is not in the AUR history are left out.
+// The packages of a base share its changelog, which is fetched once.
+func (c *ChangelogFetcher) Changelogs(ctx context.Context, aurUp UpSlice) map[string]AURChangelog {
+	logs := make(map[string]AURChangelog)
+	if c == nil || c.limit <= 0 {
+		return logs
+	}
+
+	type baseVersions struct {
+		base, localVersion, remoteVersion string
+	}
+
+	namesByBase := make(map[baseVersions][]string)
+
+	for i := range aurUp.Up {
+		up := &aurUp.Up[i]
+		key := baseVersions{up.Base, up.LocalVersion, up.RemoteVersion}
+		namesByBase[key] = append(namesByBase[key], up.Name)
+	}
+
+	var (
+		wg  sync.WaitGroup
+		mux sync.Mutex
+	)
+
+	sem := make(chan uint8, maxConcurrentLogs)
+
+	for key, names := range namesByBase {
+		sem <- 1
+		wg.Add(1)
+
+		go func(key baseVersions, names []string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			log, ok := c.changelog(ctx, key.base, key.localVersion, key.remoteVersion)
+			if !ok {
+				return
+			}
+
+			mux.Lock()
+			for _, name := range names {
+				logs[name] = log
+			}
+			mux.Unlock()
+		}(key, names)
+	}
+
+	wg.Wait()
+
+	return logs
+}
+
+func (c *ChangelogFetcher) changelog(ctx context.Context, base, localVersion, remoteVersion string) (AURChangelog, bool) {
+	dir, ref, ok := c.fetch(ctx, base)
+	if !ok {
+		return AURChangelog{}, false
+	}
+
+	installed, ok := c.findVersion(ctx, dir, ref, localVersion)
+	if !ok {
+		c.log.Debugln("changelog: installed version not found in history of", base, localVersion)
+		return AURChangelog{}, false
+	}
+
+	commitRange := installed + ".." + ref
+
+	stdout, ok := c.git(ctx, dir, "rev-list", "--count", commitRange)
+	if !ok {
+		return AURChangelog{}, false
+	}
+
+	log := AURChangelog{PkgrelOnly: pkgrelOnly(localVersion, remoteVersion)}
+	log.Count, _ = strconv.Atoi(strings.TrimSpace(stdout))
+
+	if log.Count == 0 {
+		return AURChangelog{}, false
+	}
+
+	stdout, ok = c.git(ctx, dir, "log", "--format=%s", "-n", strconv.Itoa(c.limit), commitRange)
+	if !ok {
+		return AURChangelog{}, false
+	}
+
+	for _, subject := range strings.Split(stdout, "\n") {
+		if subject != "" {
+			log.Subjects = append(log.Subjects, subject)
+		}
+	}
+
+	return log, true
+}
+
+// fetch updates the clone of a package base and returns its directory and
+// the ref of the AUR head. The clone in the build directory is fetched the
+// same way the download step pulls it, without touching the working tree.
+func (c *ChangelogFetcher) fetch(ctx context.Context, base string) (dir, ref string, ok bool) {
+	dir = filepath.Join(c.buildDir, base)
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		if _, ok := c.git(ctx, dir, "fetch", "--quiet"); !ok {
+			return "", "", false
+		}
+
+		return dir, "HEAD@{upstream}", true
+	}
+
+	if c.cloneDir == "" {
+		return "", "", false
+	}
+
+	dir = filepath.Join(c.cloneDir, base+".git")
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		if _, ok := c.git(ctx, "", "init", "--quiet", "--bare", dir); !ok {
+			return "", "", false
+		}
+	}
+
+	if _, ok := c.git(ctx, dir, "fetch", "--quiet", "--no-tags", c.aurURL+"/"+base+".git", "HEAD"); !ok {
+		return "", "", false
+	}
+
+	return dir, "FETCH_HEAD", true
+}
+
+// findVersion returns the newest commit whose .SRCINFO has the given version.
+// The version fields of every commit are read with a single git grep.
+func (c *ChangelogFetcher) findVersion(ctx context.Context, dir, ref, version string) (string, bool) {
+	stdout, ok := c.git(ctx, dir, "log", "--format=%H", "-n", strconv.Itoa(maxSrcinfoCommits), ref, "--", ".SRCINFO")
+	if !ok {
+		return "", false
+	}
+
+	commits := strings.Fields(stdout)
+	if len(commits) == 0 {
+		return "", false
+	}
+
+	args := append([]string{"grep", "-E", `^[[:space:]]*(epoch|pkgver|pkgrel) = `}, commits...)
+
+	stdout, ok = c.git(ctx, dir, append(args, "--", ".SRCINFO")...)
+	if !ok {
+		return "", false
+	}
+
+	// lines are <commit>:.SRCINFO:<field> = <value>
+	fields := make(map[string]map[string]string, len(commits))
+
+	for _, line := range strings.Split(stdout, "\n") {
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+
+		key, value, ok := strings.Cut(parts[2], "=")
+		if !ok {
+			continue
+		}
+
+		if fields[parts[0]] == nil {
+			fields[parts[0]] = make(map[string]string, 3)
+		}
+
+		fields[parts[0]][strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+
+	for _, commit := range commits {
+		commitFields, ok := fields[commit]
+		if !ok {
+			continue
+		}
+
+		commitVersion := commitFields["pkgver"] + "-" + commitFields["pkgrel"]
+		if epoch := commitFields["epoch"]; epoch != "" && epoch != "0" {
+			commitVersion = epoch + ":" + commitVersion
+		}
+
+		if commitVersion == version {
+			return commit, true
+		}
+	}
+
+	return "", false
+}
+
+// git runs a git command, logging its failure at debug level.
+func (c *ChangelogFetcher) git(ctx context.Context, dir string, args ...string) (string, bool) {
+	cmd := c.cmdBuilder.BuildGitCmd(ctx, dir, args...)
+
+	stdout, stderr, err := c.cmdBuilder.Capture(cmd)
+	if err != nil {
+		c.log.Debugln("changelog:", cmd.String(), stderr, err)
+		return "", false
+	}
+
+	return stdout, true
+}
+
+// pkgrelOnly returns true if two versions only differ in pkgrel.
+func pkgrelOnly(localVersion, remoteVersion string) bool {
+	local := strings.LastIndex(localVersion, "-")
+	remote := strings.LastIndex(remoteVersion, "-")
+
+	return local >= 0 && remote >= 0 && localVersion[:local] == remoteVersion[:remote]
+}
+
+// formatChangelog renders a changelog as extra text of the upgrade menu, on
+// the upgrade line when collapsed or as extra lines when expanded.
+func formatChangelog(log *AURChangelog, expanded bool) string {
+	kind := gotext.Get("upstream update")
+	if log.PkgrelOnly {
+		kind = gotext.Get("pkgrel rebuild")
+	}
+
+	summary := gotext.GetN("%d AUR commit", "%d AUR commits", log.Count, log.Count) + ", " + kind
+
+	if !expanded {
+		return " " + text.Cyan("("+summary+")")
+	}
+
+	var builder strings.Builder
+
+	builder.WriteString("  ")
+	builder.WriteString(text.Bold(summary))
+
+	for _, subject := range log.Subjects {
+		builder.WriteString("\n    - ")
+		builder.WriteString(subject)
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,165 @@
+//go:build !integration
+// +build !integration
+
+package upgrade
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yay/v12/pkg/settings/exe"
+	"github.com/Jguer/yay/v12/pkg/text"
+)
+
+// testSrcinfoGrep is the git grep of the version fields of three .SRCINFO
+// revisions.
+const testSrcinfoGrep = `ccc:.SRCINFO:	pkgver = 1.1
+ccc:.SRCINFO:	pkgrel = 1
+bbb:.SRCINFO:	pkgver = 1.0
+bbb:.SRCINFO:	pkgrel = 2
+aaa:.SRCINFO:	pkgver = 1.0
+aaa:.SRCINFO:	pkgrel = 1
+`
+
+func newChangelogRunner() *exe.MockRunner {
+	return &exe.MockRunner{
+		CaptureFn: func(cmd *exec.Cmd) (string, string, error) {
+			args := strings.Join(cmd.Args[1:], " ")
+			switch {
+			case strings.HasPrefix(args, "log --format=%H"):
+				return "ccc\nbbb\naaa\n", "", nil
+			case strings.HasPrefix(args, "grep -E"):
+				return testSrcinfoGrep, "", nil
+			case strings.HasPrefix(args, "rev-list --count"):
+				return "3\n", "", nil
+			case strings.HasPrefix(args, "log --format=%s"):
+				return "Update to 1.1\nFix checksums\n", "", nil
+			}
+
+			return "", "", nil
+		},
+	}
+}
+
+func TestChangelogFetcher_BuildDirClone(t *testing.T) {
+	t.Parallel()
+
+	buildDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(buildDir, "foo", ".git"), 0o755))
+
+	runner := newChangelogRunner()
+	fetcher := NewChangelogFetcher(&exe.MockBuilder{Runner: runner}, "https://aur.archlinux.org",
+		buildDir, t.TempDir(), 2, text.NewLogger(io.Discard, io.Discard, strings.NewReader(""), false, "test"))
+
+	logs := fetcher.Changelogs(context.Background(), UpSlice{Up: []Upgrade{
+		{Name: "foo", Base: "foo", LocalVersion: "1.0-2", RemoteVersion: "1.1-1"},
+	}})
+
+	assert.Equal(t, map[string]AURChangelog{"foo": {
+		Count:    3,
+		Subjects: []string{"Update to 1.1", "Fix checksums"},
+	}}, logs)
+
+	first := runner.CaptureCalls[0].Args[0].(*exec.Cmd)
+	assert.Equal(t, filepath.Join(buildDir, "foo"), first.Dir)
+	assert.Equal(t, []string{"git", "fetch", "--quiet"}, first.Args)
+
+	last := runner.CaptureCalls[len(runner.CaptureCalls)-1].Args[0].(*exec.Cmd)
+	assert.Equal(t, []string{"git", "log", "--format=%s", "-n", "2", "bbb..HEAD@{upstream}"}, last.Args)
+}
+
+func TestChangelogFetcher_BareClone(t *testing.T) {
+	t.Parallel()
+
+	cloneDir := t.TempDir()
+	runner := newChangelogRunner()
+	fetcher := NewChangelogFetcher(&exe.MockBuilder{Runner: runner}, "https://aur.archlinux.org",
+		t.TempDir(), cloneDir, 5, text.NewLogger(io.Discard, io.Discard, strings.NewReader(""), false, "test"))
+
+	logs := fetcher.Changelogs(context.Background(), UpSlice{Up: []Upgrade{
+		{Name: "foo", Base: "foo", LocalVersion: "1.0-1", RemoteVersion: "1.0-2"},
+		{Name: "unknown", Base: "foo", LocalVersion: "0.9-1", RemoteVersion: "1.0-2"},
+	}})
+
+	require.Contains(t, logs, "foo")
+	assert.NotContains(t, logs, "unknown")
+	assert.True(t, logs["foo"].PkgrelOnly)
+
+	calls := make([][]string, 0, len(runner.CaptureCalls))
+	for _, call := range runner.CaptureCalls {
+		calls = append(calls, call.Args[0].(*exec.Cmd).Args)
+	}
+
+	assert.Contains(t, calls, []string{"git", "init", "--quiet", "--bare", filepath.Join(cloneDir, "foo.git")})
+	assert.Contains(t, calls, []string{
+		"git", "fetch", "--quiet", "--no-tags",
+		"https://aur.archlinux.org/foo.git", "HEAD",
+	})
+}
+
+func TestChangelogFetcher_SplitPackages(t *testing.T) {
+	t.Parallel()
+
+	runner := newChangelogRunner()
+	fetcher := NewChangelogFetcher(&exe.MockBuilder{Runner: runner}, "https://aur.archlinux.org",
+		t.TempDir(), t.TempDir(), 5, text.NewLogger(io.Discard, io.Discard, strings.NewReader(""), false, "test"))
+
+	logs := fetcher.Changelogs(context.Background(), UpSlice{Up: []Upgrade{
+		{Name: "foo", Base: "foo", LocalVersion: "1.0-1", RemoteVersion: "1.1-1"},
+		{Name: "foo-docs", Base: "foo", LocalVersion: "1.0-1", RemoteVersion: "1.1-1"},
+	}})
+
+	require.Contains(t, logs, "foo")
+	assert.Equal(t, logs["foo"], logs["foo-docs"])
+
+	fetches, searches := 0, 0
+
+	for _, call := range runner.CaptureCalls {
+		switch call.Args[0].(*exec.Cmd).Args[1] {
+		case "fetch":
+			fetches++
+		case "grep":
+			searches++
+		}
+	}
+
+	assert.Equal(t, 1, fetches, "a base is fetched once")
+	assert.Equal(t, 1, searches, "the history is searched with a single git grep")
+}
+
+func TestChangelogFetcher_Disabled(t *testing.T) {
+	t.Parallel()
+
+	var fetcher *ChangelogFetcher
+	assert.Empty(t, fetcher.Changelogs(context.Background(), UpSlice{Up: []Upgrade{{Name: "foo"}}}))
+}
+
+func Test_pkgrelOnly(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, pkgrelOnly("1.0-1", "1.0-2"))
+	assert.True(t, pkgrelOnly("1:1.0-1", "1:1.0-1.1"))
+	assert.False(t, pkgrelOnly("1.0-1", "1.1-1"))
+	assert.False(t, pkgrelOnly("1.0-1", "1:1.0-2"))
+	assert.False(t, pkgrelOnly("1.0", "1.0"))
+}
+
+func Test_formatChangelog(t *testing.T) {
+	t.Parallel()
+
+	log := &AURChangelog{Count: 2, Subjects: []string{"Update to 1.1", "Fix checksums"}}
+	assert.Equal(t, " "+text.Cyan("(2 AUR commits, upstream update)"), formatChangelog(log, false))
+	assert.Equal(t, "  "+text.Bold("2 AUR commits, upstream update")+"\n    - Update to 1.1\n    - Fix checksums",
+		formatChangelog(log, true))
+
+	log = &AURChangelog{Count: 1, PkgrelOnly: true}
+	assert.Equal(t, " "+text.Cyan("(1 AUR commit, pkgrel rebuild)"), formatChangelog(log, false))
+}
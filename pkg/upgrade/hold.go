@@ -0,0 +1,106 @@
+package upgrade
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yay/v12/pkg/query"
+)
+
+// HoldPolicy holds back AUR upgrades that were pushed more recently than a
+// minimum age.
+type HoldPolicy struct {
+	// Delay is the minimum age of an AUR update before it is upgraded.
+	Delay time.Duration
+	// Packages overrides Delay for single packages or package bases.
+	Packages map[string]time.Duration
+}
+
+// delayRegex matches the day and week delays time.ParseDuration lacks.
+var delayRegex = regexp.MustCompile(`^(\d+)([dw])$`)
+
+var delayUnits = map[string]time.Duration{
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+}
+
+// NewHoldPolicy parses the delays of a hold policy, such as "72h", "3d" or
+// "1w". An empty delay disables holding.
+func NewHoldPolicy(delay string, packages map[string]string) (*HoldPolicy, error) {
+	policy := &HoldPolicy{Packages: make(map[string]time.Duration, len(packages))}
+
+	parse := func(value string) (time.Duration, error) {
+		if value == "" {
+			return 0, nil
+		}
+
+		if match := delayRegex.FindStringSubmatch(value); match != nil {
+			count, _ := strconv.Atoi(match[1])
+			return time.Duration(count) * delayUnits[match[2]], nil
+		}
+
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return 0, fmt.Errorf(gotext.Get("invalid upgrade delay '%s'", value)+": %w", err)
+		}
+
+		return duration, nil
+	}
+
+	var err error
+	if policy.Delay, err = parse(delay); err != nil {
+		return nil, err
+	}
+
+	for name, value := range packages {
+		if policy.Packages[name], err = parse(value); err != nil {
+			return nil, err
+		}
+	}
+
+	return policy, nil
+}
+
+// delay returns the minimum age of the upgrades of a package.
+func (h *HoldPolicy) delay(pkg *query.Pkg) time.Duration {
+	if delay, ok := h.Packages[pkg.Name]; ok {
+		return delay
+	}
+
+	if delay, ok := h.Packages[pkg.PackageBase]; ok {
+		return delay
+	}
+
+	return h.Delay
+}
+
+// Holds returns the age of the AUR update of a package and whether it is too
+// recent to be upgraded.
+func (h *HoldPolicy) Holds(pkg *query.Pkg, now time.Time) (age time.Duration, held bool) {
+	if h == nil {
+		return 0, false
+	}
+
+	delay := h.delay(pkg)
+	if delay <= 0 {
+		return 0, false
+	}
+
+	age = now.Sub(time.Unix(int64(pkg.LastModified), 0))
+
+	return age, age < delay
+}
+
+// formatAge formats the age of an update in whole hours, or minutes below
+// an hour.
+func formatAge(age time.Duration) string {
+	if age < time.Hour {
+		return fmt.Sprintf("%dm", int(max(age, 0)/time.Minute))
+	}
+
+	return fmt.Sprintf("%dh", int(age/time.Hour))
+}
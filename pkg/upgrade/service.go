@@ -11,6 +11,8 @@ import (
 	mapset "github.com/deckarep/golang-set/v2"
 	"github.com/leonelquinteros/gotext"
 
+	"github.com/Jguer/yay/v12/pkg/advisory"
+	"github.com/Jguer/yay/v12/pkg/aurrequest"
 	"github.com/Jguer/yay/v12/pkg/db"
 	"github.com/Jguer/yay/v12/pkg/dep"
 	"github.com/Jguer/yay/v12/pkg/dep/topo"
@@ -34,12 +36,35 @@ type UpgradeService struct {
 	noConfirm  bool
 
 	AURWarnings *query.AURWarnings
+	// Advisories marks upgrades fixing a known advisory when set.
+	Advisories *advisory.Feed
+	// Changelogs lists the AUR commits of upgrades in the menu when set.
+	Changelogs *ChangelogFetcher
+	// Requests tells what became of the packages that left the AUR when set.
+	Requests *aurrequest.Feed
+
+	// replaces maps the packages replacing foreign packages to them.
+	replaces         map[string]string
+	develLogs        map[string][]vcs.CommitLog
+	aurLogs          map[string]AURChangelog
+	expandChangelogs bool
+	hold             *HoldPolicy
+	held             []Upgrade
 }
 
 func NewUpgradeService(grapher *dep.Grapher, aurCache aur.QueryClient,
 	dbExecutor db.Executor, vcsStore vcs.Store,
 	cfg *settings.Configuration, noConfirm bool, logger *text.Logger,
-) *UpgradeService {
+) (*UpgradeService, error) {
+	var hold *HoldPolicy
+
+	if !cfg.NoUpgradeDelay {
+		var err error
+		if hold, err = NewHoldPolicy(cfg.AURUpgradeDelay, cfg.AURUpgradeDelayPackages); err != nil {
+			return nil, err
+		}
+	}
+
 	return &UpgradeService{
 		grapher:     grapher,
 		aurCache:    aurCache,
@@ -48,8 +73,9 @@ func NewUpgradeService(grapher *dep.Grapher, aurCache aur.QueryClient,
 		cfg:         cfg,
 		noConfirm:   noConfirm,
 		log:         logger,
+		hold:        hold,
 		AURWarnings: query.NewWarnings(logger.Child("warnings")),
-	}
+	}, nil
 }
 
 // upGraph adds packages to upgrade to the graph.
@@ -66,6 +92,7 @@ func (u *UpgradeService) upGraph(ctx context.Context, graph *topo.Graph[string,
 
 	remote := u.dbExecutor.InstalledRemotePackages()
 	remoteNames := u.dbExecutor.InstalledRemotePackageNames()
+	u.replaces = make(map[string]string)
 
 	if u.cfg.Mode.AtLeastAUR() {
 		u.log.OperationInfoln(gotext.Get("Searching AUR for updates..."))
@@ -81,15 +108,30 @@ func (u *UpgradeService) upGraph(ctx context.Context, graph *topo.Graph[string,
 				u.AURWarnings.AddToWarnings(remote, pkg)
 			}
 
-			u.AURWarnings.CalculateMissing(remoteNames, remote, aurdata)
+			u.AURWarnings.CalculateMissing(u.dbExecutor, remoteNames, remote, aurdata)
+			// successors are only informative, the upgrade goes on without them
+			if errSucc := u.AURWarnings.CalculateSuccessors(ctx, u.aurCache, u.Requests, remote); errSucc != nil {
+				u.log.Warnln(gotext.Get("unable to look up AUR successors:"), errSucc)
+			}
+
+			aurUp = UpAUR(u.log, remote, aurdata, u.cfg.TimeUpdate, enableDowngrade, u.hold)
+			u.held = aurUp.Held
 
-			aurUp = UpAUR(u.log, remote, aurdata, u.cfg.TimeUpdate, enableDowngrade)
+			// the changelogs are only useful to pick upgrades in the menu
+			if !u.noConfirm {
+				u.aurLogs = u.Changelogs.Changelogs(ctx, aurUp)
+			}
 
 			if u.cfg.Devel {
 				u.log.OperationInfoln(gotext.Get("Checking development packages..."))
 
 				develUp = UpDevel(ctx, u.log, remote, aurdata, u.vcsStore)
 
+				// the commit logs are only useful to pick upgrades in the menu
+				if !u.noConfirm {
+					u.develLogs = develCommitLogs(ctx, develUp, u.vcsStore, u.cfg.DevelCommits)
+				}
+
 				u.vcsStore.CleanOrphans(remote)
 			}
 		}
@@ -153,6 +195,8 @@ func (u *UpgradeService) upGraph(ctx context.Context, graph *topo.Graph[string,
 		aurPkgsAdded = append(aurPkgsAdded, aurPkg)
 	}
 
+	aurPkgsAdded = append(aurPkgsAdded, u.graphSuccessors(ctx, graph, remote, names, filter)...)
+
 	u.grapher.AddDepsForPkgs(ctx, aurPkgsAdded, graph)
 
 	if u.cfg.Mode.AtLeastRepo() {
@@ -176,11 +220,116 @@ func (u *UpgradeService) upGraph(ctx context.Context, graph *topo.Graph[string,
 		}
 
 		errs.Add(err)
+
+		if u.cfg.Migrate {
+			u.graphMigrations(ctx, graph, filter)
+		}
 	}
 
 	return errs.Return()
 }
 
+// graphSuccessors adds the AUR packages replacing the foreign packages that
+// were replaced or merged in the AUR and returns them.
+func (u *UpgradeService) graphSuccessors(ctx context.Context,
+	graph *topo.Graph[string, *dep.InstallInfo], remote map[string]alpm.IPackage,
+	names mapset.Set[string], filter Filter,
+) []*aur.Pkg {
+	added := []*aur.Pkg{}
+
+	for i := range u.AURWarnings.Successors {
+		successor := &u.AURWarnings.Successors[i]
+		aurPkg := successor.Package
+
+		if aurPkg == nil || names.Contains(aurPkg.Name) {
+			continue
+		}
+
+		// an installed successor is upgraded on its own
+		if _, installed := remote[aurPkg.Name]; installed {
+			continue
+		}
+
+		if filter != nil && !filter(&db.Upgrade{
+			Name:          aurPkg.Name,
+			RemoteVersion: aurPkg.Version,
+			Repository:    "aur",
+			Base:          aurPkg.PackageBase,
+			LocalVersion:  successor.LocalVersion,
+			Reason:        successor.Reason,
+		}) {
+			continue
+		}
+
+		info := &dep.InstallInfo{
+			Reason:       dep.Reason(successor.Reason),
+			Source:       dep.AUR,
+			AURBase:      &aurPkg.PackageBase,
+			LocalVersion: successor.LocalVersion,
+			Version:      aurPkg.Version,
+		}
+
+		// pacman removes a conflicting package in the same transaction, one
+		// provided by its successor can be removed without breaking the
+		// packages depending on it
+		if !listsName(aurPkg.Conflicts, successor.Name) && listsName(aurPkg.Provides, successor.Name) {
+			info.Replaces = successor.Name
+		}
+
+		graph = u.grapher.GraphAURTarget(ctx, graph, aurPkg, info)
+		names.Add(aurPkg.Name)
+		u.replaces[aurPkg.Name] = successor.Name
+		added = append(added, aurPkg)
+	}
+
+	return added
+}
+
+// listsName reports whether a list of dependencies of an AUR package names a
+// package.
+func listsName(deps []string, name string) bool {
+	for _, dep := range deps {
+		if i := strings.IndexAny(dep, "<>="); i != -1 {
+			dep = dep[:i]
+		}
+
+		if dep == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// graphMigrations adds the sync packages replacing the foreign packages that
+// moved from the AUR to a repository.
+func (u *UpgradeService) graphMigrations(ctx context.Context,
+	graph *topo.Graph[string, *dep.InstallInfo], filter Filter,
+) {
+	for i := range u.AURWarnings.Migrations {
+		migration := &u.AURWarnings.Migrations[i]
+		pkg := migration.Package
+
+		if !migration.Replaces {
+			continue
+		}
+
+		if filter != nil && !filter(&db.Upgrade{
+			Name:          pkg.Name(),
+			RemoteVersion: pkg.Version(),
+			Repository:    pkg.DB().Name(),
+			Base:          pkg.Base(),
+			LocalVersion:  migration.LocalVersion,
+			Reason:        migration.Reason,
+		}) {
+			continue
+		}
+
+		u.grapher.GraphSyncMigration(ctx, graph, pkg, migration.LocalVersion, migration.Reason)
+		u.replaces[pkg.Name()] = migration.Name
+	}
+}
+
 func (u *UpgradeService) graphToUpSlice(graph *topo.Graph[string, *dep.InstallInfo]) (aurUp, repoUp UpSlice) {
 	aurUp = UpSlice{Up: make([]Upgrade, 0, graph.Len())}
 	repoUp = UpSlice{Up: make([]Upgrade, 0, graph.Len()), Repos: u.dbExecutor.Repos()}
@@ -201,6 +350,32 @@ func (u *UpgradeService) graphToUpSlice(graph *topo.Graph[string, *dep.InstallIn
 			extra = fmt.Sprintf(" (%s of %s)", dep.ReasonNames[info.Reason], strings.Join(reducedParents, ", "))
 		}
 
+		extra += u.advisoryExtra(name, info)
+
+		if foreign, ok := u.replaces[name]; ok && foreign != name {
+			extra += " " + gotext.Get("(replaces %s)", foreign)
+		}
+
+		if logs, ok := u.develLogs[name]; ok && info.Devel {
+			if extra != "" {
+				extra += "\n"
+			}
+
+			extra += formatCommitLogs(logs)
+		}
+
+		if log, ok := u.aurLogs[name]; ok && info.Source == dep.AUR && !info.Devel {
+			if u.expandChangelogs {
+				if extra != "" {
+					extra += "\n"
+				}
+
+				extra += formatChangelog(&log, true)
+			} else {
+				extra += formatChangelog(&log, false)
+			}
+		}
+
 		if info.Source == dep.AUR {
 			aurRepo := "aur"
 			if info.Devel {
@@ -232,32 +407,14 @@ func (u *UpgradeService) graphToUpSlice(graph *topo.Graph[string, *dep.InstallIn
 	return aurUp, repoUp
 }
 
-func (u *UpgradeService) GraphUpgrades(ctx context.Context,
-	graph *topo.Graph[string, *dep.InstallInfo],
-	enableDowngrade bool, filter Filter,
-) (*topo.Graph[string, *dep.InstallInfo], error) {
-	if graph == nil {
-		graph = dep.NewGraph()
-	}
-
-	err := u.upGraph(ctx, graph, enableDowngrade, filter)
-	if err != nil {
-		return graph, err
-	}
-
-	if graph.Len() == 0 {
-		return graph, nil
-	}
-
-	return graph, nil
+// Held returns the upgrades held back by the upgrade delay.
+func (u *UpgradeService) Held() []Upgrade {
+	return u.held
 }
 
-// userExcludeUpgrades asks the user which packages to exclude from the upgrade and
-// removes them from the graph
-func (u *UpgradeService) UserExcludeUpgrades(graph *topo.Graph[string, *dep.InstallInfo]) ([]string, error) {
-	if graph.Len() == 0 {
-		return []string{}, nil
-	}
+// printUpgradeMenu prints the pulled dependencies and the numbered upgrades
+// of the graph and returns the upgrades in menu order.
+func (u *UpgradeService) printUpgradeMenu(graph *topo.Graph[string, *dep.InstallInfo]) UpSlice {
 	aurUp, repoUp := u.graphToUpSlice(graph)
 
 	sort.Sort(repoUp)
@@ -291,12 +448,85 @@ func (u *UpgradeService) UserExcludeUpgrades(graph *topo.Graph[string, *dep.Inst
 		len(allUp.Up), text.Bold(gotext.Get("%s to upgrade/install.", gotext.GetN("package", "packages", len(allUp.Up)))))
 	allUp.Print(u.log)
 
-	u.log.Infoln(gotext.Get("Packages to exclude: (eg: \"1 2 3\", \"1-3\", \"^4\" or repo name)"))
-	u.log.Warnln(gotext.Get("Excluding packages may cause partial upgrades and break systems"))
+	return allUp
+}
+
+// advisoryExtra describes the advisories fixed by upgrading a package.
+func (u *UpgradeService) advisoryExtra(name string, info *dep.InstallInfo) string {
+	if u.Advisories == nil || info.LocalVersion == "" {
+		return ""
+	}
+
+	fixed := u.Advisories.FixedBy(name, info.LocalVersion, info.Version)
+	if len(fixed) == 0 {
+		return ""
+	}
+
+	return " " + text.Red(gotext.Get("(fixes %s)", strings.Join(advisory.Names(fixed), ", ")))
+}
+
+func (u *UpgradeService) GraphUpgrades(ctx context.Context,
+	graph *topo.Graph[string, *dep.InstallInfo],
+	enableDowngrade bool, filter Filter,
+) (*topo.Graph[string, *dep.InstallInfo], error) {
+	if graph == nil {
+		graph = dep.NewGraph()
+	}
 
-	numbers, err := u.log.GetInput(u.cfg.AnswerUpgrade, settings.NoConfirm)
+	err := u.upGraph(ctx, graph, enableDowngrade, filter)
 	if err != nil {
-		return nil, err
+		return graph, err
+	}
+
+	if graph.Len() == 0 {
+		return graph, nil
+	}
+
+	return graph, nil
+}
+
+// userExcludeUpgrades asks the user which packages to exclude from the upgrade and
+// removes them from the graph
+func (u *UpgradeService) UserExcludeUpgrades(graph *topo.Graph[string, *dep.InstallInfo]) ([]string, error) {
+	if len(u.held) > 0 {
+		held := UpSlice{Held: u.held}
+		sort.Slice(held.Held, func(i, j int) bool { return held.Held[i].Name < held.Held[j].Name })
+
+		u.log.Printf("%s"+text.Bold(" %d ")+"%s\n", text.Bold(text.Cyan("::")),
+			len(held.Held), text.Bold(gotext.Get("%s held back by the upgrade delay.",
+				gotext.GetN("package", "packages", len(held.Held)))))
+		held.PrintHeld(u.log)
+	}
+
+	if graph.Len() == 0 {
+		return []string{}, nil
+	}
+
+	var (
+		allUp   UpSlice
+		numbers string
+		err     error
+	)
+
+	for {
+		allUp = u.printUpgradeMenu(graph)
+
+		u.log.Infoln(gotext.Get("Packages to exclude: (eg: \"1 2 3\", \"1-3\", \"^4\" or repo name)"))
+		if len(u.aurLogs) > 0 {
+			u.log.Infoln(gotext.Get("Enter %s to expand or collapse the AUR changelogs", changelogToggle))
+		}
+		u.log.Warnln(gotext.Get("Excluding packages may cause partial upgrades and break systems"))
+
+		numbers, err = u.log.GetInput(u.cfg.AnswerUpgrade, settings.NoConfirm)
+		if err != nil {
+			return nil, err
+		}
+
+		if settings.NoConfirm || len(u.aurLogs) == 0 || strings.TrimSpace(numbers) != changelogToggle {
+			break
+		}
+
+		u.expandChangelogs = !u.expandChangelogs
 	}
 
 	// upgrade menu asks you which packages to NOT upgrade so in this case
@@ -5,6 +5,7 @@ package upgrade
 
 import (
 	"context"
+	"errors"
 	"io"
 	"os"
 	"strings"
@@ -15,6 +16,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/Jguer/yay/v12/pkg/advisory"
+	"github.com/Jguer/yay/v12/pkg/aurrequest"
 	"github.com/Jguer/yay/v12/pkg/db"
 	"github.com/Jguer/yay/v12/pkg/db/mock"
 	"github.com/Jguer/yay/v12/pkg/dep"
@@ -588,6 +591,8 @@ func TestUpgradeService_GraphUpgradesNoUpdates(t *testing.T) {
 
 			return mapRemote
 		},
+		SyncPackageFn:   func(string) mock.IPackage { return nil },
+		SyncSatisfierFn: func(string) mock.IPackage { return nil },
 		SyncUpgradesFn: func(bool) (map[string]db.SyncUpgrade, error) {
 			mapUpgrades := make(map[string]db.SyncUpgrade)
 			return mapUpgrades, nil
@@ -726,6 +731,7 @@ func TestUpgradeService_Warnings(t *testing.T) {
 			return mapRemote
 		},
 		LocalSatisfierExistsFn: func(string) bool { return false },
+		SyncPackageFn:          func(string) mock.IPackage { return nil },
 		SyncSatisfierFn: func(s string) mock.IPackage {
 			return nil
 		},
@@ -782,6 +788,240 @@ func TestUpgradeService_Warnings(t *testing.T) {
 	assert.Equal(t, []string{"orphan"}, u.AURWarnings.Orphans)
 }
 
+func TestUpgradeService_Migrate(t *testing.T) {
+	t.Parallel()
+
+	extraDB := mock.NewDB("extra")
+	remote := map[string]mock.IPackage{
+		"foo-bin": &mock.Package{PName: "foo-bin", PVersion: "1.0-1", PReason: alpm.PkgReasonDepend},
+		"bar":     &mock.Package{PName: "bar", PVersion: "2.0-1", PReason: alpm.PkgReasonExplicit},
+		"gone":    &mock.Package{PName: "gone", PVersion: "3.0-1", PReason: alpm.PkgReasonExplicit},
+		"libbaz":  &mock.Package{PName: "libbaz", PVersion: "4.0-1", PReason: alpm.PkgReasonDepend},
+	}
+	foo := &mock.Package{
+		PName: "foo", PVersion: "1.2-1", PDB: extraDB,
+		PConflicts: mock.DependList{Depends: []alpm.Depend{{Name: "foo-bin"}}},
+	}
+	// baz provides libbaz but does not replace it
+	baz := &mock.Package{PName: "baz", PVersion: "5.0-1", PDB: extraDB}
+
+	dbExe := &mock.DBExecutor{
+		InstalledRemotePackageNamesFn: func() []string { return []string{"bar", "foo-bin", "gone", "libbaz"} },
+		InstalledRemotePackagesFn:     func() map[string]mock.IPackage { return remote },
+		LocalPackageFn:                func(string) mock.IPackage { return nil },
+		SyncPackageFn:                 func(string) mock.IPackage { return nil },
+		SyncSatisfierFn: func(s string) mock.IPackage {
+			switch s {
+			case "foo-bin":
+				return foo
+			case "libbaz":
+				return baz
+			}
+
+			return nil
+		},
+		SyncUpgradesFn: func(bool) (map[string]db.SyncUpgrade, error) {
+			return map[string]db.SyncUpgrade{}, nil
+		},
+		ReposFn: func() []string { return []string{"extra"} },
+	}
+
+	mockAUR := &mockaur.MockAUR{
+		GetFn: func(ctx context.Context, query *aur.Query) ([]aur.Pkg, error) {
+			return []aur.Pkg{{Name: "bar", Version: "2.0-1", PackageBase: "bar", Maintainer: "bob"}}, nil
+		},
+	}
+
+	logger := text.NewLogger(io.Discard, os.Stderr, strings.NewReader("\n"), true, "test")
+
+	for _, migrate := range []bool{false, true} {
+		u := &UpgradeService{
+			log:         logger,
+			grapher:     dep.NewGrapher(dbExe, mockAUR, false, true, false, false, false, logger),
+			aurCache:    mockAUR,
+			dbExecutor:  dbExe,
+			vcsStore:    &vcs.Mock{ToUpgradeReturn: []string{}},
+			cfg:         &settings.Configuration{Mode: parser.ModeAny, Migrate: migrate},
+			noConfirm:   true,
+			AURWarnings: query.NewWarnings(logger),
+		}
+
+		graph, err := u.GraphUpgrades(context.Background(), nil, false, func(*Upgrade) bool { return true })
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"gone"}, u.AURWarnings.Missing)
+		require.Len(t, u.AURWarnings.Migrations, 2)
+		assert.Equal(t, "extra/foo", u.AURWarnings.Migrations[0].String())
+		assert.True(t, u.AURWarnings.Migrations[0].Replaces)
+		assert.Equal(t, "extra/baz", u.AURWarnings.Migrations[1].String())
+		assert.False(t, u.AURWarnings.Migrations[1].Replaces)
+
+		if !migrate {
+			assert.Zero(t, graph.Len())
+			continue
+		}
+
+		assert.Equal(t, &dep.InstallInfo{
+			Source:       dep.Sync,
+			Reason:       dep.Dep,
+			Version:      "1.2-1",
+			LocalVersion: "1.0-1",
+			SyncDBName:   ptrString("extra"),
+		}, graph.GetNodeInfo("foo").Value)
+
+		_, repoUp := u.graphToUpSlice(graph)
+		require.Len(t, repoUp.Up, 1)
+		assert.Equal(t, " (replaces foo-bin)", repoUp.Up[0].Extra)
+	}
+}
+
+func TestUpgradeService_Successors(t *testing.T) {
+	t.Parallel()
+
+	remote := map[string]mock.IPackage{
+		"python-foo": &mock.Package{
+			PName: "python-foo", PBase: "python-foo", PVersion: "2-1", PReason: alpm.PkgReasonDepend,
+		},
+	}
+	fooNG := aur.Pkg{Name: "python-foo-ng", PackageBase: "python-foo-ng", Version: "3-1"}
+
+	dbExe := &mock.DBExecutor{
+		InstalledRemotePackageNamesFn: func() []string { return []string{"python-foo"} },
+		InstalledRemotePackagesFn:     func() map[string]mock.IPackage { return remote },
+		LocalPackageFn:                func(string) mock.IPackage { return nil },
+		SyncPackageFn:                 func(string) mock.IPackage { return nil },
+		SyncSatisfierFn:               func(string) mock.IPackage { return nil },
+		ReposFn:                       func() []string { return []string{"core"} },
+	}
+
+	testCases := []struct {
+		desc       string
+		noConfirm  bool
+		conflicts  []string
+		provides   []string
+		wantRemove string
+	}{
+		{
+			desc: "keeps a foreign package the successor does not provide",
+		},
+		{
+			desc:       "removes a foreign package the successor provides",
+			provides:   []string{"python-foo=2"},
+			wantRemove: "python-foo",
+		},
+		{
+			desc:      "lets pacman remove a conflicting foreign package",
+			conflicts: []string{"python-foo<3"},
+			provides:  []string{"python-foo"},
+		},
+		{
+			desc:       "replaces the foreign package with noconfirm",
+			noConfirm:  true,
+			provides:   []string{"python-foo"},
+			wantRemove: "python-foo",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			successor := fooNG
+			successor.Conflicts = tc.conflicts
+			successor.Provides = tc.provides
+
+			mockAUR := &mockaur.MockAUR{
+				GetFn: func(ctx context.Context, query *aur.Query) ([]aur.Pkg, error) {
+					if query.By == aur.Name && query.Needles[0] == "python-foo-ng" {
+						return []aur.Pkg{successor}, nil
+					}
+
+					return []aur.Pkg{}, nil
+				},
+			}
+
+			logger := text.NewLogger(io.Discard, io.Discard, strings.NewReader("\n"), true, "test")
+			u := &UpgradeService{
+				log:         logger,
+				grapher:     dep.NewGrapher(dbExe, mockAUR, false, true, false, false, false, logger),
+				aurCache:    mockAUR,
+				dbExecutor:  dbExe,
+				vcsStore:    &vcs.Mock{ToUpgradeReturn: []string{}},
+				cfg:         &settings.Configuration{Mode: parser.ModeAUR},
+				noConfirm:   tc.noConfirm,
+				AURWarnings: query.NewWarnings(logger),
+				Requests: aurrequest.NewFeed([]aurrequest.Request{{
+					ID: 1, Type: aurrequest.TypeMerge, Package: "python-foo",
+					MergeInto: "python-foo-ng", Status: aurrequest.StatusAccepted,
+				}}),
+			}
+
+			graph, err := u.GraphUpgrades(context.Background(), nil, false, func(*Upgrade) bool { return true })
+			require.NoError(t, err)
+
+			assert.Empty(t, u.AURWarnings.Missing)
+			require.Len(t, u.AURWarnings.Successors, 1)
+			assert.Equal(t, query.SuccessorMerged, u.AURWarnings.Successors[0].Kind)
+
+			assert.Equal(t, &dep.InstallInfo{
+				Source:       dep.AUR,
+				Reason:       dep.Dep,
+				AURBase:      ptrString("python-foo-ng"),
+				Version:      "3-1",
+				LocalVersion: "2-1",
+				Replaces:     tc.wantRemove,
+			}, graph.GetNodeInfo("python-foo-ng").Value)
+
+			aurUp, _ := u.graphToUpSlice(graph)
+			require.Len(t, aurUp.Up, 1)
+			assert.Equal(t, " (replaces python-foo)", aurUp.Up[0].Extra)
+		})
+	}
+}
+
+func TestUpgradeService_SuccessorsLookupFails(t *testing.T) {
+	t.Parallel()
+
+	remote := map[string]mock.IPackage{
+		"python-foo": &mock.Package{PName: "python-foo", PBase: "python-foo", PVersion: "2-1"},
+	}
+
+	dbExe := &mock.DBExecutor{
+		InstalledRemotePackageNamesFn: func() []string { return []string{"python-foo"} },
+		InstalledRemotePackagesFn:     func() map[string]mock.IPackage { return remote },
+		LocalPackageFn:                func(string) mock.IPackage { return nil },
+		SyncPackageFn:                 func(string) mock.IPackage { return nil },
+		SyncSatisfierFn:               func(string) mock.IPackage { return nil },
+		ReposFn:                       func() []string { return []string{"core"} },
+	}
+
+	mockAUR := &mockaur.MockAUR{
+		GetFn: func(ctx context.Context, query *aur.Query) ([]aur.Pkg, error) {
+			if query.By == aur.Replaces {
+				return nil, errors.New("rpc timeout")
+			}
+
+			return []aur.Pkg{}, nil
+		},
+	}
+
+	logger := text.NewLogger(io.Discard, io.Discard, strings.NewReader("\n"), true, "test")
+	u := &UpgradeService{
+		log:         logger,
+		grapher:     dep.NewGrapher(dbExe, mockAUR, false, true, false, false, false, logger),
+		aurCache:    mockAUR,
+		dbExecutor:  dbExe,
+		vcsStore:    &vcs.Mock{ToUpgradeReturn: []string{}},
+		cfg:         &settings.Configuration{Mode: parser.ModeAUR},
+		AURWarnings: query.NewWarnings(logger),
+	}
+
+	_, err := u.GraphUpgrades(context.Background(), nil, false, func(*Upgrade) bool { return true })
+	require.NoError(t, err)
+	assert.Equal(t, []string{"python-foo"}, u.AURWarnings.Missing)
+}
+
 func TestUpgradeService_GraphUpgrades_zfs_dkms(t *testing.T) {
 	t.Parallel()
 	zfsDKMSInfo := &dep.InstallInfo{
@@ -966,3 +1206,67 @@ func TestUpgradeService_GraphUpgrades_zfs_dkms(t *testing.T) {
 		})
 	}
 }
+
+func TestUpgradeService_AdvisoryExtra(t *testing.T) {
+	t.Parallel()
+
+	feed := advisory.NewFeed([]advisory.Advisory{
+		{Name: "AVG-1", Packages: []string{"openssl"}, Status: "Fixed", Fixed: "3.0.8-1"},
+		{Name: "AVG-2", Packages: []string{"libtiff"}, Status: "Vulnerable"},
+	})
+
+	graph := dep.NewGraph()
+	for name, info := range map[string]*dep.InstallInfo{
+		"openssl": {Source: dep.Sync, Reason: dep.Explicit, Version: "3.0.8-1", LocalVersion: "3.0.7-2", SyncDBName: ptrString("core"), Upgrade: true},
+		"libtiff": {Source: dep.Sync, Reason: dep.Explicit, Version: "4.5.1-1", LocalVersion: "4.5.0-1", SyncDBName: ptrString("extra"), Upgrade: true},
+	} {
+		graph.AddNode(name)
+		graph.SetNodeInfo(name, &topo.NodeInfo[*dep.InstallInfo]{Value: info})
+	}
+
+	u := &UpgradeService{
+		dbExecutor: &mock.DBExecutor{ReposFn: func() []string { return []string{"core", "extra"} }},
+		Advisories: feed,
+	}
+
+	_, repoUp := u.graphToUpSlice(graph)
+	extras := make(map[string]string)
+	for _, up := range repoUp.Up {
+		extras[up.Name] = up.Extra
+	}
+
+	assert.Contains(t, extras["openssl"], "(fixes AVG-1)")
+	assert.Empty(t, extras["libtiff"])
+}
+
+func TestUpgradeService_ChangelogToggle(t *testing.T) {
+	t.Parallel()
+
+	graph := dep.NewGraph()
+	graph.AddNode("foo")
+	graph.SetNodeInfo("foo", &topo.NodeInfo[*dep.InstallInfo]{Value: &dep.InstallInfo{
+		Source: dep.AUR, Reason: dep.Explicit, Version: "1.1-1", LocalVersion: "1.0-1",
+		AURBase: ptrString("foo"), Upgrade: true,
+	}})
+
+	out := &strings.Builder{}
+	u := &UpgradeService{
+		dbExecutor: &mock.DBExecutor{ReposFn: func() []string { return []string{"core"} }},
+		cfg:        &settings.Configuration{},
+		log: text.NewLogger(out, io.Discard,
+			io.MultiReader(strings.NewReader("?\n"), strings.NewReader("\n")), false, "test"),
+		aurLogs: map[string]AURChangelog{
+			"foo": {Count: 1, Subjects: []string{"Update to 1.1"}},
+		},
+	}
+
+	excluded, err := u.UserExcludeUpgrades(graph)
+	require.NoError(t, err)
+	assert.Empty(t, excluded)
+	assert.True(t, u.expandChangelogs)
+
+	menus := strings.Split(out.String(), "to upgrade/install.")
+	require.Len(t, menus, 3)
+	assert.NotContains(t, menus[1], "Update to 1.1")
+	assert.Contains(t, menus[2], "Update to 1.1")
+}
@@ -2,6 +2,10 @@ package upgrade
 
 import (
 	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/leonelquinteros/gotext"
 
@@ -11,6 +15,9 @@ import (
 	"github.com/Jguer/yay/v12/pkg/vcs"
 )
 
+// maxConcurrentLogs bounds the commit logs and changelogs fetched at once.
+const maxConcurrentLogs = 8
+
 func UpDevel(
 	ctx context.Context,
 	log *text.Logger,
@@ -22,34 +29,127 @@ func UpDevel(
 	toUpgrade := UpSlice{Up: make([]Upgrade, 0), Repos: []string{"devel"}}
 
 	for pkgName, pkg := range remote {
+		remoteVersion := ""
 		if localCache.ToUpgrade(ctx, pkgName) {
-			if _, ok := aurdata[pkgName]; !ok {
-				log.Warnln(gotext.Get("ignoring package devel upgrade (no AUR info found):"), pkgName)
-				continue
-			}
+			remoteVersion = "latest-commit"
+		} else if tag := localCache.NewTag(ctx, pkgName); tag != "" {
+			remoteVersion = tag
+		}
 
-			if pkg.ShouldIgnore() {
-				printIgnoringPackage(log, pkg, "latest-commit")
-				continue
-			}
+		if remoteVersion == "" {
+			continue
+		}
 
-			toUpgrade.Up = append(toUpgrade.Up,
-				Upgrade{
-					Name:          pkg.Name(),
-					Base:          pkg.Base(),
-					Repository:    "devel",
-					LocalVersion:  pkg.Version(),
-					RemoteVersion: "latest-commit",
-					Reason:        pkg.Reason(),
-				})
+		if _, ok := aurdata[pkgName]; !ok {
+			log.Warnln(gotext.Get("ignoring package devel upgrade (no AUR info found):"), pkgName)
+			continue
 		}
+
+		if pkg.ShouldIgnore() {
+			printIgnoringPackage(log, pkg, remoteVersion)
+			continue
+		}
+
+		if remoteVersion != "latest-commit" {
+			log.Infoln(gotext.Get("%s: new tag available: %s", text.Cyan(pkgName), remoteVersion))
+		}
+
+		toUpgrade.Up = append(toUpgrade.Up,
+			Upgrade{
+				Name:          pkg.Name(),
+				Base:          pkg.Base(),
+				Repository:    "devel",
+				LocalVersion:  pkg.Version(),
+				RemoteVersion: remoteVersion,
+				Reason:        pkg.Reason(),
+			})
 	}
 
 	localCache.RemovePackages(toRemove)
 
+	// persist the remote heads recorded by ToUpgrade in a single write
+	if err := localCache.Save(); err != nil {
+		log.Errorln(err)
+	}
+
 	return toUpgrade
 }
 
+// develCommitLogs fetches the commit logs of the devel upgrades following a
+// branch head. limit is the number of subjects kept per origin, 0 disables
+// the logs.
+func develCommitLogs(ctx context.Context, develUp UpSlice, localCache vcs.Store, limit int) map[string][]vcs.CommitLog {
+	logs := make(map[string][]vcs.CommitLog)
+	if limit <= 0 {
+		return logs
+	}
+
+	var (
+		wg  sync.WaitGroup
+		mux sync.Mutex
+	)
+
+	sem := make(chan uint8, maxConcurrentLogs)
+
+	for i := range develUp.Up {
+		up := &develUp.Up[i]
+		if up.RemoteVersion != "latest-commit" {
+			continue
+		}
+
+		sem <- 1
+		wg.Add(1)
+
+		go func(pkgName string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			pkgLogs := localCache.CommitLogs(ctx, pkgName, limit)
+			if len(pkgLogs) == 0 {
+				return
+			}
+
+			mux.Lock()
+			logs[pkgName] = pkgLogs
+			mux.Unlock()
+		}(up.Name)
+	}
+
+	wg.Wait()
+
+	return logs
+}
+
+// formatCommitLogs renders commit logs as extra lines of the upgrade menu.
+func formatCommitLogs(logs []vcs.CommitLog) string {
+	var builder strings.Builder
+
+	for i := range logs {
+		log := &logs[i]
+
+		count := strconv.Itoa(log.Count)
+		if log.Partial {
+			count += "+"
+		}
+
+		if i > 0 {
+			builder.WriteString("\n")
+		}
+
+		builder.WriteString("  ")
+		builder.WriteString(text.Bold(gotext.Get("%s new commits in %s", count, log.URL)))
+
+		for _, subject := range log.Subjects {
+			builder.WriteString("\n    - ")
+			builder.WriteString(subject)
+		}
+	}
+
+	return builder.String()
+}
+
 func printIgnoringPackage(log *text.Logger, pkg db.IPackage, newPkgVersion string) {
 	left, right := query.GetVersionDiff(pkg.Version(), newPkgVersion)
 
@@ -62,10 +162,12 @@ func printIgnoringPackage(log *text.Logger, pkg db.IPackage, newPkgVersion strin
 
 // UpAUR gathers foreign packages and checks if they have new versions.
 // Output: Upgrade type package list.
+// Upgrades held back by the hold policy are returned in Held.
 func UpAUR(log *text.Logger, remote map[string]db.IPackage, aurdata map[string]*query.Pkg,
-	timeUpdate, enableDowngrade bool,
+	timeUpdate, enableDowngrade bool, hold *HoldPolicy,
 ) UpSlice {
 	toUpgrade := UpSlice{Up: make([]Upgrade, 0), Repos: []string{"aur"}}
+	now := time.Now()
 
 	for name, pkg := range remote {
 		aurPkg, ok := aurdata[name]
@@ -78,17 +180,26 @@ func UpAUR(log *text.Logger, remote map[string]db.IPackage, aurdata map[string]*
 			(enableDowngrade && (db.VerCmp(pkg.Version(), aurPkg.Version) > 0)) {
 			if pkg.ShouldIgnore() {
 				printIgnoringPackage(log, pkg, aurPkg.Version)
-			} else {
-				toUpgrade.Up = append(toUpgrade.Up,
-					Upgrade{
-						Name:          aurPkg.Name,
-						Base:          aurPkg.PackageBase,
-						Repository:    "aur",
-						LocalVersion:  pkg.Version(),
-						RemoteVersion: aurPkg.Version,
-						Reason:        pkg.Reason(),
-					})
+				continue
 			}
+
+			up := Upgrade{
+				Name:          aurPkg.Name,
+				Base:          aurPkg.PackageBase,
+				Repository:    "aur",
+				LocalVersion:  pkg.Version(),
+				RemoteVersion: aurPkg.Version,
+				Reason:        pkg.Reason(),
+			}
+
+			if age, held := hold.Holds(aurPkg, now); held {
+				up.Extra = gotext.Get("held (age %s)", formatAge(age))
+				toUpgrade.Held = append(toUpgrade.Held, up)
+
+				continue
+			}
+
+			toUpgrade.Up = append(toUpgrade.Up, up)
 		}
 	}
 
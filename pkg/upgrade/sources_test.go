@@ -13,6 +13,7 @@ import (
 
 	aur "github.com/Jguer/aur"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	alpm "github.com/Jguer/go-alpm/v2"
 
@@ -127,7 +128,7 @@ func Test_upAUR(t *testing.T) {
 			t.Parallel()
 
 			got := UpAUR(text.NewLogger(io.Discard, os.Stderr, strings.NewReader(""), false, "test"),
-				tt.args.remote, tt.args.aurdata, tt.args.timeUpdate, tt.args.enableDowngrade)
+				tt.args.remote, tt.args.aurdata, tt.args.timeUpdate, tt.args.enableDowngrade, nil)
 			assert.ElementsMatch(t, tt.want.Repos, got.Repos)
 			assert.ElementsMatch(t, tt.want.Up, got.Up)
 			assert.Equal(t, tt.want.Len(), got.Len())
@@ -135,6 +136,59 @@ func Test_upAUR(t *testing.T) {
 	}
 }
 
+func Test_upAURHeld(t *testing.T) {
+	t.Parallel()
+
+	remote := map[string]alpm.IPackage{
+		"fresh":    &mock.Package{PName: "fresh", PVersion: "1.0.0"},
+		"old":      &mock.Package{PName: "old", PVersion: "1.0.0"},
+		"override": &mock.Package{PName: "override", PVersion: "1.0.0"},
+	}
+	aurdata := map[string]*aur.Pkg{
+		"fresh":    {Version: "1.1.0", Name: "fresh", LastModified: int(time.Now().Add(-5 * time.Hour).Unix())},
+		"old":      {Version: "1.1.0", Name: "old", LastModified: int(time.Now().Add(-96 * time.Hour).Unix())},
+		"override": {Version: "1.1.0", Name: "override", PackageBase: "override-base", LastModified: int(time.Now().Unix())},
+	}
+
+	hold, err := NewHoldPolicy("72h", map[string]string{"override-base": "0"})
+	require.NoError(t, err)
+
+	got := UpAUR(text.NewLogger(io.Discard, os.Stderr, strings.NewReader(""), false, "test"),
+		remote, aurdata, false, false, hold)
+
+	assert.ElementsMatch(t, []Upgrade{
+		{Name: "old", Repository: "aur", LocalVersion: "1.0.0", RemoteVersion: "1.1.0"},
+		{Name: "override", Base: "override-base", Repository: "aur", LocalVersion: "1.0.0", RemoteVersion: "1.1.0"},
+	}, got.Up)
+	assert.Equal(t, []Upgrade{
+		{Name: "fresh", Repository: "aur", LocalVersion: "1.0.0", RemoteVersion: "1.1.0", Extra: "held (age 5h)"},
+	}, got.Held)
+}
+
+func TestNewHoldPolicy(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewHoldPolicy("3 days", nil)
+	assert.Error(t, err)
+
+	_, err = NewHoldPolicy("", map[string]string{"yay": "soon"})
+	assert.Error(t, err)
+
+	hold, err := NewHoldPolicy("3d", map[string]string{"yay": "1w", "go": "90m"})
+	require.NoError(t, err)
+	assert.Equal(t, 72*time.Hour, hold.Delay)
+	assert.Equal(t, map[string]time.Duration{"yay": 7 * 24 * time.Hour, "go": 90 * time.Minute}, hold.Packages)
+
+	hold, err = NewHoldPolicy("", nil)
+	require.NoError(t, err)
+
+	_, held := hold.Holds(&aur.Pkg{Name: "yay", LastModified: int(time.Now().Unix())}, time.Now())
+	assert.False(t, held)
+
+	assert.Equal(t, "30m", formatAge(30*time.Minute))
+	assert.Equal(t, "50h", formatAge(50*time.Hour+10*time.Minute))
+}
+
 func Test_upDevel(t *testing.T) {
 	t.Parallel()
 
@@ -200,6 +254,33 @@ func Test_upDevel(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:     "New tag",
+			finalLen: 2,
+			args: args{
+				cached: &vcs.Mock{
+					NewTagReturn: map[string]string{"hello": "v2.1.0"},
+				},
+				remote: map[string]alpm.IPackage{
+					"hello":  &mock.Package{PName: "hello", PVersion: "2.0.0"},
+					"hello2": &mock.Package{PName: "hello2", PVersion: "3.0.0"},
+				},
+				aurdata: map[string]*aur.Pkg{
+					"hello":  {Version: "2.0.0", Name: "hello"},
+					"hello2": {Version: "3.0.0", Name: "hello2"},
+				},
+			},
+			want: UpSlice{
+				Repos: []string{"devel"}, Up: []Upgrade{
+					{
+						Name:          "hello",
+						Repository:    "devel",
+						LocalVersion:  "2.0.0",
+						RemoteVersion: "v2.1.0",
+					},
+				},
+			},
+		},
 		{
 			name:     "No update returned",
 			finalLen: 1,
@@ -238,3 +319,34 @@ func Test_upDevel(t *testing.T) {
 		})
 	}
 }
+
+func Test_develCommitLogs(t *testing.T) {
+	t.Parallel()
+
+	logs := []vcs.CommitLog{{URL: "github.com/Jguer/yay.git", Count: 2, Subjects: []string{"a", "b"}}}
+	store := &vcs.Mock{CommitLogsReturn: map[string][]vcs.CommitLog{"yay-git": logs, "tagged": logs}}
+	develUp := UpSlice{Up: []Upgrade{
+		{Name: "yay-git", RemoteVersion: "latest-commit"},
+		{Name: "tagged", RemoteVersion: "v1.2"},
+		{Name: "nolog", RemoteVersion: "latest-commit"},
+	}}
+
+	got := develCommitLogs(context.Background(), develUp, store, 5)
+	assert.Equal(t, map[string][]vcs.CommitLog{"yay-git": logs}, got)
+	assert.Empty(t, develCommitLogs(context.Background(), develUp, store, 0))
+}
+
+func Test_formatCommitLogs(t *testing.T) {
+	text.UseColor = false
+
+	got := formatCommitLogs([]vcs.CommitLog{
+		{URL: "github.com/Jguer/yay.git", Count: 2, Subjects: []string{"Fix crash", "Update translations"}},
+		{URL: "github.com/Jguer/aur.git", Count: 100, Partial: true, Subjects: []string{"Bump"}},
+	})
+
+	assert.Equal(t, "  2 new commits in github.com/Jguer/yay.git\n"+
+		"    - Fix crash\n"+
+		"    - Update translations\n"+
+		"  100+ new commits in github.com/Jguer/aur.git\n"+
+		"    - Bump", got)
+}
@@ -24,6 +24,8 @@ type UpSlice struct {
 	Up         []Upgrade
 	Repos      []string
 	PulledDeps []Upgrade
+	// Held lists the upgrades held back by the upgrade delay.
+	Held []Upgrade
 }
 
 func (u UpSlice) Len() int      { return len(u.Up) }
@@ -80,16 +82,27 @@ func (u UpSlice) Print(logger *text.Logger) {
 
 		logger.Printf("%s -> %s\n", fmt.Sprintf(versionPadding, left), right)
 		if upgrade.Extra != "" {
-			logger.Println(strings.Repeat(" ", longestNumber), upgrade.Extra)
+			for _, line := range strings.Split(upgrade.Extra, "\n") {
+				logger.Println(strings.Repeat(" ", longestNumber), line)
+			}
 		}
 	}
 }
 
 func (u UpSlice) PrintDeps(logger *text.Logger) {
+	printUnnumbered(logger, u.PulledDeps)
+}
+
+// PrintHeld prints the upgrades held back by the upgrade delay.
+func (u UpSlice) PrintHeld(logger *text.Logger) {
+	printUnnumbered(logger, u.Held)
+}
+
+func printUnnumbered(logger *text.Logger, ups []Upgrade) {
 	longestName, longestVersion := 0, 0
 
-	for k := range u.PulledDeps {
-		upgrade := &u.PulledDeps[k]
+	for k := range ups {
+		upgrade := &ups[k]
 		packNameLen := len(StylizedNameWithRepository(upgrade))
 		packVersion, _ := query.GetVersionDiff(upgrade.LocalVersion, upgrade.RemoteVersion)
 		packVersionLen := len(packVersion)
@@ -97,13 +110,13 @@ func (u UpSlice) PrintDeps(logger *text.Logger) {
 		longestVersion = max(packVersionLen, longestVersion)
 	}
 
-	lenUp := len(u.PulledDeps)
+	lenUp := len(ups)
 	longestNumber := len(fmt.Sprintf("%v", lenUp))
 	namePadding := fmt.Sprintf("  %s%%-%ds  ", strings.Repeat(" ", longestNumber), longestName)
 	versionPadding := fmt.Sprintf("%%-%ds", longestVersion)
 
-	for k := range u.PulledDeps {
-		upgrade := &u.PulledDeps[k]
+	for k := range ups {
+		upgrade := &ups[k]
 		left, right := query.GetVersionDiff(upgrade.LocalVersion, upgrade.RemoteVersion)
 
 		logger.Printf(namePadding, StylizedNameWithRepository(upgrade))
@@ -0,0 +1,14 @@
+package vcs
+
+import "github.com/leonelquinteros/gotext"
+
+// ErrSchemaVersion is returned when vcs.json was written by a newer yay.
+type ErrSchemaVersion struct {
+	filePath string
+	version  int
+}
+
+func (e *ErrSchemaVersion) Error() string {
+	return gotext.Get("vcs file '%s' has schema version %d, this yay supports up to %d",
+		e.filePath, e.version, SchemaVersion)
+}
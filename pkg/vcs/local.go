@@ -0,0 +1,217 @@
+package vcs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	gosrc "github.com/Morganamilo/go-srcinfo"
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yay/v12/pkg/text"
+)
+
+// checkoutName returns the name makepkg gives to the download of a VCS
+// source, following get_filename of makepkg.
+func checkoutName(source string) string {
+	if name, _, ok := strings.Cut(source, "::"); ok {
+		return name
+	}
+
+	url := source
+	if _, after, ok := strings.Cut(source, "://"); ok {
+		url = after
+	}
+
+	url = strings.Split(url, "#")[0]
+	url = strings.Split(url, "?")[0]
+	url = strings.TrimSuffix(url, "/")
+	name := url[strings.LastIndex(url, "/")+1:]
+
+	vcsType := source
+	if i := strings.IndexAny(source, "+:"); i >= 0 {
+		vcsType = source[:i]
+	}
+
+	switch vcsType {
+	case VCSBzr:
+		if _, after, ok := strings.Cut(name, "lp:"); ok {
+			name = after
+		}
+	case VCSFossil:
+		name += ".fossil"
+	case VCSGit:
+		// makepkg strips ${filename%%.git*}
+		name, _, _ = strings.Cut(name, ".git")
+	}
+
+	return name
+}
+
+// fossilInfoHash reads the check-in hash from the output of fossil info, on
+// the checkout line of a working copy or the hash line of a check-in.
+func fossilInfoHash(info string) string {
+	for _, line := range strings.Split(info, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || (key != "checkout" && key != "hash") {
+			continue
+		}
+
+		if fields := strings.Fields(value); len(fields) > 0 && fossilHash.MatchString(fields[0]) {
+			return fields[0]
+		}
+	}
+
+	return ""
+}
+
+// localCommit returns the commit of a checkout. checkout is the working copy
+// extracted by makepkg and reflects what was built, mirror is the download in
+// SRCDEST which is read at the given branch when the working copy is gone.
+func (v *InfoStore) localCommit(ctx context.Context, vcsType, branch, checkout, mirror string) string {
+	isDir := func(dir string) bool {
+		stat, err := os.Stat(dir)
+		return err == nil && stat.IsDir()
+	}
+
+	var cmds []*exec.Cmd
+
+	switch vcsType {
+	case VCSGit:
+		if isDir(checkout) {
+			cmds = append(cmds, v.CmdBuilder.BuildGitCmd(ctx, checkout, "rev-parse", "HEAD"))
+		}
+
+		if isDir(mirror) {
+			cmds = append(cmds, v.CmdBuilder.BuildGitCmd(ctx, mirror, "rev-parse", "--verify", "--quiet", branch+"^{commit}"))
+		}
+	case VCSHg:
+		if isDir(checkout) {
+			cmds = append(cmds, v.CmdBuilder.BuildHgCmd(ctx, checkout, "identify", "--id"))
+		}
+
+		if isDir(mirror) {
+			cmds = append(cmds, v.CmdBuilder.BuildHgCmd(ctx, mirror, "identify", "--id", "-r", branch))
+		}
+	case VCSSvn:
+		for _, dir := range []string{checkout, mirror} {
+			if isDir(dir) {
+				cmds = append(cmds, v.CmdBuilder.BuildSvnCmd(ctx, dir, "info", "--show-item", "last-changed-revision"))
+			}
+		}
+	case VCSBzr:
+		for _, dir := range []string{checkout, mirror} {
+			if isDir(dir) {
+				cmds = append(cmds, v.CmdBuilder.BuildBzrCmd(ctx, dir, "revno"))
+			}
+		}
+	case VCSFossil:
+		// the fossil download is a repository file opened into the checkout
+		if isDir(checkout) {
+			cmds = append(cmds, v.CmdBuilder.BuildFossilCmd(ctx, checkout, "info"))
+		}
+
+		if stat, err := os.Stat(mirror); err == nil && !stat.IsDir() {
+			cmds = append(cmds, v.CmdBuilder.BuildFossilCmd(ctx, "", "info", "-R", mirror, branch))
+		}
+	}
+
+	for _, cmd := range cmds {
+		stdout, stderr, err := v.CmdBuilder.Capture(cmd)
+		if err != nil {
+			v.logger.Debugln(cmd.String(), stderr, err)
+			continue
+		}
+
+		if vcsType == VCSFossil {
+			stdout = fossilInfoHash(stdout)
+		}
+
+		// hg marks working copies with uncommitted changes with a +
+		if commit := strings.TrimSuffix(strings.TrimSpace(stdout), "+"); commit != "" {
+			return commit
+		}
+	}
+
+	return ""
+}
+
+// UpdateFromCheckouts records the VCS info of a package from the sources
+// already downloaded in its build directory and in srcDest, without querying
+// the remotes. An empty srcDest means makepkg downloads into pkgBuildDir.
+func (v *InfoStore) UpdateFromCheckouts(ctx context.Context, pkgName string,
+	sources []gosrc.ArchString, pkgBuildDir, srcDest string,
+) bool {
+	if srcDest == "" {
+		srcDest = pkgBuildDir
+	}
+
+	info := make(OriginInfoByURL)
+
+	for _, source := range sources {
+		vcsType, url, branch, protocols := parseSource(source.Value)
+
+		name := checkoutName(source.Value)
+		mirror := filepath.Join(srcDest, name)
+
+		// makepkg opens fossil repositories into a directory without the suffix
+		if vcsType == VCSFossil {
+			name = strings.TrimSuffix(name, ".fossil")
+		}
+
+		checkout := filepath.Join(pkgBuildDir, "src", name)
+		if url == "" {
+			pattern, tracked := v.TagTracking.pattern(pkgName)
+			tagURL, tagProtocols, tag := parseTagSource(source.Value)
+
+			if !tracked || tagURL == "" || tag == "" {
+				continue
+			}
+
+			// the tag of the source is the one that was built
+			info[tagURL] = OriginInfo{
+				Protocols:  tagProtocols,
+				SHA:        v.localCommit(ctx, VCSGit, "HEAD", checkout, ""),
+				VCS:        VCSGit,
+				TagPattern: pattern,
+				Tag:        tag,
+			}
+
+			continue
+		}
+
+		commit := v.localCommit(ctx, vcsType, branch, checkout, mirror)
+		if commit == "" {
+			v.logger.Debugln(gotext.Get("no local checkout of %s repo: %s", vcsType, text.Cyan(url)))
+			continue
+		}
+
+		info[url] = OriginInfo{
+			Protocols: protocols,
+			Branch:    branch,
+			SHA:       commit,
+			VCS:       vcsType,
+		}
+
+		v.logger.Debugln(gotext.Get("Found %s repo: %s", vcsType, text.Cyan(url)))
+	}
+
+	if len(info) == 0 {
+		return false
+	}
+
+	v.mux.Lock()
+	defer v.mux.Unlock()
+
+	v.OriginsByPackage[pkgName] = info
+	v.markChanged(pkgName)
+
+	if err := v.save(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+
+	return true
+}
@@ -0,0 +1,136 @@
+//go:build !integration
+// +build !integration
+
+package vcs
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gosrc "github.com/Morganamilo/go-srcinfo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yay/v12/pkg/settings/exe"
+)
+
+func TestCheckoutName(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"git+https://github.com/Jguer/yay.git":              "yay",
+		"yay::git+https://github.com/Jguer/yay#branch=next": "yay",
+		"hg+https://hg.example.org/repo/":                   "repo",
+		"svn+https://svn.example.org/trunk?rev=1":           "trunk",
+		"git+https://github.com/Jguer/go-alpm#tag=v2.0":     "go-alpm",
+		"git+https://github.com/Jguer/yay.git/":             "yay",
+		"git+https://example.org/repo.git.bak":              "repo",
+		"bzr+lp:foo":                                        "foo",
+		"fossil+https://fossil-scm.org/home":                "home.fossil",
+	}
+
+	for source, want := range tests {
+		assert.Equal(t, want, checkoutName(source), source)
+	}
+}
+
+func TestInfoStore_UpdateFromCheckouts(t *testing.T) {
+	t.Parallel()
+
+	pkgBuildDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(pkgBuildDir, "src", "yay"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(pkgBuildDir, "aur"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(pkgBuildDir, "src", "go-alpm"), 0o755))
+
+	runner := &exe.MockRunner{
+		CaptureFn: func(cmd *exec.Cmd) (string, string, error) {
+			switch {
+			case strings.Contains(cmd.Dir, filepath.Join("src", "yay")):
+				return "aaaa\n", "", nil
+			case strings.Contains(cmd.Dir, filepath.Join("src", "go-alpm")):
+				return "cccc\n", "", nil
+			default:
+				return "bbbb\n", "", nil
+			}
+		},
+	}
+
+	v := newFileStore(filepath.Join(t.TempDir(), "vcs.json"))
+	v.CmdBuilder = &exe.MockBuilder{Runner: runner}
+	v.TagTracking = &TagTracking{Packages: map[string]string{"yay-git": "v*"}}
+
+	found := v.UpdateFromCheckouts(context.Background(), "yay-git", []gosrc.ArchString{
+		{Value: "git+https://github.com/Jguer/yay.git"},
+		{Value: "git+https://github.com/Jguer/aur.git#branch=next"},
+		{Value: "git+https://github.com/Jguer/go-alpm#tag=v2.0"},
+		{Value: "git+https://github.com/Jguer/missing.git"},
+		{Value: "https://example.org/file.tar.gz"},
+	}, pkgBuildDir, "")
+	require.True(t, found)
+
+	assert.Equal(t, OriginInfoByURL{
+		"github.com/Jguer/yay.git": {Protocols: []string{"https"}, Branch: "HEAD", SHA: "aaaa", VCS: VCSGit},
+		"github.com/Jguer/aur.git": {Protocols: []string{"https"}, Branch: "next", SHA: "bbbb", VCS: VCSGit},
+		"github.com/Jguer/go-alpm": {
+			Protocols: []string{"https"}, SHA: "cccc", VCS: VCSGit,
+			TagPattern: "v*", Tag: "v2.0",
+		},
+	}, v.OriginsByPackage["yay-git"])
+
+	var mirrorArgs []string
+	for _, call := range runner.CaptureCalls {
+		if cmd := call.Args[0].(*exec.Cmd); strings.HasSuffix(cmd.Dir, "aur") {
+			mirrorArgs = cmd.Args[1:]
+		}
+	}
+
+	assert.Equal(t, []string{"rev-parse", "--verify", "--quiet", "next^{commit}"}, mirrorArgs)
+
+	assert.False(t, v.UpdateFromCheckouts(context.Background(), "other-git", []gosrc.ArchString{
+		{Value: "git+https://github.com/Jguer/missing.git"},
+	}, pkgBuildDir, ""))
+}
+
+func TestFossilInfoHash(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "5f3b1c2d7e9a", fossilInfoHash(
+		"project-name: Fossil\ncheckout:     5f3b1c2d7e9a 2023-05-01 12:00:00 UTC\ntags:         trunk\n"))
+	assert.Equal(t, "abcdef012345", fossilInfoHash("hash:         abcdef012345 2023-05-01 12:00:00 UTC\n"))
+	assert.Empty(t, fossilInfoHash("project-name: Fossil\n"))
+}
+
+func TestInfoStore_UpdateFromCheckoutsFossil(t *testing.T) {
+	t.Parallel()
+
+	pkgBuildDir := t.TempDir()
+	srcDest := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(pkgBuildDir, "src", "home"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(srcDest, "home.fossil"), []byte{}, 0o644))
+
+	runner := &exe.MockRunner{
+		CaptureFn: func(cmd *exec.Cmd) (string, string, error) {
+			if cmd.Dir != "" {
+				return "checkout:     5f3b1c2d7e9a 2023-05-01 12:00:00 UTC\n", "", nil
+			}
+
+			return "hash:         abcdef012345 2023-05-01 12:00:00 UTC\n", "", nil
+		},
+	}
+
+	v := newFileStore(filepath.Join(t.TempDir(), "vcs.json"))
+	v.CmdBuilder = &exe.MockBuilder{Runner: runner}
+
+	require.True(t, v.UpdateFromCheckouts(context.Background(), "fossil-git", []gosrc.ArchString{
+		{Value: "fossil+https://fossil-scm.org/home"},
+	}, pkgBuildDir, srcDest))
+
+	assert.Equal(t, OriginInfoByURL{
+		"fossil-scm.org/home": {Protocols: []string{"https"}, Branch: "trunk", SHA: "5f3b1c2d7e9a", VCS: VCSFossil},
+	}, v.OriginsByPackage["fossil-git"])
+	assert.Equal(t, []string{"fossil", "info"}, runner.CaptureCalls[0].Args[0].(*exec.Cmd).Args)
+}
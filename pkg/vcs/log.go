@@ -0,0 +1,124 @@
+package vcs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// fetchDepth is the number of commits fetched to build a commit log.
+	fetchDepth = 100
+	// fetchTimeout bounds the shallow fetch of a single origin.
+	fetchTimeout = 60 * time.Second
+)
+
+var cloneNameInvalid = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// CommitLog summarizes the commits between the installed and the remote head
+// of an origin.
+type CommitLog struct {
+	URL string
+	// Count is the number of new commits.
+	Count int
+	// Partial is set when the installed commit is older than the fetched
+	// history, in which case Count is a lower bound.
+	Partial bool
+	// Subjects holds the subjects of the most recent commits, newest first.
+	Subjects []string
+}
+
+// CommitLogs fetches the new commits of the git origins of a package into a
+// shallow cache clone and returns up to limit subjects for each of them.
+func (v *InfoStore) CommitLogs(ctx context.Context, pkgName string, limit int) []CommitLog {
+	logs := make([]CommitLog, 0)
+
+	if v.CloneDir == "" {
+		return logs
+	}
+
+	v.mux.Lock()
+	origins := make(OriginInfoByURL, len(v.OriginsByPackage[pkgName]))
+
+	for url, info := range v.OriginsByPackage[pkgName] {
+		origins[url] = info
+	}
+	v.mux.Unlock()
+
+	for url, info := range origins {
+		if (info.VCS != "" && info.VCS != VCSGit) || info.TagPattern != "" || len(info.Protocols) == 0 {
+			continue
+		}
+
+		if log, ok := v.commitLog(ctx, url, info, limit); ok {
+			logs = append(logs, log)
+		}
+	}
+
+	return logs
+}
+
+func (v *InfoStore) commitLog(ctx context.Context, url string, info OriginInfo, limit int) (CommitLog, bool) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	dir := filepath.Join(v.CloneDir, cloneNameInvalid.ReplaceAllString(url, "_"))
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		if !v.runGit(ctxTimeout, "", "init", "--quiet", "--bare", dir) {
+			return CommitLog{}, false
+		}
+	}
+
+	remote := info.Protocols[len(info.Protocols)-1] + "://" + url
+	if !v.runGit(ctxTimeout, dir, "fetch", "--quiet", "--no-tags",
+		"--depth="+strconv.Itoa(fetchDepth), remote, info.Branch) {
+		return CommitLog{}, false
+	}
+
+	log := CommitLog{URL: url}
+	commitRange := info.SHA + "..FETCH_HEAD"
+
+	// The installed commit is unknown when it is older than the fetched depth.
+	if _, _, err := v.CmdBuilder.Capture(
+		v.CmdBuilder.BuildGitCmd(ctxTimeout, dir, "cat-file", "-e", info.SHA+"^{commit}")); err != nil {
+		log.Partial = true
+		commitRange = "FETCH_HEAD"
+	}
+
+	stdout, _, err := v.CmdBuilder.Capture(
+		v.CmdBuilder.BuildGitCmd(ctxTimeout, dir, "rev-list", "--count", commitRange))
+	if err != nil {
+		return CommitLog{}, false
+	}
+
+	log.Count, _ = strconv.Atoi(strings.TrimSpace(stdout))
+
+	stdout, _, err = v.CmdBuilder.Capture(
+		v.CmdBuilder.BuildGitCmd(ctxTimeout, dir, "log", "--format=%s", "-n", strconv.Itoa(limit), commitRange))
+	if err != nil {
+		return CommitLog{}, false
+	}
+
+	for _, subject := range strings.Split(stdout, "\n") {
+		if subject != "" {
+			log.Subjects = append(log.Subjects, subject)
+		}
+	}
+
+	return log, true
+}
+
+// runGit runs a git command, logging its failure at debug level.
+func (v *InfoStore) runGit(ctx context.Context, dir string, args ...string) bool {
+	cmd := v.CmdBuilder.BuildGitCmd(ctx, dir, args...)
+	if _, stderr, err := v.CmdBuilder.Capture(cmd); err != nil {
+		v.logger.Debugln("commit log:", cmd.String(), stderr, err)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,107 @@
+//go:build !integration
+// +build !integration
+
+package vcs
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yay/v12/pkg/settings/exe"
+)
+
+func newLogStore(t *testing.T, knownCommit bool) (*InfoStore, *exe.MockRunner) {
+	t.Helper()
+
+	runner := &exe.MockRunner{
+		CaptureFn: func(cmd *exec.Cmd) (string, string, error) {
+			args := strings.Join(cmd.Args[1:], " ")
+			switch {
+			case strings.HasPrefix(args, "cat-file"):
+				if !knownCommit {
+					return "", "fatal: Not a valid object name", errors.New("exit status 128")
+				}
+			case strings.HasPrefix(args, "rev-list"):
+				if knownCommit {
+					return "3\n", "", nil
+				}
+				return "100\n", "", nil
+			case strings.HasPrefix(args, "log"):
+				return "Fix crash on startup\nUpdate translations\n", "", nil
+			}
+
+			return "", "", nil
+		},
+	}
+
+	store := &InfoStore{
+		OriginsByPackage: map[string]OriginInfoByURL{
+			"yay-git": {
+				"github.com/Jguer/yay.git": {
+					Protocols: []string{"https"},
+					Branch:    "HEAD",
+					SHA:       "991c5b4146fd27f4aacf4e3111258a848934aaa1",
+					VCS:       VCSGit,
+				},
+				"hg.example.org/foo": {Protocols: []string{"https"}, Branch: "default", VCS: VCSHg},
+			},
+		},
+		CmdBuilder: &exe.MockBuilder{Runner: runner},
+		CloneDir:   t.TempDir(),
+		logger:     newTestLogger(),
+	}
+
+	return store, runner
+}
+
+func TestInfoStore_CommitLogs(t *testing.T) {
+	t.Parallel()
+
+	store, runner := newLogStore(t, true)
+
+	logs := store.CommitLogs(context.Background(), "yay-git", 2)
+	assert.Equal(t, []CommitLog{{
+		URL:      "github.com/Jguer/yay.git",
+		Count:    3,
+		Subjects: []string{"Fix crash on startup", "Update translations"},
+	}}, logs)
+
+	calls := make([][]string, 0, len(runner.CaptureCalls))
+	for _, call := range runner.CaptureCalls {
+		calls = append(calls, call.Args[0].(*exec.Cmd).Args)
+	}
+
+	dir := filepath.Join(store.CloneDir, "github.com_Jguer_yay.git")
+	require.Len(t, calls, 5)
+	assert.Equal(t, []string{"git", "init", "--quiet", "--bare", dir}, calls[0])
+	assert.Equal(t, []string{
+		"git", "fetch", "--quiet", "--no-tags", "--depth=100",
+		"https://github.com/Jguer/yay.git", "HEAD",
+	}, calls[1])
+	assert.Equal(t, []string{"git", "rev-list", "--count", "991c5b4146fd27f4aacf4e3111258a848934aaa1..FETCH_HEAD"}, calls[3])
+	assert.Equal(t, []string{
+		"git", "log", "--format=%s", "-n", "2",
+		"991c5b4146fd27f4aacf4e3111258a848934aaa1..FETCH_HEAD",
+	}, calls[4])
+}
+
+func TestInfoStore_CommitLogsPartial(t *testing.T) {
+	t.Parallel()
+
+	store, _ := newLogStore(t, false)
+
+	logs := store.CommitLogs(context.Background(), "yay-git", 2)
+	require.Len(t, logs, 1)
+	assert.True(t, logs[0].Partial)
+	assert.Equal(t, 100, logs[0].Count)
+
+	store.CloneDir = ""
+	assert.Empty(t, store.CommitLogs(context.Background(), "yay-git", 2))
+}
@@ -10,6 +10,8 @@ import (
 type Mock struct {
 	OriginsByPackage map[string]OriginInfoByURL
 	ToUpgradeReturn  []string
+	NewTagReturn     map[string]string
+	CommitLogsReturn map[string][]CommitLog
 }
 
 func (m *Mock) ToUpgrade(ctx context.Context, pkgName string) bool {
@@ -21,9 +23,23 @@ func (m *Mock) ToUpgrade(ctx context.Context, pkgName string) bool {
 	return false
 }
 
+func (m *Mock) NewTag(ctx context.Context, pkgName string) string {
+	return m.NewTagReturn[pkgName]
+}
+
+func (m *Mock) CommitLogs(ctx context.Context, pkgName string, limit int) []CommitLog {
+	return m.CommitLogsReturn[pkgName]
+}
+
 func (m *Mock) Update(ctx context.Context, pkgName string, sources []gosrc.ArchString) {
 }
 
+func (m *Mock) UpdateFromCheckouts(ctx context.Context, pkgName string,
+	sources []gosrc.ArchString, pkgBuildDir, srcDest string,
+) bool {
+	return false
+}
+
 func (m *Mock) Save() error {
 	return nil
 }
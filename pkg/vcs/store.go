@@ -0,0 +1,244 @@
+package vcs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/leonelquinteros/gotext"
+)
+
+// SchemaVersion is the version of the vcs.json layout written by Save.
+//
+// Version 1 is the original layout, a bare object of packages. From version 2
+// on the packages are wrapped in an object holding the schema version.
+const SchemaVersion = 2
+
+// migrations upgrade a decoded store from version i+1 to version i+2.
+var migrations = []func(packages map[string]OriginInfoByURL){
+	migrateV1,
+}
+
+// migrateV1 marks the origins written before other VCS were tracked as git.
+func migrateV1(packages map[string]OriginInfoByURL) {
+	for _, origins := range packages {
+		for url, info := range origins {
+			if info.VCS == "" {
+				info.VCS = VCSGit
+				origins[url] = info
+			}
+		}
+	}
+}
+
+// storeFile is the layout of a vcs.json declaring its schema version.
+type storeFile struct {
+	Version  int                        `json:"version"`
+	Packages map[string]OriginInfoByURL `json:"packages"`
+}
+
+// lockFile takes a shared or exclusive lock on the lock file of the store,
+// which serializes access to vcs.json between yay processes.
+func (v *InfoStore) lockFile(how int) (unlock func(), err error) {
+	lock, err := os.OpenFile(v.FilePath+".lock", os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := syscall.Flock(int(lock.Fd()), how); err != nil {
+		lock.Close()
+		return nil, fmt.Errorf(gotext.Get("failed to lock vcs file '%s'", v.FilePath)+": %w", err)
+	}
+
+	return func() {
+		_ = syscall.Flock(int(lock.Fd()), syscall.LOCK_UN)
+		lock.Close()
+	}, nil
+}
+
+// readFile reads the store from disk and migrates it to SchemaVersion.
+// A missing or empty file is an empty store.
+func (v *InfoStore) readFile() (map[string]OriginInfoByURL, error) {
+	packages := map[string]OriginInfoByURL{}
+
+	content, err := os.ReadFile(v.FilePath)
+	if os.IsNotExist(err) || (err == nil && len(content) == 0) {
+		return packages, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to open vcs file '%s': %w", v.FilePath, err)
+	}
+
+	raw := map[string]json.RawMessage{}
+	if err := json.Unmarshal(content, &raw); err != nil {
+		return nil, fmt.Errorf("failed to read vcs '%s': %w", v.FilePath, err)
+	}
+
+	// Packages are objects, so a numeric version key is never a package.
+	version := 1
+	if rawVersion, ok := raw["version"]; !ok || json.Unmarshal(rawVersion, &version) != nil {
+		version = 1
+		if err := json.Unmarshal(content, &packages); err != nil {
+			return nil, fmt.Errorf("failed to read vcs '%s': %w", v.FilePath, err)
+		}
+	} else {
+		file := storeFile{Packages: packages}
+		if err := json.Unmarshal(content, &file); err != nil {
+			return nil, fmt.Errorf("failed to read vcs '%s': %w", v.FilePath, err)
+		}
+
+		packages = file.Packages
+	}
+
+	if version > SchemaVersion {
+		return nil, &ErrSchemaVersion{filePath: v.FilePath, version: version}
+	}
+
+	for ; version < SchemaVersion; version++ {
+		v.logger.Debugln("migrating vcs file to version", version+1)
+		migrations[version-1](packages)
+	}
+
+	if packages == nil {
+		packages = map[string]OriginInfoByURL{}
+	}
+
+	return packages, nil
+}
+
+// writeFile atomically replaces the store on disk.
+func (v *InfoStore) writeFile(packages map[string]OriginInfoByURL) error {
+	marshalledinfo, err := json.MarshalIndent(storeFile{
+		Version:  SchemaVersion,
+		Packages: packages,
+	}, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(v.FilePath), filepath.Base(v.FilePath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if _, err := tmp.Write(marshalledinfo); err != nil {
+		return err
+	}
+
+	if err := tmp.Chmod(0o644); err != nil {
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), v.FilePath)
+}
+
+// markChanged records that the origins of a package were changed by this
+// process and take precedence over the ones on disk on Save.
+func (v *InfoStore) markChanged(pkgName string) {
+	if v.changed == nil {
+		v.changed = mapset.NewThreadUnsafeSet[string]()
+	}
+
+	v.changed.Add(pkgName)
+}
+
+// Save writes the VCS info to disk. Packages changed by other yay processes
+// since Load are merged in, unless this process changed them too.
+func (v *InfoStore) Save() error {
+	v.mux.Lock()
+	defer v.mux.Unlock()
+
+	return v.save()
+}
+
+// save is Save for callers holding the mutex.
+func (v *InfoStore) save() error {
+	if v.OriginsByPackage == nil {
+		return nil
+	}
+
+	unlock, err := v.lockFile(syscall.LOCK_EX)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	onDisk, err := v.readFile()
+	if err != nil {
+		return err
+	}
+
+	for pkgName, origins := range onDisk {
+		if v.changed == nil || !v.changed.Contains(pkgName) {
+			v.OriginsByPackage[pkgName] = origins
+		}
+	}
+
+	for pkgName := range v.OriginsByPackage {
+		_, ok := onDisk[pkgName]
+		removedElsewhere := !ok && v.loaded != nil && v.loaded.Contains(pkgName) &&
+			(v.changed == nil || !v.changed.Contains(pkgName))
+
+		if removedElsewhere {
+			delete(v.OriginsByPackage, pkgName)
+		}
+	}
+
+	if err := v.writeFile(v.OriginsByPackage); err != nil {
+		return err
+	}
+
+	v.changed = nil
+	v.loaded = mapset.NewThreadUnsafeSet[string]()
+
+	for pkgName := range v.OriginsByPackage {
+		v.loaded.Add(pkgName)
+	}
+
+	return nil
+}
+
+// Load reads the VCS info from disk.
+func (v *InfoStore) Load() error {
+	unlock, err := v.lockFile(syscall.LOCK_SH)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	packages, err := v.readFile()
+	if err != nil {
+		return err
+	}
+
+	v.mux.Lock()
+	defer v.mux.Unlock()
+
+	if v.OriginsByPackage == nil {
+		v.OriginsByPackage = map[string]OriginInfoByURL{}
+	}
+
+	v.loaded = mapset.NewThreadUnsafeSet[string]()
+
+	for pkgName, origins := range packages {
+		v.OriginsByPackage[pkgName] = origins
+		v.loaded.Add(pkgName)
+	}
+
+	return nil
+}
@@ -0,0 +1,184 @@
+//go:build !integration
+// +build !integration
+
+package vcs
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yay/v12/pkg/settings/exe"
+)
+
+func newFileStore(filePath string) *InfoStore {
+	return &InfoStore{
+		OriginsByPackage: map[string]OriginInfoByURL{},
+		FilePath:         filePath,
+		logger:           newTestLogger(),
+	}
+}
+
+func TestInfoStore_LoadMigratesV1(t *testing.T) {
+	t.Parallel()
+
+	filePath := filepath.Join(t.TempDir(), "vcs.json")
+	require.NoError(t, os.WriteFile(filePath, []byte(`{
+	"yay-git": {
+		"github.com/Jguer/yay.git": {"protocols": ["https"], "branch": "HEAD", "sha": "aaaa"}
+	}
+}`), 0o644))
+
+	v := newFileStore(filePath)
+	require.NoError(t, v.Load())
+	assert.Equal(t, VCSGit, v.OriginsByPackage["yay-git"]["github.com/Jguer/yay.git"].VCS)
+
+	require.NoError(t, v.Save())
+
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+
+	// the migrated file declares its version and is not migrated again
+	file := storeFile{}
+	require.NoError(t, json.Unmarshal(content, &file))
+	assert.Equal(t, SchemaVersion, file.Version)
+	assert.Equal(t, VCSGit, file.Packages["yay-git"]["github.com/Jguer/yay.git"].VCS)
+
+	reloaded := newFileStore(filePath)
+	require.NoError(t, reloaded.Load())
+	assert.Equal(t, v.OriginsByPackage, reloaded.OriginsByPackage)
+}
+
+func TestInfoStore_LoadNewerVersion(t *testing.T) {
+	t.Parallel()
+
+	filePath := filepath.Join(t.TempDir(), "vcs.json")
+	require.NoError(t, os.WriteFile(filePath, []byte(`{"version": 99, "packages": {}}`), 0o644))
+
+	v := newFileStore(filePath)
+	err := v.Load()
+	require.Error(t, err)
+	assert.IsType(t, &ErrSchemaVersion{}, err)
+	assert.IsType(t, &ErrSchemaVersion{}, v.Save())
+}
+
+func TestInfoStore_SaveMergesConcurrentWriters(t *testing.T) {
+	t.Parallel()
+
+	filePath := filepath.Join(t.TempDir(), "vcs.json")
+	origin := OriginInfoByURL{"github.com/Jguer/yay.git": {Protocols: []string{"https"}, SHA: "aaaa", VCS: VCSGit}}
+
+	seed := newFileStore(filePath)
+	seed.OriginsByPackage["kept"] = origin
+	seed.OriginsByPackage["removed"] = origin
+	seed.markChanged("kept")
+	seed.markChanged("removed")
+	require.NoError(t, seed.Save())
+
+	first := newFileStore(filePath)
+	second := newFileStore(filePath)
+	require.NoError(t, first.Load())
+	require.NoError(t, second.Load())
+
+	first.OriginsByPackage["first"] = origin
+	first.markChanged("first")
+	require.NoError(t, first.Save())
+
+	second.OriginsByPackage["second"] = origin
+	second.markChanged("second")
+	second.RemovePackages([]string{"removed"})
+
+	// a later save of the first process keeps the changes of the second
+	require.NoError(t, first.Save())
+
+	final := newFileStore(filePath)
+	require.NoError(t, final.Load())
+	assert.ElementsMatch(t, []string{"kept", "first", "second"}, keys(final.OriginsByPackage))
+	assert.ElementsMatch(t, []string{"kept", "first", "second"}, keys(first.OriginsByPackage))
+}
+
+func TestInfoStore_CheckTTL(t *testing.T) {
+	t.Parallel()
+
+	runner := &exe.MockRunner{
+		CaptureFn: func(cmd *exec.Cmd) (string, string, error) {
+			return "bbbb\tHEAD\n", "", nil
+		},
+	}
+
+	v := newFileStore(filepath.Join(t.TempDir(), "vcs.json"))
+	v.CmdBuilder = &exe.MockBuilder{Runner: runner}
+	v.CheckTTL = time.Hour
+	v.OriginsByPackage["yay-git"] = OriginInfoByURL{
+		"github.com/Jguer/yay.git": {Protocols: []string{"https"}, Branch: "HEAD", SHA: "aaaa", VCS: VCSGit},
+	}
+
+	assert.True(t, v.ToUpgrade(context.Background(), "yay-git"))
+	require.Len(t, runner.CaptureCalls, 1)
+
+	info := v.OriginsByPackage["yay-git"]["github.com/Jguer/yay.git"]
+	assert.Equal(t, "bbbb", info.Head)
+	assert.NotZero(t, info.Checked)
+
+	// the heads are only written by Save
+	_, err := os.Stat(v.FilePath)
+	assert.True(t, os.IsNotExist(err))
+
+	// within the TTL the cached head is used
+	assert.True(t, v.ToUpgrade(context.Background(), "yay-git"))
+	assert.Len(t, runner.CaptureCalls, 1)
+
+	// once expired the remote is checked again
+	info.Checked = time.Now().Add(-2 * time.Hour).Unix()
+	v.OriginsByPackage["yay-git"]["github.com/Jguer/yay.git"] = info
+
+	assert.True(t, v.ToUpgrade(context.Background(), "yay-git"))
+	assert.Len(t, runner.CaptureCalls, 2)
+
+	require.NoError(t, v.Save())
+
+	reloaded := newFileStore(v.FilePath)
+	require.NoError(t, reloaded.Load())
+	assert.Equal(t, "bbbb", reloaded.OriginsByPackage["yay-git"]["github.com/Jguer/yay.git"].Head)
+}
+
+func TestInfoStore_CheckTTLStampsEveryOrigin(t *testing.T) {
+	t.Parallel()
+
+	runner := &exe.MockRunner{
+		CaptureFn: func(cmd *exec.Cmd) (string, string, error) {
+			return "bbbb\tHEAD\n", "", nil
+		},
+	}
+
+	v := newFileStore(filepath.Join(t.TempDir(), "vcs.json"))
+	v.CmdBuilder = &exe.MockBuilder{Runner: runner}
+	v.CheckTTL = time.Hour
+	v.OriginsByPackage["yay-git"] = OriginInfoByURL{
+		"github.com/Jguer/yay.git":     {Protocols: []string{"https"}, Branch: "HEAD", SHA: "aaaa", VCS: VCSGit},
+		"github.com/Jguer/go-alpm.git": {Protocols: []string{"https"}, Branch: "HEAD", SHA: "bbbb", VCS: VCSGit},
+	}
+
+	assert.True(t, v.ToUpgrade(context.Background(), "yay-git"))
+
+	// an update found early does not leave other checks running
+	for url, info := range v.OriginsByPackage["yay-git"] {
+		assert.Equal(t, "bbbb", info.Head, url)
+	}
+}
+
+func keys(m map[string]OriginInfoByURL) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+
+	return names
+}
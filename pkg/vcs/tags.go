@@ -0,0 +1,210 @@
+package vcs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yay/v12/pkg/db"
+	"github.com/Jguer/yay/v12/pkg/text"
+)
+
+// TagTracking selects the packages whose git #tag= sources follow the newest
+// remote tag instead of being treated as pinned.
+type TagTracking struct {
+	// All enables tag tracking for every package.
+	All bool
+	// Pattern is the glob matched against tag names when a package has no
+	// pattern of its own.
+	Pattern string
+	// Packages enables tag tracking for single packages, mapping them to
+	// their tag pattern. An empty pattern uses Pattern.
+	Packages map[string]string
+}
+
+// pattern returns the tag pattern of a package and whether it is tracked.
+func (t *TagTracking) pattern(pkgName string) (string, bool) {
+	if t == nil {
+		return "", false
+	}
+
+	pattern, ok := t.Packages[pkgName]
+	if !ok && !t.All {
+		return "", false
+	}
+
+	if pattern == "" {
+		pattern = t.Pattern
+	}
+
+	if pattern == "" {
+		pattern = "*"
+	}
+
+	return pattern, true
+}
+
+// parseTagSource returns the url, protocols and tag of a git source pinned
+// to a tag.
+func parseTagSource(source string) (url string, protocols []string, tag string) {
+	vcsType, url, protocols, fragKey, tag := splitSource(source)
+	if vcsType != VCSGit || fragKey != "tag" {
+		return "", nil, ""
+	}
+
+	return url, protocols, tag
+}
+
+// tagVersion strips the prefix of a tag up to its first digit, so that
+// "v1.2" and "release-1.2" sort as "1.2". It returns "" if the tag has
+// no digits.
+func tagVersion(tag string) string {
+	if i := strings.IndexAny(tag, "0123456789"); i >= 0 {
+		return tag[i:]
+	}
+
+	return ""
+}
+
+// newerTag reports whether tag a is a newer version than tag b.
+func newerTag(a, b string) bool {
+	return db.VerCmp(tagVersion(a), tagVersion(b)) > 0
+}
+
+// getNewestTag returns the newest remote tag matching pattern and its commit.
+func (v *InfoStore) getNewestTag(ctx context.Context, url, pattern string, protocols []string) (tag, commit string) {
+	if len(protocols) == 0 {
+		return "", ""
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	cmd := v.CmdBuilder.BuildGitCmd(ctxTimeout, "", "ls-remote", "--tags", "--refs",
+		protocols[len(protocols)-1]+"://"+url)
+
+	stdout, stderr, err := v.CmdBuilder.Capture(cmd)
+	if err != nil {
+		v.logger.Warnln(gotext.Get("devel check for package failed: '%s' encountered an error", cmd.String()), ": ", stderr, err)
+		return "", ""
+	}
+
+	for _, line := range strings.Split(stdout, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		name := strings.TrimPrefix(fields[1], "refs/tags/")
+		if matched, _ := path.Match(pattern, name); !matched || tagVersion(name) == "" {
+			continue
+		}
+
+		if tag == "" || newerTag(name, tag) {
+			tag, commit = name, fields[0]
+		}
+	}
+
+	return tag, commit
+}
+
+// getTagCommit returns the commit a remote tag points to.
+func (v *InfoStore) getTagCommit(ctx context.Context, url, tag string, protocols []string) string {
+	if len(protocols) == 0 {
+		return ""
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	cmd := v.CmdBuilder.BuildGitCmd(ctxTimeout, "", "ls-remote", "--tags", "--refs",
+		protocols[len(protocols)-1]+"://"+url, "refs/tags/"+tag)
+
+	stdout, stderr, err := v.CmdBuilder.Capture(cmd)
+	if err != nil {
+		v.logger.Warnln(gotext.Get("devel check for package failed: '%s' encountered an error", cmd.String()), ": ", stderr, err)
+		return ""
+	}
+
+	for _, line := range strings.Split(stdout, "\n") {
+		if fields := strings.Fields(line); len(fields) >= 2 && fields[1] == "refs/tags/"+tag {
+			return fields[0]
+		}
+	}
+
+	return ""
+}
+
+// updateTag records the tag a #tag= source was built from for packages with
+// tag tracking enabled, along with the newest remote tag, so that NewTag only
+// reports tags newer than both.
+func (v *InfoStore) updateTag(ctx context.Context, pkgName, source string, info OriginInfoByURL) {
+	pattern, ok := v.TagTracking.pattern(pkgName)
+	if !ok {
+		return
+	}
+
+	url, protocols, tag := parseTagSource(source)
+	if url == "" || tag == "" {
+		return
+	}
+
+	commit := v.getTagCommit(ctx, url, tag, protocols)
+
+	newest, _ := v.getNewestTag(ctx, url, pattern, protocols)
+	if !newerTag(newest, tag) {
+		newest = ""
+	}
+
+	v.mux.Lock()
+	defer v.mux.Unlock()
+
+	info[url] = OriginInfo{
+		Protocols:  protocols,
+		SHA:        commit,
+		VCS:        VCSGit,
+		TagPattern: pattern,
+		Tag:        tag,
+		NewestTag:  newest,
+	}
+
+	v.OriginsByPackage[pkgName] = info
+	v.markChanged(pkgName)
+
+	v.logger.Debugln(gotext.Get("Tracking tags of git repo: %s", text.Cyan(url)))
+
+	if err := v.save(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
+
+// NewTag returns the newest remote tag of a tag tracked origin of the
+// package if it is newer than the tag it was built from and than the newest
+// tag known when it was built.
+func (v *InfoStore) NewTag(ctx context.Context, pkgName string) string {
+	if _, ok := v.TagTracking.pattern(pkgName); !ok {
+		return ""
+	}
+
+	for url, info := range v.OriginsByPackage[pkgName] {
+		if info.TagPattern == "" {
+			continue
+		}
+
+		known := info.Tag
+		if info.NewestTag != "" && newerTag(info.NewestTag, known) {
+			known = info.NewestTag
+		}
+
+		tag, _ := v.getNewestTag(ctx, url, info.TagPattern, info.Protocols)
+		if tag != "" && newerTag(tag, known) {
+			return tag
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,173 @@
+//go:build !integration
+// +build !integration
+
+package vcs
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	gosrc "github.com/Morganamilo/go-srcinfo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yay/v12/pkg/settings/exe"
+)
+
+const lsRemoteTags = `1111111111111111111111111111111111111111	refs/tags/v1.9
+2222222222222222222222222222222222222222	refs/tags/v1.10
+3333333333333333333333333333333333333333	refs/tags/v2.0-rc1
+4444444444444444444444444444444444444444	refs/tags/nightly
+5555555555555555555555555555555555555555	refs/tags/release-1.8
+`
+
+func TestTagTracking_pattern(t *testing.T) {
+	t.Parallel()
+
+	var disabled *TagTracking
+	_, ok := disabled.pattern("foo")
+	assert.False(t, ok)
+
+	tracking := &TagTracking{Pattern: "v*", Packages: map[string]string{"foo": "", "bar": "release-*"}}
+
+	pattern, ok := tracking.pattern("foo")
+	assert.True(t, ok)
+	assert.Equal(t, "v*", pattern)
+
+	pattern, ok = tracking.pattern("bar")
+	assert.True(t, ok)
+	assert.Equal(t, "release-*", pattern)
+
+	_, ok = tracking.pattern("baz")
+	assert.False(t, ok)
+
+	tracking.All = true
+	pattern, ok = tracking.pattern("baz")
+	assert.True(t, ok)
+	assert.Equal(t, "v*", pattern)
+}
+
+func TestParseTagSource(t *testing.T) {
+	t.Parallel()
+
+	url, protocols, tag := parseTagSource("foo::git+https://github.com/foo/foo.git#tag=v1.0?signed")
+	assert.Equal(t, "github.com/foo/foo.git", url)
+	assert.Equal(t, []string{"https"}, protocols)
+	assert.Equal(t, "v1.0", tag)
+
+	url, _, _ = parseTagSource("git+https://github.com/foo/foo.git#commit=abc")
+	assert.Empty(t, url)
+
+	url, _, _ = parseTagSource("hg+https://hg.example.org/foo#tag=1.0")
+	assert.Empty(t, url)
+}
+
+func newTagStore(t *testing.T, tracking *TagTracking) (*InfoStore, *exe.MockRunner) {
+	t.Helper()
+
+	runner := &exe.MockRunner{
+		CaptureFn: func(cmd *exec.Cmd) (string, string, error) {
+			return lsRemoteTags, "", nil
+		},
+	}
+
+	return &InfoStore{
+		OriginsByPackage: map[string]OriginInfoByURL{},
+		FilePath:         filepath.Join(t.TempDir(), "vcs.json"),
+		CmdBuilder:       &exe.MockBuilder{Runner: runner},
+		TagTracking:      tracking,
+		logger:           newTestLogger(),
+	}, runner
+}
+
+func TestInfoStore_getNewestTag(t *testing.T) {
+	t.Parallel()
+
+	v, runner := newTagStore(t, nil)
+
+	tag, commit := v.getNewestTag(context.Background(), "github.com/foo/foo.git", "v*", []string{"https"})
+	assert.Equal(t, "v2.0-rc1", tag)
+	assert.Equal(t, "3333333333333333333333333333333333333333", commit)
+	assert.Equal(t, []string{"git", "ls-remote", "--tags", "--refs", "https://github.com/foo/foo.git"},
+		runner.CaptureCalls[0].Args[0].(*exec.Cmd).Args)
+
+	tag, _ = v.getNewestTag(context.Background(), "github.com/foo/foo.git", "v1.*", []string{"https"})
+	assert.Equal(t, "v1.10", tag)
+
+	tag, _ = v.getNewestTag(context.Background(), "github.com/foo/foo.git", "nightly", []string{"https"})
+	assert.Empty(t, tag, "tags without a version are never picked")
+}
+
+func TestInfoStore_UpdateTag(t *testing.T) {
+	t.Parallel()
+
+	sources := []gosrc.ArchString{{Value: "git+https://github.com/foo/foo.git#tag=v1.9"}}
+
+	v, _ := newTagStore(t, nil)
+	v.Update(context.Background(), "foo", sources)
+	assert.Empty(t, v.OriginsByPackage, "tag sources are pinned without tag tracking")
+
+	v, runner := newTagStore(t, &TagTracking{Packages: map[string]string{"foo": "v1.*"}})
+	v.Update(context.Background(), "foo", sources)
+	assert.Equal(t, OriginInfoByURL{
+		"github.com/foo/foo.git": {
+			Protocols:  []string{"https"},
+			SHA:        "1111111111111111111111111111111111111111",
+			VCS:        VCSGit,
+			TagPattern: "v1.*",
+			Tag:        "v1.9",
+			NewestTag:  "v1.10",
+		},
+	}, v.OriginsByPackage["foo"], "the built tag is recorded, not the newest one")
+	assert.Equal(t, []string{
+		"git", "ls-remote", "--tags", "--refs", "https://github.com/foo/foo.git", "refs/tags/v1.9",
+	}, runner.CaptureCalls[0].Args[0].(*exec.Cmd).Args)
+
+	_, err := os.Stat(v.FilePath)
+	require.NoError(t, err)
+}
+
+func TestInfoStore_NewTag(t *testing.T) {
+	t.Parallel()
+
+	v, _ := newTagStore(t, &TagTracking{All: true})
+	v.OriginsByPackage["foo"] = OriginInfoByURL{
+		"github.com/foo/foo.git": {Protocols: []string{"https"}, VCS: VCSGit, TagPattern: "v1.*", Tag: "v1.9"},
+	}
+	v.OriginsByPackage["bar"] = OriginInfoByURL{
+		"github.com/foo/foo.git": {Protocols: []string{"https"}, VCS: VCSGit, TagPattern: "v1.*", Tag: "v1.10"},
+	}
+
+	assert.Equal(t, "v1.10", v.NewTag(context.Background(), "foo"))
+	assert.Empty(t, v.NewTag(context.Background(), "bar"))
+	assert.False(t, v.ToUpgrade(context.Background(), "foo"), "tag origins are not branch heads")
+
+	v.TagTracking = nil
+	assert.Empty(t, v.NewTag(context.Background(), "foo"))
+}
+
+func TestInfoStore_NewTagAfterRebuild(t *testing.T) {
+	t.Parallel()
+
+	sources := []gosrc.ArchString{{Value: "git+https://github.com/foo/foo.git#tag=v1.9"}}
+
+	v, _ := newTagStore(t, &TagTracking{Packages: map[string]string{"foo": "v1.*"}})
+	v.OriginsByPackage["foo"] = OriginInfoByURL{
+		"github.com/foo/foo.git": {Protocols: []string{"https"}, VCS: VCSGit, TagPattern: "v1.*", Tag: "v1.9"},
+	}
+	require.Equal(t, "v1.10", v.NewTag(context.Background(), "foo"))
+
+	// the PKGBUILD still pins v1.9, so the rebuild cannot deliver v1.10
+	v.Update(context.Background(), "foo", sources)
+	assert.Empty(t, v.NewTag(context.Background(), "foo"), "the same tag is not offered twice")
+
+	v.CmdBuilder = &exe.MockBuilder{Runner: &exe.MockRunner{
+		CaptureFn: func(cmd *exec.Cmd) (string, string, error) {
+			return lsRemoteTags + "6666666666666666666666666666666666666666\trefs/tags/v1.11\n", "", nil
+		},
+	}}
+	assert.Equal(t, "v1.11", v.NewTag(context.Background(), "foo"), "a newer tag is offered again")
+}
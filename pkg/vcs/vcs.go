@@ -2,17 +2,21 @@ package vcs
 
 import (
 	"context"
-	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"fmt"
+	"net/http"
+	neturl "net/url"
 	"os"
 	"os/exec"
+	"regexp"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/Jguer/go-alpm/v2"
 	gosrc "github.com/Morganamilo/go-srcinfo"
+	mapset "github.com/deckarep/golang-set/v2"
 	"github.com/leonelquinteros/gotext"
 
 	"github.com/Jguer/yay/v12/pkg/settings/exe"
@@ -21,11 +25,44 @@ import (
 
 const defaultTimeout = 15 * time.Second
 
+// Version control systems supported by makepkg.
+const (
+	VCSGit    = "git"
+	VCSHg     = "hg"
+	VCSSvn    = "svn"
+	VCSBzr    = "bzr"
+	VCSFossil = "fossil"
+)
+
+// defaultBranches maps each supported VCS to the head followed when a source
+// does not specify a branch.
+var defaultBranches = map[string]string{
+	VCSGit:    "HEAD",
+	VCSHg:     "default",
+	VCSSvn:    "HEAD",
+	VCSBzr:    "HEAD",
+	VCSFossil: "trunk",
+}
+
+// branchFragments lists the VCS whose sources can follow a #branch= fragment.
+var branchFragments = mapset.NewThreadUnsafeSet(VCSGit, VCSHg, VCSFossil)
+
+// fossilHash matches a full or abbreviated fossil artifact hash.
+var fossilHash = regexp.MustCompile(`^[0-9a-f]{4,64}$`)
+
 type Store interface {
-	// ToUpgrade returns true if the package needs to be updated.
+	// ToUpgrade returns true if the package needs to be updated. The remote
+	// heads it records are persisted by Save.
 	ToUpgrade(ctx context.Context, pkgName string) bool
+	// NewTag returns the newest tracked tag of a package if it changed.
+	NewTag(ctx context.Context, pkgName string) string
+	// CommitLogs returns the commits a devel upgrade of the package brings.
+	CommitLogs(ctx context.Context, pkgName string, limit int) []CommitLog
 	// Update updates the VCS info of a package.
 	Update(ctx context.Context, pkgName string, sources []gosrc.ArchString)
+	// UpdateFromCheckouts updates the VCS info of a package from its local checkouts.
+	UpdateFromCheckouts(ctx context.Context, pkgName string, sources []gosrc.ArchString,
+		pkgBuildDir, srcDest string) bool
 	// RemovePackages removes the VCS info of the packages given as arg if they exist.
 	RemovePackages(pkgs []string)
 	// Clean orphaned VCS info.
@@ -41,9 +78,19 @@ type Store interface {
 type InfoStore struct {
 	OriginsByPackage map[string]OriginInfoByURL
 	FilePath         string
-	CmdBuilder       exe.GitCmdBuilder
-	mux              sync.Mutex
-	logger           *text.Logger
+	CmdBuilder       exe.VCSCmdBuilder
+	HTTPClient       *http.Client
+	TagTracking      *TagTracking
+	CloneDir         string
+	// CheckTTL is how long the remote head of an origin is reused before it
+	// is checked again. Zero checks on every run.
+	CheckTTL time.Duration
+	mux      sync.Mutex
+	logger   *text.Logger
+	// changed and loaded track the packages changed by this process and the
+	// ones read by Load, to merge with concurrent writers on Save.
+	changed mapset.Set[string]
+	loaded  mapset.Set[string]
 }
 
 // OriginInfoByURL stores the OriginInfo of each origin URL provided.
@@ -57,21 +104,59 @@ type OriginInfoByURL map[string]OriginInfo
 //			"https"
 //		],
 //		"branch": "next",
-//		"sha": "c1171d41467c68ffd3c46748182a16366aaaf87b"
+//		"sha": "c1171d41467c68ffd3c46748182a16366aaaf87b",
+//		"vcs": "git"
 //	}.
+//
+// Entries without a VCS were written by older versions and are git origins.
+// Origins following tags instead of a branch store the tag pattern and the
+// newest matching tag. With a CheckTTL the last seen remote head and the unix
+// time it was checked at are kept as well.
 type OriginInfo struct {
-	Protocols []string `json:"protocols"`
-	Branch    string   `json:"branch"`
-	SHA       string   `json:"sha"`
+	Protocols  []string `json:"protocols"`
+	Branch     string   `json:"branch"`
+	SHA        string   `json:"sha"`
+	VCS        string   `json:"vcs,omitempty"`
+	TagPattern string   `json:"tagpattern,omitempty"`
+	Tag        string   `json:"tag,omitempty"`
+	// NewestTag is the newest remote tag when the package was built. A
+	// PKGBUILD pinning an older tag cannot build it, so it is not offered
+	// again.
+	NewestTag string `json:"newesttag,omitempty"`
+	Head      string `json:"head,omitempty"`
+	Checked   int64  `json:"checked,omitempty"`
+}
+
+// stamp records the remote head of an origin if checks are cached.
+func (v *InfoStore) stamp(info *OriginInfo, head string) {
+	if v.CheckTTL > 0 {
+		info.Head = head
+		info.Checked = time.Now().Unix()
+	}
 }
 
-func NewInfoStore(filePath string, cmdBuilder exe.GitCmdBuilder,
+// cachedHead returns the remote head of an origin if it was checked within
+// CheckTTL.
+func (v *InfoStore) cachedHead(info OriginInfo) (string, bool) {
+	if v.CheckTTL <= 0 || info.Head == "" {
+		return "", false
+	}
+
+	if time.Since(time.Unix(info.Checked, 0)) >= v.CheckTTL {
+		return "", false
+	}
+
+	return info.Head, true
+}
+
+func NewInfoStore(filePath string, cmdBuilder exe.VCSCmdBuilder,
 	logger *text.Logger,
 ) *InfoStore {
 	infoStore := &InfoStore{
 		CmdBuilder:       cmdBuilder,
 		FilePath:         filePath,
 		OriginsByPackage: map[string]OriginInfoByURL{},
+		HTTPClient:       &http.Client{},
 		mux:              sync.Mutex{},
 		logger:           logger,
 	}
@@ -79,18 +164,38 @@ func NewInfoStore(filePath string, cmdBuilder exe.GitCmdBuilder,
 	return infoStore
 }
 
-// GetCommit parses HEAD commit from url and branch.
-func (v *InfoStore) getCommit(ctx context.Context, url, branch string, protocols []string) string {
+// getCommit returns the current head of the origin at url for the given branch.
+func (v *InfoStore) getCommit(ctx context.Context, vcsType, url, branch string, protocols []string) string {
 	if len(protocols) > 0 {
 		protocol := protocols[len(protocols)-1]
 
 		ctxTimeout, cancel := context.WithTimeout(ctx, defaultTimeout)
 		defer cancel()
 
-		cmd := v.CmdBuilder.BuildGitCmd(ctxTimeout, "", "ls-remote", protocol+"://"+url, branch)
+		remote := protocol + "://" + url
+
+		var cmd *exec.Cmd
+
+		switch vcsType {
+		case VCSHg:
+			cmd = v.CmdBuilder.BuildHgCmd(ctxTimeout, "", "identify", "--id", "-r", branch, remote)
+		case VCSSvn:
+			cmd = v.CmdBuilder.BuildSvnCmd(ctxTimeout, "", "info", "--show-item", "last-changed-revision", remote)
+		case VCSBzr:
+			cmd = v.CmdBuilder.BuildBzrCmd(ctxTimeout, "", "revno", remote)
+		case VCSFossil:
+			return v.getFossilCommit(ctxTimeout, remote, branch)
+		default:
+			cmd = v.CmdBuilder.BuildGitCmd(ctxTimeout, "", "ls-remote", remote, branch)
+		}
 
 		stdout, stderr, err := v.CmdBuilder.Capture(cmd)
 		if err != nil {
+			// checks cancelled once an update was found are not failures
+			if errors.Is(ctx.Err(), context.Canceled) {
+				return ""
+			}
+
 			exitError := &exec.ExitError{}
 			if ok := errors.As(err, &exitError); ok && exitError.ExitCode() == 128 {
 				v.logger.Warnln(gotext.Get("devel check for package failed: '%s' encountered an error", cmd.String()), ": ", stderr)
@@ -104,7 +209,9 @@ func (v *InfoStore) getCommit(ctx context.Context, url, branch string, protocols
 
 		split := strings.Fields(stdout)
 
-		if len(split) < 2 {
+		// git ls-remote prints the ref next to the commit, other VCS print
+		// the revision alone.
+		if len(split) == 0 || (len(split) < 2 && (vcsType == "" || vcsType == VCSGit)) {
 			return ""
 		}
 
@@ -116,34 +223,95 @@ func (v *InfoStore) getCommit(ctx context.Context, url, branch string, protocols
 	return ""
 }
 
+// getFossilCommit reads the latest check-in of a branch from the timeline
+// feed of a fossil server. Fossil has no command to query a remote without
+// cloning it first.
+func (v *InfoStore) getFossilCommit(ctx context.Context, remote, branch string) string {
+	feedURL := strings.TrimSuffix(remote, "/") + "/timeline.rss?y=ci&n=1&tag=" + neturl.QueryEscape(branch)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, http.NoBody)
+	if err != nil {
+		return ""
+	}
+
+	client := v.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.Canceled) {
+			return ""
+		}
+
+		v.logger.Warnln(gotext.Get("devel check for package failed: '%s' encountered an error", feedURL), ": ", err)
+		return ""
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		v.logger.Warnln(gotext.Get("devel check for package failed: '%s' encountered an error", feedURL), ": ", resp.Status)
+		return ""
+	}
+
+	feed := struct {
+		Items []struct {
+			Link string `xml:"link"`
+		} `xml:"channel>item"`
+	}{}
+
+	if err := xml.NewDecoder(resp.Body).Decode(&feed); err != nil || len(feed.Items) == 0 {
+		return ""
+	}
+
+	// Check-in links end in /info/<hash>
+	link := strings.TrimSpace(feed.Items[0].Link)
+
+	hash := link[strings.LastIndex(link, "/")+1:]
+	if !fossilHash.MatchString(hash) {
+		v.logger.Warnln(gotext.Get("devel check for package failed: '%s' encountered an error", feedURL), ": ",
+			gotext.Get("unexpected check-in link '%s'", link))
+		return ""
+	}
+
+	return hash
+}
+
 func (v *InfoStore) Update(ctx context.Context, pkgName string, sources []gosrc.ArchString) {
 	var wg sync.WaitGroup
 	info := make(OriginInfoByURL)
 	checkSource := func(source gosrc.ArchString) {
 		defer wg.Done()
 
-		url, branch, protocols := parseSource(source.Value)
+		vcsType, url, branch, protocols := parseSource(source.Value)
 		if url == "" || branch == "" {
+			v.updateTag(ctx, pkgName, source.Value, info)
 			return
 		}
 
-		commit := v.getCommit(ctx, url, branch, protocols)
+		commit := v.getCommit(ctx, vcsType, url, branch, protocols)
 		if commit == "" {
 			return
 		}
 
 		v.mux.Lock()
-		info[url] = OriginInfo{
-			protocols,
-			branch,
-			commit,
+		origin := OriginInfo{
+			Protocols: protocols,
+			Branch:    branch,
+			SHA:       commit,
+			VCS:       vcsType,
 		}
+		v.stamp(&origin, commit)
+		info[url] = origin
 
 		v.OriginsByPackage[pkgName] = info
+		v.markChanged(pkgName)
 
-		v.logger.Debugln(gotext.Get("Found git repo: %s", text.Cyan(url)))
+		v.logger.Debugln(gotext.Get("Found %s repo: %s", vcsType, text.Cyan(url)))
 
-		if err := v.Save(); err != nil {
+		if err := v.save(); err != nil {
 			fmt.Fprintln(os.Stderr, err)
 		}
 		v.mux.Unlock()
@@ -158,169 +326,176 @@ func (v *InfoStore) Update(ctx context.Context, pkgName string, sources []gosrc.
 	wg.Wait()
 }
 
-// parseSource returns the git url, default branch and protocols it supports.
-func parseSource(source string) (url, branch string, protocols []string) {
+// splitSource returns the VCS, url, protocols and fragment of a source.
+// vcsType is empty for sources that are not VCS checkouts.
+func splitSource(source string) (vcsType, url string, protocols []string, fragKey, fragValue string) {
 	split := strings.Split(source, "::")
 	source = split[len(split)-1]
 	split = strings.SplitN(source, "://", 2)
 
 	if len(split) != 2 {
-		return "", "", nil
+		return "", "", nil, "", ""
 	}
 
-	protocols = strings.SplitN(split[0], "+", 2)
-
-	git := false
+	scheme := split[0]
+	protocols = strings.SplitN(scheme, "+", 2)
+	vcsType = protocols[0]
 
-	for _, protocol := range protocols {
-		if protocol == "git" {
-			git = true
-			break
-		}
+	if _, ok := defaultBranches[vcsType]; !ok {
+		return "", "", nil, "", ""
 	}
 
 	protocols = protocols[len(protocols)-1:]
 
-	if !git {
-		return "", "", nil
+	// makepkg keeps the prefix of the ssh transports of svn and bzr
+	if scheme == "svn+ssh" || scheme == "bzr+ssh" {
+		protocols = []string{scheme}
 	}
 
 	split = strings.SplitN(split[1], "#", 2)
+	url = strings.Split(split[0], "?")[0]
+
 	if len(split) == 2 {
-		secondSplit := strings.SplitN(split[1], "=", 2)
-		if secondSplit[0] != "branch" {
-			// source has #commit= or #tag= which makes them not vcs
-			// packages because they reference a specific point
-			return "", "", nil
-		}
+		fragment := strings.SplitN(split[1], "=", 2)
+		fragKey = fragment[0]
 
-		if len(secondSplit) == 2 {
-			url = split[0]
-			branch = secondSplit[1]
+		if len(fragment) == 2 {
+			fragValue = strings.Split(fragment[1], "?")[0]
 		}
-	} else {
-		url = split[0]
-		branch = "HEAD"
 	}
 
-	url = strings.Split(url, "?")[0]
-	branch = strings.Split(branch, "?")[0]
+	return vcsType, url, protocols, fragKey, fragValue
+}
+
+// parseSource returns the VCS, url, default branch and protocols of a
+// source that follows a moving head.
+func parseSource(source string) (vcsType, url, branch string, protocols []string) {
+	vcsType, url, protocols, fragKey, fragValue := splitSource(source)
+	if vcsType == "" {
+		return "", "", "", nil
+	}
+
+	switch {
+	case fragKey == "":
+		branch = defaultBranches[vcsType]
+	case fragKey == "branch" && branchFragments.Contains(vcsType):
+		branch = fragValue
+	default:
+		// source has #commit=, #tag= or #revision= which makes them not vcs
+		// packages because they reference a specific point
+		return "", "", "", nil
+	}
+
+	if branch == "" {
+		return "", "", "", nil
+	}
 
-	return url, branch, protocols
+	return vcsType, url, branch, protocols
 }
 
+// ToUpgrade checks the remote heads of the origins of a package. With a
+// CheckTTL the heads seen are recorded but only written to disk by Save.
 func (v *InfoStore) ToUpgrade(ctx context.Context, pkgName string) bool {
-	if infos, ok := v.OriginsByPackage[pkgName]; ok {
-		return v.needsUpdate(ctx, infos)
+	v.mux.Lock()
+	infos, ok := v.OriginsByPackage[pkgName]
+	v.mux.Unlock()
+
+	if !ok {
+		return false
 	}
 
-	return false
-}
+	needsUpdate, stamped := v.needsUpdate(ctx, infos)
 
-func (v *InfoStore) needsUpdate(ctx context.Context, infos OriginInfoByURL) bool {
-	// used to signal we have gone through all sources and found nothing
-	finished := make(chan struct{})
-	alive := 0
+	if stamped {
+		v.mux.Lock()
+		v.markChanged(pkgName)
+		v.mux.Unlock()
+	}
+
+	return needsUpdate
+}
 
-	// if we find an update we use this to exit early and return true
-	hasUpdate := make(chan struct{})
+// needsUpdate reports whether an origin moved past its installed commit and
+// whether a remote head was recorded into infos. The remaining checks are
+// cancelled once an update is found, and waited for so that none writes to
+// infos afterwards.
+func (v *InfoStore) needsUpdate(ctx context.Context, infos OriginInfoByURL) (hasUpdate, stamped bool) {
+	var wg sync.WaitGroup
 
-	closed := make(chan struct{})
-	defer close(closed)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
 	checkHash := func(url string, info OriginInfo) {
-		hash := v.getCommit(ctx, url, info.Branch, info.Protocols)
+		defer wg.Done()
 
-		var sendTo chan<- struct{}
-		if hash != "" && hash != info.SHA {
-			sendTo = hasUpdate
-		} else {
-			sendTo = finished
-		}
+		hash, cached := v.cachedHead(info)
+		if !cached {
+			hash = v.getCommit(ctx, info.VCS, url, info.Branch, info.Protocols)
 
-		select {
-		case sendTo <- struct{}{}:
-		case <-closed:
+			if hash != "" && v.CheckTTL > 0 {
+				v.mux.Lock()
+				v.stamp(&info, hash)
+				infos[url] = info
+				stamped = true
+				v.mux.Unlock()
+			}
 		}
-	}
-
-	for url, info := range infos {
-		alive++
 
-		go checkHash(url, info)
-	}
+		if hash != "" && hash != info.SHA {
+			v.mux.Lock()
+			hasUpdate = true
+			v.mux.Unlock()
 
-	for {
-		select {
-		case <-hasUpdate:
-			return true
-		case <-finished:
-			alive--
-			if alive == 0 {
-				return false
-			}
+			cancel()
 		}
 	}
-}
 
-func (v *InfoStore) Save() error {
-	marshalledinfo, err := json.MarshalIndent(v.OriginsByPackage, "", "\t")
-	if err != nil || string(marshalledinfo) == "null" {
-		return err
-	}
+	// checkHash writes back to infos, so pick the origins before starting
+	origins := make(OriginInfoByURL, len(infos))
 
-	in, err := os.OpenFile(v.FilePath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
-	if err != nil {
-		return err
+	v.mux.Lock()
+	for url, info := range infos {
+		// tag origins are checked by NewTag
+		if info.TagPattern == "" {
+			origins[url] = info
+		}
 	}
+	v.mux.Unlock()
 
-	defer in.Close()
+	for url, info := range origins {
+		wg.Add(1)
 
-	if _, errM := in.Write(marshalledinfo); errM != nil {
-		return errM
+		go checkHash(url, info)
 	}
 
-	return in.Sync()
+	wg.Wait()
+
+	return hasUpdate, stamped
 }
 
 // RemovePackage removes package from VCS information.
 func (v *InfoStore) RemovePackages(pkgs []string) {
+	v.mux.Lock()
+	defer v.mux.Unlock()
+
 	updated := false
 
 	for _, pkgName := range pkgs {
 		if _, ok := v.OriginsByPackage[pkgName]; ok {
 			delete(v.OriginsByPackage, pkgName)
+			v.markChanged(pkgName)
 
 			updated = true
 		}
 	}
 
 	if updated {
-		if err := v.Save(); err != nil {
+		if err := v.save(); err != nil {
 			fmt.Fprintln(os.Stderr, err)
 		}
 	}
 }
 
-// LoadStore reads a json file and populates a InfoStore structure.
-func (v *InfoStore) Load() error {
-	vfile, err := os.Open(v.FilePath)
-	if !os.IsNotExist(err) && err != nil {
-		return fmt.Errorf("failed to open vcs file '%s': %w", v.FilePath, err)
-	}
-
-	defer vfile.Close()
-
-	if !os.IsNotExist(err) {
-		decoder := json.NewDecoder(vfile)
-		if err = decoder.Decode(&v.OriginsByPackage); err != nil {
-			return fmt.Errorf("failed to read vcs '%s': %w", v.FilePath, err)
-		}
-	}
-
-	return nil
-}
-
 func (v *InfoStore) CleanOrphans(pkgs map[string]alpm.IPackage) {
 	missing := make([]string, 0)
 
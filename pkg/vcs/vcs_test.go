@@ -8,15 +8,19 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"strings"
 	"testing"
+	"time"
 
 	gosrc "github.com/Morganamilo/go-srcinfo"
 	"github.com/bradleyjkemp/cupaloy"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
 
 	"github.com/Jguer/yay/v12/pkg/db"
 	"github.com/Jguer/yay/v12/pkg/settings/exe"
@@ -30,6 +34,7 @@ func newTestLogger() *text.Logger {
 func TestParsing(t *testing.T) {
 	t.Parallel()
 	type source struct {
+		VCS       string
 		URL       string
 		Branch    string
 		Protocols []string
@@ -42,21 +47,44 @@ func TestParsing(t *testing.T) {
 		"git://github.com/jguer/yay.git#tag=v3.440",
 		"git://github.com/jguer/yay.git#commit=e5470c88c6e2f9e0f97deb4728659ffa70ef5d0c",
 		"a+b+c+d+e+f://github.com/jguer/yay.git#branch=foo",
+		"hg+https://hg.mozilla.org/mozilla-central",
+		"hg+https://www.mercurial-scm.org/repo/hg#branch=stable",
+		"hg+https://www.mercurial-scm.org/repo/hg#revision=6.4",
+		"svn+https://svn.code.sf.net/p/netpbm/code/trunk",
+		"svn://svn.code.sf.net/p/netpbm/code/trunk",
+		"svn+ssh://svn.example.org/repo#revision=1234",
+		"bzr+ssh://bazaar.launchpad.net/~foo/bar/trunk",
+		"bzr+https://bazaar.launchpad.net/~foo/bar/trunk#revision=10",
+		"fossil+https://fossil-scm.org/home",
+		"fossil+https://fossil-scm.org/home#branch=release",
+		"fossil+https://fossil-scm.org/home#tag=version-2.21",
 	}
 
 	sources := []source{
-		{"github.com/neovim/neovim.git", "HEAD", []string{"https"}},
-		{"github.com/jguer/yay.git", "master", []string{"git"}},
-		{"github.com/davidgiven/ack", "HEAD", []string{"git"}},
-		{"", "", nil},
-		{"", "", nil},
-		{"", "", nil},
+		{"git", "github.com/neovim/neovim.git", "HEAD", []string{"https"}},
+		{"git", "github.com/jguer/yay.git", "master", []string{"git"}},
+		{"git", "github.com/davidgiven/ack", "HEAD", []string{"git"}},
+		{"", "", "", nil},
+		{"", "", "", nil},
+		{"", "", "", nil},
+		{"hg", "hg.mozilla.org/mozilla-central", "default", []string{"https"}},
+		{"hg", "www.mercurial-scm.org/repo/hg", "stable", []string{"https"}},
+		{"", "", "", nil},
+		{"svn", "svn.code.sf.net/p/netpbm/code/trunk", "HEAD", []string{"https"}},
+		{"svn", "svn.code.sf.net/p/netpbm/code/trunk", "HEAD", []string{"svn"}},
+		{"", "", "", nil},
+		{"bzr", "bazaar.launchpad.net/~foo/bar/trunk", "HEAD", []string{"bzr+ssh"}},
+		{"", "", "", nil},
+		{"fossil", "fossil-scm.org/home", "trunk", []string{"https"}},
+		{"fossil", "fossil-scm.org/home", "release", []string{"https"}},
+		{"", "", "", nil},
 	}
 
 	for n, url := range urls {
-		url, branch, protocols := parseSource(url)
+		vcsType, url, branch, protocols := parseSource(url)
 		compare := sources[n]
 
+		assert.Equal(t, compare.VCS, vcsType)
 		assert.Equal(t, compare.URL, url)
 		assert.Equal(t, compare.Branch, branch)
 		assert.Equal(t, compare.Protocols, protocols)
@@ -371,7 +399,7 @@ func TestInfoStore_NeedsUpdate(t *testing.T) {
 				logger:     newTestLogger(),
 				CmdBuilder: tt.fields.CmdBuilder,
 			}
-			got := v.needsUpdate(context.Background(), tt.args.infos)
+			got, _ := v.needsUpdate(context.Background(), tt.args.infos)
 			assert.Equal(t, tt.want, got)
 		})
 	}
@@ -539,3 +567,162 @@ func TestInfoStore_CleanOrphans(t *testing.T) {
 
 	require.NoError(t, os.Remove(filePath))
 }
+
+func TestInfoStore_getCommitVCS(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		info     OriginInfo
+		returned string
+		wantArgs []string
+		want     string
+	}{
+		{
+			name:     "hg",
+			info:     OriginInfo{VCS: VCSHg, Branch: "default", Protocols: []string{"https"}},
+			returned: "9f4b2f9e2c1a\n",
+			wantArgs: []string{"hg", "identify", "--id", "-r", "default", "https://example.org/repo"},
+			want:     "9f4b2f9e2c1a",
+		},
+		{
+			name:     "svn",
+			info:     OriginInfo{VCS: VCSSvn, Branch: "HEAD", Protocols: []string{"svn"}},
+			returned: "1234\n",
+			wantArgs: []string{"svn", "info", "--show-item", "last-changed-revision", "svn://example.org/repo"},
+			want:     "1234",
+		},
+		{
+			name:     "bzr",
+			info:     OriginInfo{VCS: VCSBzr, Branch: "HEAD", Protocols: []string{"bzr+ssh"}},
+			returned: "42\n",
+			wantArgs: []string{"bzr", "revno", "bzr+ssh://example.org/repo"},
+			want:     "42",
+		},
+		{
+			name:     "legacy git entry",
+			info:     OriginInfo{Branch: "HEAD", Protocols: []string{"https"}},
+			returned: "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa\tHEAD\n",
+			wantArgs: []string{"git", "ls-remote", "https://example.org/repo", "HEAD"},
+			want:     "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			runner := &exe.MockRunner{
+				CaptureFn: func(cmd *exec.Cmd) (string, string, error) {
+					return tt.returned, "", nil
+				},
+			}
+			v := &InfoStore{
+				logger:     newTestLogger(),
+				CmdBuilder: &exe.MockBuilder{Runner: runner},
+			}
+
+			got := v.getCommit(context.Background(), tt.info.VCS, "example.org/repo", tt.info.Branch, tt.info.Protocols)
+			assert.Equal(t, tt.want, got)
+			require.Len(t, runner.CaptureCalls, 1)
+			assert.Equal(t, tt.wantArgs, runner.CaptureCalls[0].Args[0].(*exec.Cmd).Args)
+		})
+	}
+}
+
+func TestInfoStore_getCommitFossil(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://fossil-scm.org").
+		Get("/home/timeline.rss").
+		MatchParam("tag", "trunk").
+		Reply(200).
+		BodyString(`<?xml version="1.0"?>
+<rss xmlns:dc="http://purl.org/dc/elements/1.1/" version="2.0">
+<channel>
+<title>Fossil</title>
+<item>
+<title>Fix a typo</title>
+<link>https://fossil-scm.org/home/info/5f3b1c2d7e9a</link>
+<guid>https://fossil-scm.org/home/info/5f3b1c2d7e9a</guid>
+</item>
+</channel>
+</rss>`)
+
+	v := &InfoStore{logger: newTestLogger(), HTTPClient: &http.Client{}}
+
+	got := v.getCommit(context.Background(), VCSFossil, "fossil-scm.org/home", "trunk", []string{"https"})
+	assert.Equal(t, "5f3b1c2d7e9a", got)
+}
+
+func TestInfoStore_getCommitFossilInvalidLink(t *testing.T) {
+	defer gock.Off()
+
+	gock.New("https://fossil-scm.org").
+		Get("/home/timeline.rss").
+		MatchParam("tag", "trunk").
+		Reply(200).
+		BodyString(`<?xml version="1.0"?>
+<rss xmlns:dc="http://purl.org/dc/elements/1.1/" version="2.0">
+<channel>
+<title>Fossil</title>
+<item>
+<title>Login required</title>
+<link>https://fossil-scm.org/home/login</link>
+</item>
+</channel>
+</rss>`)
+
+	v := &InfoStore{logger: newTestLogger(), HTTPClient: &http.Client{}}
+
+	got := v.getCommit(context.Background(), VCSFossil, "fossil-scm.org/home", "trunk", []string{"https"})
+	assert.Equal(t, "", got)
+}
+
+func TestInfoStore_NeedsUpdateCancelsRemainingChecks(t *testing.T) {
+	t.Parallel()
+
+	requested, cancelled := make(chan struct{}), make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(requested)
+
+		select {
+		case <-r.Context().Done():
+			close(cancelled)
+		case <-time.After(defaultTimeout):
+		}
+	}))
+	defer server.Close()
+
+	runner := &exe.MockRunner{
+		CaptureFn: func(cmd *exec.Cmd) (string, string, error) {
+			// the update is only found while the fossil check is running
+			<-requested
+			return "bbbb\tHEAD\n", "", nil
+		},
+	}
+
+	v := &InfoStore{
+		logger:     newTestLogger(),
+		CmdBuilder: &exe.MockBuilder{Runner: runner},
+		HTTPClient: server.Client(),
+	}
+
+	started := time.Now()
+	got, _ := v.needsUpdate(context.Background(), OriginInfoByURL{
+		"github.com/Jguer/yay.git": {Protocols: []string{"https"}, Branch: "HEAD", SHA: "aaaa", VCS: VCSGit},
+		strings.TrimPrefix(server.URL, "http://") + "/home": {
+			Protocols: []string{"http"}, Branch: "trunk", SHA: "cccc", VCS: VCSFossil,
+		},
+	})
+
+	assert.True(t, got)
+	assert.Less(t, time.Since(started), defaultTimeout)
+
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the fossil check was not cancelled")
+	}
+}
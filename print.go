@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
 	"unicode"
 
 	aur "github.com/Jguer/aur"
@@ -15,10 +17,13 @@ import (
 	"github.com/leonelquinteros/gotext"
 	"golang.org/x/sys/unix"
 
+	"github.com/Jguer/yay/v12/pkg/advisory"
 	"github.com/Jguer/yay/v12/pkg/db"
 	"github.com/Jguer/yay/v12/pkg/dep"
+	"github.com/Jguer/yay/v12/pkg/output"
 	"github.com/Jguer/yay/v12/pkg/query"
 	"github.com/Jguer/yay/v12/pkg/runtime"
+	"github.com/Jguer/yay/v12/pkg/sbom"
 	"github.com/Jguer/yay/v12/pkg/settings"
 	"github.com/Jguer/yay/v12/pkg/settings/parser"
 	"github.com/Jguer/yay/v12/pkg/text"
@@ -65,64 +70,281 @@ func printInfo(logger *text.Logger, config *settings.Configuration, a *aur.Pkg,
 	logger.Println()
 }
 
-// BiggestPackages prints the name of the ten biggest packages in the system.
-func biggestPackages(logger *text.Logger, dbExecutor db.Executor) {
-	pkgS := dbExecutor.BiggestPackages()
-
-	if len(pkgS) < 10 {
-		return
-	}
-
-	for i := 0; i < 10; i++ {
-		logger.Printf("%s: %s\n", text.Bold(pkgS[i].Name()), text.Cyan(text.Human(pkgS[i].ISize())))
+// printSizes prints a list of named sizes.
+func printSizes(logger *text.Logger, entries []SizeEntry) {
+	for _, entry := range entries {
+		logger.Printf("%s: %s\n", text.Bold(entry.Name), text.Cyan(text.Human(entry.Size)))
 	}
 }
 
 // localStatistics prints installed packages statistics.
-func localStatistics(ctx context.Context, run *runtime.Runtime, dbExecutor db.Executor) error {
+func localStatistics(ctx context.Context, run *runtime.Runtime, cmdArgs *parser.Arguments,
+	dbExecutor db.Executor,
+) error {
+	formatter, err := newFormatter(cmdArgs)
+	if err != nil {
+		return err
+	}
+
 	info := statistics(run, dbExecutor)
 
-	remoteNames := dbExecutor.InstalledRemotePackageNames()
-	remote := dbExecutor.InstalledRemotePackages()
+	info.addForeignStatistics(dbExecutor)
+
+	warnings := query.NewWarnings(run.Logger.Child("warnings"))
+
+	// the AUR health is best effort, the local statistics are still printed
+	aurData, err := run.AURClient.Get(ctx, &aur.Query{
+		Needles: dbExecutor.InstalledRemotePackageNames(),
+		By:      aur.Name,
+	})
+	switch {
+	case err == nil:
+		info.addAURHealth(dbExecutor, aurData, warnings)
+	case formatter != nil:
+		// keep the formatted output on stdout parseable
+		run.Logger.Errorln(gotext.Get("unable to query the AUR, skipping AUR package health:"), err)
+	default:
+		run.Logger.Warnln(gotext.Get("unable to query the AUR, skipping AUR package health:"), err)
+	}
+
+	info.addBiggestPackages(dbExecutor)
+	info.addBuildDirs(run.Cfg.BuildDir)
+
+	if formatter != nil {
+		var out strings.Builder
+		if err := output.WriteOne(&out, formatter, *info); err != nil {
+			return err
+		}
+
+		run.Logger.Print(out.String())
+
+		return nil
+	}
+
+	separator := text.Bold(text.Cyan("==========================================="))
+
 	run.Logger.Infoln(gotext.Get("Yay version v%s", yayVersion))
-	run.Logger.Println(text.Bold(text.Cyan("===========================================")))
-	run.Logger.Infoln(gotext.Get("Total installed packages: %s", text.Cyan(strconv.Itoa(info.Totaln))))
-	run.Logger.Infoln(gotext.Get("Foreign installed packages: %s", text.Cyan(strconv.Itoa(len(remoteNames)))))
-	run.Logger.Infoln(gotext.Get("Explicitly installed packages: %s", text.Cyan(strconv.Itoa(info.Expln))))
+	run.Logger.Println(separator)
+	run.Logger.Infoln(gotext.Get("Total installed packages: %s", text.Cyan(strconv.Itoa(info.Total))))
+	run.Logger.Infoln(gotext.Get("Foreign installed packages: %s", text.Cyan(strconv.Itoa(info.Foreign))))
+	run.Logger.Infoln(gotext.Get("Explicitly installed packages: %s", text.Cyan(strconv.Itoa(info.Explicit))))
 	run.Logger.Infoln(gotext.Get("Total Size occupied by packages: %s", text.Cyan(text.Human(info.TotalSize))))
 
-	for path, size := range info.pacmanCaches {
-		run.Logger.Infoln(gotext.Get("Size of pacman cache %s: %s", path, text.Cyan(text.Human(size))))
+	for _, path := range run.PacmanConf.CacheDir {
+		run.Logger.Infoln(gotext.Get("Size of pacman cache %s: %s", path, text.Cyan(text.Human(info.PacmanCaches[path]))))
 	}
 
-	run.Logger.Infoln(gotext.Get("Size of yay cache %s: %s", run.Cfg.BuildDir, text.Cyan(text.Human(info.yayCache))))
-	run.Logger.Println(text.Bold(text.Cyan("===========================================")))
-	run.Logger.Infoln(gotext.Get("Ten biggest packages:"))
-	biggestPackages(run.Logger, dbExecutor)
-	run.Logger.Println(text.Bold(text.Cyan("===========================================")))
+	run.Logger.Infoln(gotext.Get("Size of yay cache %s: %s", run.Cfg.BuildDir, text.Cyan(text.Human(info.YayCache))))
+	run.Logger.Println(separator)
+	run.Logger.Infoln(gotext.Get("Installed size per repository:"))
 
-	aurData, err := run.AURClient.Get(ctx, &aur.Query{
-		Needles: remoteNames,
-		By:      aur.Name,
-	})
+	repos := make([]string, 0, len(info.SizeByRepo))
+	for repo := range info.SizeByRepo {
+		repos = append(repos, repo)
+	}
+
+	sort.Strings(repos)
+
+	for _, repo := range repos {
+		run.Logger.Printf("%s: %s\n", text.Bold(repo), text.Cyan(text.Human(info.SizeByRepo[repo])))
+	}
+
+	run.Logger.Println(separator)
+	run.Logger.Infoln(gotext.Get("AUR package health:"))
+
+	if info.AURHealth.Deleted != nil {
+		run.Logger.Printf("%s: %s\n", text.Bold(gotext.Get("Orphaned")), text.Cyan(strconv.Itoa(len(info.AURHealth.Orphaned))))
+		run.Logger.Printf("%s: %s\n", text.Bold(gotext.Get("Out-of-date")), text.Cyan(strconv.Itoa(len(info.AURHealth.OutOfDate))))
+		run.Logger.Printf("%s: %s\n", text.Bold(gotext.Get("Deleted")), text.Cyan(strconv.Itoa(len(info.AURHealth.Deleted))))
+	}
+
+	run.Logger.Printf("%s: %s\n", text.Bold(gotext.Get("Devel")), text.Cyan(strconv.Itoa(len(info.AURHealth.Devel))))
+
+	if len(info.BiggestPackages) == statisticsTop {
+		run.Logger.Println(separator)
+		run.Logger.Infoln(gotext.Get("Ten biggest packages:"))
+		printSizes(run.Logger, info.BiggestPackages)
+	}
+
+	if len(info.LargestForeign) > 0 {
+		run.Logger.Println(separator)
+		run.Logger.Infoln(gotext.Get("Largest foreign packages:"))
+		printSizes(run.Logger, info.LargestForeign)
+	}
+
+	if len(info.OldestAURBuilds) > 0 {
+		run.Logger.Println(separator)
+		run.Logger.Infoln(gotext.Get("Oldest AUR builds:"))
+
+		for _, build := range info.OldestAURBuilds {
+			run.Logger.Printf("%s %s: %s\n", text.Bold(build.Name), text.Green(build.Version),
+				text.Cyan(text.FormatTime(int(build.BuildDate.Unix()))))
+		}
+	}
+
+	if len(info.BuildDirs) > 0 {
+		run.Logger.Println(separator)
+		run.Logger.Infoln(gotext.Get("Largest build directories:"))
+		printSizes(run.Logger, info.BuildDirs)
+	}
+
+	run.Logger.Println(separator)
+	warnings.Print()
+
+	return nil
+}
+
+// printAdvisories lists installed packages affected by a known advisory.
+func printAdvisories(ctx context.Context, run *runtime.Runtime, dbExecutor db.Executor) error {
+	if run.Cfg.AdvisoryFeed == "" {
+		return advisory.ErrNoFeed
+	}
+
+	feed, err := advisory.Load(ctx, run.HTTPClient, run.Cfg.AdvisoryFeed)
 	if err != nil {
 		return err
 	}
 
-	warnings := query.NewWarnings(run.Logger.Child("warnings"))
-	for i := range aurData {
-		warnings.AddToWarnings(remote, &aurData[i])
+	affected := 0
+
+	for _, pkg := range dbExecutor.LocalPackages() {
+		advisories := feed.Affecting(pkg.Name(), pkg.Version())
+		if len(advisories) == 0 {
+			continue
+		}
+
+		affected++
+
+		run.Logger.Println(text.Bold(pkg.Name()), text.Bold(text.Red(pkg.Version())))
+
+		for _, adv := range advisories {
+			fixed := gotext.Get("no fix available")
+			if adv.Fixed != "" {
+				fixed = gotext.Get("fixed in %s", text.Green(adv.Fixed))
+			}
+
+			run.Logger.Printf("    %s %s\n", adv, fixed)
+		}
 	}
 
-	warnings.Print()
+	if affected == 0 {
+		run.Logger.Infoln(gotext.Get("No installed packages are affected by known advisories."))
+	}
 
 	return nil
 }
 
+// printSBOM prints a software bill of materials of the installed packages.
+func printSBOM(ctx context.Context, run *runtime.Runtime, dbExecutor db.Executor, format string) error {
+	if format == "" {
+		format = sbom.FormatSPDX
+	}
+
+	if err := sbom.CheckFormat(format); err != nil {
+		return err
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "localhost"
+	}
+
+	collector := sbom.NewCollector(dbExecutor, run.CmdBuilder, run.Cfg.BuildDir, run.Logger.Child("sbom"))
+	doc := &sbom.Document{
+		Name:        hostname,
+		Tool:        "yay",
+		ToolVersion: yayVersion,
+		AURURL:      run.Cfg.AURURL,
+		Created:     time.Now(),
+		Components:  collector.Components(ctx),
+	}
+
+	return sbom.Write(os.Stdout, doc, format)
+}
+
+// pendingUpgrade is a pending upgrade in the machine-readable output of -Qu.
+type pendingUpgrade struct {
+	Name          string `json:"name"`
+	Base          string `json:"base"`
+	Repository    string `json:"repository"`
+	LocalVersion  string `json:"localVersion"`
+	RemoteVersion string `json:"remoteVersion"`
+	Devel         bool   `json:"devel"`
+	Reason        string `json:"reason"`
+}
+
+func (p pendingUpgrade) Fields() []string {
+	return []string{
+		p.Name, p.Base, p.Repository, p.LocalVersion,
+		p.RemoteVersion, strconv.FormatBool(p.Devel), p.Reason,
+	}
+}
+
+// upgradeCounts are the totals printed by -Qu --count. AUR includes devel
+// upgrades.
+type upgradeCounts struct {
+	Repo  int `json:"repo"`
+	AUR   int `json:"aur"`
+	Devel int `json:"devel"`
+	Total int `json:"total"`
+}
+
+func (c upgradeCounts) Fields() []string {
+	return []string{
+		strconv.Itoa(c.Repo), strconv.Itoa(c.AUR),
+		strconv.Itoa(c.Devel), strconv.Itoa(c.Total),
+	}
+}
+
+func newPendingUpgrade(dbExecutor db.Executor, pkgName string, ii *dep.InstallInfo) pendingUpgrade {
+	up := pendingUpgrade{
+		Name:          pkgName,
+		Base:          pkgName,
+		Repository:    "aur",
+		LocalVersion:  ii.LocalVersion,
+		RemoteVersion: ii.Version,
+		Devel:         ii.Devel,
+		Reason:        "explicit",
+	}
+
+	if ii.Reason != dep.Explicit {
+		up.Reason = "dependency"
+	}
+
+	if ii.AURBase != nil {
+		up.Base = *ii.AURBase
+	} else if pkg := dbExecutor.LocalPackage(pkgName); pkg != nil && pkg.Base() != "" {
+		up.Base = pkg.Base()
+	}
+
+	if ii.Source == dep.Sync && ii.SyncDBName != nil {
+		up.Repository = *ii.SyncDBName
+	}
+
+	return up
+}
+
+// newFormatter parses the --format of a machine-readable output. It returns
+// nil when the output is for humans.
+func newFormatter(cmdArgs *parser.Arguments) (*output.Formatter, error) {
+	format, _, ok := cmdArgs.GetArg("format")
+	if !ok {
+		return nil, nil
+	}
+
+	return output.New(format)
+}
+
 func printUpdateList(ctx context.Context, run *runtime.Runtime, cmdArgs *parser.Arguments,
 	dbExecutor db.Executor, enableDowngrade bool, filter upgrade.Filter,
 ) error {
 	quietMode := cmdArgs.ExistsArg("q", "quiet")
+	countMode := cmdArgs.ExistsArg("count")
+
+	formatter, err := newFormatter(cmdArgs)
+	if err != nil {
+		return err
+	}
 
 	// TODO: handle quiet mode in a better way
 	logger := text.NewLogger(io.Discard, os.Stderr, os.Stdin, run.Cfg.Debug, "update-list")
@@ -136,9 +358,12 @@ func printUpdateList(ctx context.Context, run *runtime.Runtime, cmdArgs *parser.
 	grapher := dep.NewGrapher(dbExecutor, run.AURClient, false, true,
 		false, false, cmdArgs.ExistsArg("needed"), logger.Child("grapher"))
 
-	upService := upgrade.NewUpgradeService(
+	upService, err := upgrade.NewUpgradeService(
 		grapher, run.AURClient, dbExecutor, run.VCSStore,
 		run.Cfg, true, logger.Child("upgrade"))
+	if err != nil {
+		return err
+	}
 
 	graph, errSysUp := upService.GraphUpgrades(ctx, nil,
 		enableDowngrade, filter)
@@ -146,15 +371,12 @@ func printUpdateList(ctx context.Context, run *runtime.Runtime, cmdArgs *parser.
 		return errSysUp
 	}
 
-	if graph.Len() == 0 {
-		return fmt.Errorf("")
-	}
-
 	noTargets := targets.Cardinality() == 0
 	foreignFilter := cmdArgs.ExistsArg("m", "foreign")
 	nativeFilter := cmdArgs.ExistsArg("n", "native")
 
-	noUpdates := true
+	pending := []pendingUpgrade{}
+	counts := upgradeCounts{}
 	_ = graph.ForEach(func(pkgName string, ii *dep.InstallInfo) error {
 		if !ii.Upgrade {
 			return nil
@@ -167,20 +389,56 @@ func printUpdateList(ctx context.Context, run *runtime.Runtime, cmdArgs *parser.
 				return nil
 			}
 
-			if quietMode {
+			switch {
+			case countMode:
+			case formatter != nil:
+				pending = append(pending, newPendingUpgrade(dbExecutor, pkgName, ii))
+			case quietMode:
 				run.Logger.Printf("%s\n", pkgName)
-			} else {
+			default:
 				run.Logger.Printf("%s %s -> %s\n", text.Bold(pkgName), text.Bold(text.Green(ii.LocalVersion)),
 					text.Bold(text.Green(ii.Version)))
 			}
 
+			if ii.Source == dep.AUR {
+				counts.AUR++
+			} else {
+				counts.Repo++
+			}
+
+			if ii.Devel {
+				counts.Devel++
+			}
+
+			counts.Total++
+
 			targets.Remove(pkgName)
-			noUpdates = false
 		}
 
 		return nil
 	})
 
+	var (
+		out      strings.Builder
+		errPrint error
+	)
+
+	switch {
+	case countMode && formatter != nil:
+		errPrint = output.WriteOne(&out, formatter, counts)
+	case countMode:
+		run.Logger.Printf("repo %d\naur %d\ndevel %d\ntotal %d\n",
+			counts.Repo, counts.AUR, counts.Devel, counts.Total)
+	case formatter != nil:
+		errPrint = output.Write(&out, formatter, pending)
+	}
+
+	if errPrint != nil {
+		return errPrint
+	}
+
+	run.Logger.Print(out.String())
+
 	missing := false
 	targets.Each(func(pkgName string) bool {
 		if dbExecutor.LocalPackage(pkgName) == nil {
@@ -190,7 +448,7 @@ func printUpdateList(ctx context.Context, run *runtime.Runtime, cmdArgs *parser.
 		return false
 	})
 
-	if missing || noUpdates {
+	if missing || counts.Total == 0 {
 		return fmt.Errorf("")
 	}
 
@@ -165,6 +165,7 @@ func TestPrintUpdateList(t *testing.T) {
 		name     string
 		mockData mockData
 		args     []string
+		params   map[string]string
 		targets  []string
 		wantPkgs []string
 		wantErr  bool
@@ -236,6 +237,44 @@ func TestPrintUpdateList(t *testing.T) {
 			wantPkgs: []string{},
 			wantErr:  true,
 		},
+		{
+			name:     "Qu format tsv",
+			mockData: mockData{mockDB, mockAUR},
+			args:     []string{"Q", "u"},
+			params:   map[string]string{"format": "tsv"},
+			targets:  []string{},
+			wantPkgs: []string{
+				"linux\tlinux\tcore\t4.3.0\t5.10.0\tfalse\texplicit",
+				"go\tgo\tcore\t2:1.20.3-1\t2:1.20.4-1\tfalse\texplicit",
+				"vosk-api\tvosk-api\taur\t0.3.43-1\t0.3.45-1\tfalse\texplicit",
+			},
+		},
+		{
+			name:     "Qu format template",
+			mockData: mockData{mockDB, mockAUR},
+			args:     []string{"Q", "u", "m"},
+			params:   map[string]string{"format": "{{.Repository}}/{{.Name}} {{.RemoteVersion}}"},
+			targets:  []string{},
+			wantPkgs: []string{"aur/vosk-api 0.3.45-1"},
+		},
+		{
+			name:     "Qu count",
+			mockData: mockData{mockDB, mockAUR},
+			args:     []string{"Q", "u", "count"},
+			targets:  []string{},
+			wantPkgs: []string{"repo 2", "aur 1", "devel 0", "total 3"},
+		},
+		{
+			name:     "Qu count json no-updates-any",
+			mockData: mockData{mockDBNoUpdates, mockAURNoUpdates},
+			args:     []string{"Q", "u", "count"},
+			params:   map[string]string{"format": "json"},
+			targets:  []string{},
+			wantPkgs: []string{
+				"{", `  "repo": 0,`, `  "aur": 0,`, `  "devel": 0,`, `  "total": 0`, "}",
+			},
+			wantErr: true,
+		},
 		{
 			name:     "Qu no-updates-any",
 			mockData: mockData{mockDBNoUpdates, mockAURNoUpdates},
@@ -288,6 +327,9 @@ func TestPrintUpdateList(t *testing.T) {
 
 			cmdArgs := parser.MakeArguments()
 			cmdArgs.AddArg(tc.args...)
+			for option, value := range tc.params {
+				cmdArgs.CreateOrAppendOption(option, value)
+			}
 			cmdArgs.AddTarget(tc.targets...)
 
 			err = handleCmd(context.Background(), run, cmdArgs, tc.mockData.db)
@@ -2,15 +2,19 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/fs"
 	"path/filepath"
+	"strings"
 
 	aur "github.com/Jguer/aur"
 	alpm "github.com/Jguer/go-alpm/v2"
 	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/leonelquinteros/gotext"
 
 	"github.com/Jguer/yay/v12/pkg/db"
+	"github.com/Jguer/yay/v12/pkg/output"
 	"github.com/Jguer/yay/v12/pkg/query"
 	"github.com/Jguer/yay/v12/pkg/runtime"
 	"github.com/Jguer/yay/v12/pkg/settings"
@@ -19,10 +23,16 @@ import (
 )
 
 // SyncSearch presents a query to the local repos and to the AUR.
-func syncSearch(ctx context.Context, pkgS []string,
-	dbExecutor db.Executor, queryBuilder query.Builder, verbose bool,
+func syncSearch(ctx context.Context, logger *text.Logger, pkgS []string,
+	dbExecutor db.Executor, queryBuilder query.Builder, verbose bool, formatter *output.Formatter,
 ) error {
-	queryBuilder.Execute(ctx, dbExecutor, pkgS)
+	if err := queryBuilder.Execute(ctx, dbExecutor, pkgS); err != nil {
+		return err
+	}
+
+	if formatter != nil {
+		return printRecords(logger, formatter, queryBuilder.Records(dbExecutor))
+	}
 
 	searchMode := query.Minimal
 	if verbose {
@@ -42,6 +52,11 @@ func syncInfo(ctx context.Context, run *runtime.Runtime,
 		missing = false
 	)
 
+	formatter, err := newFormatter(cmdArgs)
+	if err != nil {
+		return err
+	}
+
 	pkgS = query.RemoveInvalidTargets(run.Logger, pkgS, run.Cfg.Mode)
 	aurS, repoS := packageSlices(pkgS, run.Cfg, dbExecutor)
 
@@ -63,7 +78,7 @@ func syncInfo(ctx context.Context, run *runtime.Runtime,
 			noDB = append(noDB, name)
 		}
 
-		info, err = run.AURClient.Get(ctx, &aur.Query{
+		info, err = run.AURInfoClient.Get(ctx, &aur.Query{
 			Needles: noDB,
 			By:      aur.Name,
 		})
@@ -74,7 +89,22 @@ func syncInfo(ctx context.Context, run *runtime.Runtime,
 		}
 	}
 
-	if len(repoS) != 0 || (len(aurS) == 0 && len(repoS) == 0) {
+	records := make([]query.PackageRecord, 0, len(repoS)+len(info))
+
+	switch {
+	case formatter != nil:
+		for _, target := range repoS {
+			pkg := syncPackageFromTarget(dbExecutor, target)
+			if pkg == nil {
+				run.Logger.Errorln(gotext.Get("package '%s' was not found", target))
+				missing = true
+
+				continue
+			}
+
+			records = append(records, query.NewRepoRecord(pkg, dbExecutor))
+		}
+	case len(repoS) != 0 || (len(aurS) == 0 && len(repoS) == 0):
 		arguments := cmdArgs.Copy()
 		arguments.ClearTargets()
 		arguments.AddTarget(repoS...)
@@ -91,9 +121,20 @@ func syncInfo(ctx context.Context, run *runtime.Runtime,
 	}
 
 	for i := range info {
+		if formatter != nil {
+			records = append(records, query.NewAURRecord(&info[i], dbExecutor))
+			continue
+		}
+
 		printInfo(run.Logger, run.Cfg, &info[i], cmdArgs.ExistsDouble("i"))
 	}
 
+	if formatter != nil {
+		if errPrint := printRecords(run.Logger, formatter, records); errPrint != nil {
+			return errPrint
+		}
+	}
+
 	if missing {
 		err = fmt.Errorf("")
 	}
@@ -101,6 +142,102 @@ func syncInfo(ctx context.Context, run *runtime.Runtime,
 	return err
 }
 
+// getLocalFilter returns a filter keeping the installed packages selected by
+// the -m, -n, -e and -d query options.
+func getLocalFilter(cmdArgs *parser.Arguments, dbExecutor db.Executor) (func(alpm.IPackage) bool, error) {
+	foreign, native := cmdArgs.ExistsArg("m", "foreign"), cmdArgs.ExistsArg("n", "native")
+	deps, explicit := cmdArgs.ExistsArg("d", "deps"), cmdArgs.ExistsArg("e", "explicit")
+
+	switch {
+	case deps && explicit:
+		return nil, errors.New(gotext.Get("invalid option: '--deps' and '--explicit' may not be used together"))
+	case foreign && native:
+		return nil, errors.New(gotext.Get("invalid option: '--native' and '--foreign' may not be used together"))
+	}
+
+	return func(pkg alpm.IPackage) bool {
+		switch {
+		case deps && pkg.Reason() != alpm.PkgReasonDepend,
+			explicit && pkg.Reason() != alpm.PkgReasonExplicit:
+			return false
+		case foreign || native:
+			return (dbExecutor.SyncPackage(pkg.Name()) == nil) == foreign
+		}
+
+		return true
+	}, nil
+}
+
+// queryInfo serves as a pacman -Qi for the machine-readable output of
+// installed packages.
+func queryInfo(run *runtime.Runtime, cmdArgs *parser.Arguments,
+	dbExecutor db.Executor, formatter *output.Formatter,
+) error {
+	filter, err := getLocalFilter(cmdArgs, dbExecutor)
+	if err != nil {
+		return err
+	}
+
+	var pkgs []alpm.IPackage
+
+	missing := false
+
+	if len(cmdArgs.Targets) == 0 {
+		pkgs = dbExecutor.LocalPackages()
+	}
+
+	for _, target := range cmdArgs.Targets {
+		pkg := dbExecutor.LocalPackage(target)
+		if pkg == nil {
+			run.Logger.Errorln(gotext.Get("package '%s' was not found", target))
+			missing = true
+
+			continue
+		}
+
+		pkgs = append(pkgs, pkg)
+	}
+
+	records := make([]query.PackageRecord, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		if filter(pkg) {
+			records = append(records, query.NewLocalRecord(pkg))
+		}
+	}
+
+	if err := printRecords(run.Logger, formatter, records); err != nil {
+		return err
+	}
+
+	if missing {
+		return fmt.Errorf("")
+	}
+
+	return nil
+}
+
+// syncPackageFromTarget finds the sync package of a target such as linux
+// or core/linux.
+func syncPackageFromTarget(dbExecutor db.Executor, target string) alpm.IPackage {
+	dbName, name := text.SplitDBFromName(target)
+	if dbName != "" {
+		return dbExecutor.SyncPackageFromDB(name, dbName)
+	}
+
+	return dbExecutor.SyncPackage(name)
+}
+
+func printRecords(logger *text.Logger, formatter *output.Formatter, records []query.PackageRecord) error {
+	var out strings.Builder
+	if err := output.Write(&out, formatter, records); err != nil {
+		return err
+	}
+
+	logger.Print(out.String())
+
+	return nil
+}
+
 // PackageSlices separates an input slice into aur and repo slices.
 func packageSlices(toCheck []string, config *settings.Configuration, dbExecutor db.Executor) (aurNames, repoNames []string) {
 	for _, _pkg := range toCheck {
@@ -220,8 +357,14 @@ func hangingPackages(removeOptional bool, dbExecutor db.Executor) (hanging []str
 
 func getFolderSize(path string) (size int64) {
 	_ = filepath.WalkDir(path, func(p string, entry fs.DirEntry, err error) error {
-		info, _ := entry.Info()
-		size += info.Size()
+		if err != nil {
+			return nil
+		}
+
+		if info, errInfo := entry.Info(); errInfo == nil {
+			size += info.Size()
+		}
+
 		return nil
 	})
 
@@ -229,29 +372,197 @@ func getFolderSize(path string) (size int64) {
 }
 
 // Statistics returns statistics about packages installed in system.
-func statistics(run *runtime.Runtime, dbExecutor db.Executor) (res struct {
-	Totaln       int
-	Expln        int
-	TotalSize    int64
-	pacmanCaches map[string]int64
-	yayCache     int64
-},
-) {
+func statistics(run *runtime.Runtime, dbExecutor db.Executor) *Statistics {
+	res := &Statistics{
+		Version:      yayVersion,
+		PacmanCaches: make(map[string]int64),
+		SizeByRepo:   make(map[string]int64),
+	}
+
+	remote := dbExecutor.InstalledRemotePackages()
+
 	for _, pkg := range dbExecutor.LocalPackages() {
 		res.TotalSize += pkg.ISize()
-		res.Totaln++
+		res.Total++
 
 		if pkg.Reason() == alpm.PkgReasonExplicit {
-			res.Expln++
+			res.Explicit++
 		}
+
+		res.SizeByRepo[packageRepo(dbExecutor, remote, pkg)] += pkg.ISize()
 	}
 
-	res.pacmanCaches = make(map[string]int64)
 	for _, path := range run.PacmanConf.CacheDir {
-		res.pacmanCaches[path] = getFolderSize(path)
+		res.PacmanCaches[path] = getFolderSize(path)
+	}
+
+	return res
+}
+
+// requiredBy lists the AUR packages requiring each target. With local the
+// targets are installed packages and default to every foreign package.
+func requiredBy(ctx context.Context, run *runtime.Runtime, cmdArgs *parser.Arguments,
+	dbExecutor db.Executor, local bool,
+) error {
+	formatter, err := newFormatter(cmdArgs)
+	if err != nil {
+		return err
+	}
+
+	targets := cmdArgs.Targets
+	if local && len(targets) == 0 {
+		targets = dbExecutor.InstalledRemotePackageNames()
+	}
+
+	missing := false
+	records := make([]query.RequiredByRecord, 0)
+
+	for _, target := range targets {
+		_, name := text.SplitDBFromName(target)
+
+		provides, found := targetProvides(ctx, run, dbExecutor, target, local)
+		if !found {
+			run.Logger.Errorln(gotext.Get("package '%s' was not found", target))
+			missing = true
+
+			continue
+		}
+
+		dependents, err := query.RequiredBy(ctx, run.AURClient, dbExecutor, name, provides)
+		if err != nil {
+			return err
+		}
+
+		if formatter != nil {
+			records = append(records, dependents...)
+			continue
+		}
+
+		printRequiredBy(run.Logger, name, dependents)
 	}
 
-	res.yayCache = getFolderSize(run.Cfg.BuildDir)
+	if formatter != nil {
+		var out strings.Builder
+		if err := output.Write(&out, formatter, records); err != nil {
+			return err
+		}
+
+		run.Logger.Print(out.String())
+	}
+
+	if missing {
+		return fmt.Errorf("")
+	}
+
+	return nil
+}
+
+// targetProvides finds the names a target provides in the local database,
+// the sync databases or the AUR.
+func targetProvides(ctx context.Context, run *runtime.Runtime, dbExecutor db.Executor,
+	target string, local bool,
+) (provides []string, found bool) {
+	dbName, name := text.SplitDBFromName(target)
+
+	if local {
+		if pkg := dbExecutor.LocalPackage(name); pkg != nil {
+			return dependNames(pkg.Provides()), true
+		}
+
+		return nil, false
+	}
+
+	if dbName != "aur" && run.Cfg.Mode.AtLeastRepo() {
+		if pkg := syncPackageFromTarget(dbExecutor, target); pkg != nil {
+			return dependNames(pkg.Provides()), true
+		}
+	}
+
+	if (dbName == "" || dbName == "aur") && run.Cfg.Mode.AtLeastAUR() {
+		pkgs, err := run.AURClient.Get(ctx, &aur.Query{Needles: []string{name}, By: aur.Name})
+		if err == nil && len(pkgs) != 0 {
+			return query.ProvideNames(pkgs[0].Provides), true
+		}
+	}
+
+	return nil, false
+}
+
+func dependNames(deps alpm.IDependList) []string {
+	names := make([]string, 0)
+	for _, dep := range deps.Slice() {
+		names = append(names, dep.Name)
+	}
+
+	return names
+}
+
+func printRequiredBy(logger *text.Logger, name string, dependents []query.RequiredByRecord) {
+	if len(dependents) == 0 {
+		logger.Warnln(gotext.Get("no AUR package requires %s", text.Cyan(name)))
+		return
+	}
+
+	logger.OperationInfoln(gotext.Get("AUR packages requiring %s:", text.Cyan(name)))
+
+	for i := range dependents {
+		dependent := &dependents[i]
+		kind := dependent.Kind
+
+		if dependent.Via != "" {
+			kind = gotext.Get("%s via %s", kind, dependent.Via)
+		}
+
+		line := fmt.Sprintf(" %s/%s %s (%s)", text.Bold(text.ColorHash("aur")),
+			text.Bold(dependent.Name), text.Cyan(dependent.Version), kind)
+		if dependent.Installed {
+			line += " " + text.Bold(text.Green(gotext.Get("(Installed)")))
+		}
+
+		logger.Println(line)
+	}
+}
+
+// printTree prints the dependency tree of each target installed package.
+func printTree(run *runtime.Runtime, cmdArgs *parser.Arguments, dbExecutor db.Executor) error {
+	if len(cmdArgs.Targets) == 0 {
+		return errors.New(gotext.Get("no targets specified"))
+	}
+
+	opts := query.TreeOptions{
+		Depth:    -1,
+		Reverse:  cmdArgs.ExistsArg("reverse"),
+		Optional: cmdArgs.ExistsArg("optional"),
+		Full:     cmdArgs.ExistsArg("nodedup"),
+	}
+
+	if value, _, ok := cmdArgs.GetArg("depth"); ok {
+		depth, err := query.ParseTreeDepth(value)
+		if err != nil {
+			return err
+		}
+
+		opts.Depth = depth
+	}
+
+	format := query.TreeFormatText
+	if value, _, ok := cmdArgs.GetArg("format"); ok {
+		format = value
+	}
+
+	for _, target := range cmdArgs.Targets {
+		root, err := query.BuildTree(dbExecutor, target, opts)
+		if err != nil {
+			return err
+		}
+
+		var out strings.Builder
+		if err := query.WriteTree(&out, root, format); err != nil {
+			return err
+		}
+
+		run.Logger.Print(out.String())
+	}
 
-	return
+	return nil
 }
@@ -20,8 +20,10 @@ import (
 	"github.com/Jguer/yay/v12/pkg/settings"
 	"github.com/Jguer/yay/v12/pkg/settings/exe"
 	"github.com/Jguer/yay/v12/pkg/settings/parser"
+	"github.com/Jguer/yay/v12/pkg/text"
 
 	"github.com/Jguer/aur"
+	alpm "github.com/Jguer/go-alpm/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -128,10 +130,11 @@ func TestSyncInfo(t *testing.T) {
 			}
 
 			run := &runtime.Runtime{
-				CmdBuilder: cmdBuilder,
-				AURClient:  mockAUR,
-				Logger:     newTestLogger(),
-				Cfg:        &settings.Configuration{},
+				CmdBuilder:    cmdBuilder,
+				AURClient:     mockAUR,
+				AURInfoClient: mockAUR,
+				Logger:        newTestLogger(),
+				Cfg:           &settings.Configuration{},
 			}
 
 			cmdArgs := parser.MakeArguments()
@@ -272,7 +275,7 @@ func TestSyncSearchAURDB(t *testing.T) {
 				CmdBuilder: cmdBuilder,
 				AURClient:  mockAUR,
 				QueryBuilder: query.NewSourceQueryBuilder(mockAUR, newTestLogger(), "votes", parser.ModeAny, "name",
-					tc.bottomUp, tc.singleLine, tc.mixed),
+					tc.bottomUp, tc.singleLine, tc.mixed, settings.DefaultRankWeights(), false),
 				Logger: newTestLogger(),
 				Cfg:    &settings.Configuration{},
 			}
@@ -307,3 +310,228 @@ func TestSyncSearchAURDB(t *testing.T) {
 		})
 	}
 }
+
+func TestFormattedOutput(t *testing.T) {
+	t.Parallel()
+
+	linux := &mock.Package{
+		PName:        "linux",
+		PBase:        "linux",
+		PVersion:     "6.9.1-1",
+		PDescription: "The Linux kernel",
+		PDB:          mock.NewDB("core"),
+		PReason:      alpm.PkgReasonExplicit,
+	}
+
+	yayBin := &mock.Package{
+		PName:    "yay-bin",
+		PBase:    "yay-bin",
+		PVersion: "12.3.5-1",
+		PDB:      mock.NewDB("local"),
+		PReason:  alpm.PkgReasonDepend,
+	}
+
+	dbExc := &mock.DBExecutor{
+		SyncSatisfierFn: func(s string) mock.IPackage {
+			if s == "linux" {
+				return linux
+			}
+			return nil
+		},
+		SyncPackageFn: func(s string) mock.IPackage {
+			if s == "linux" {
+				return linux
+			}
+			return nil
+		},
+		SyncPackagesFn: func(s ...string) []mock.IPackage {
+			return []mock.IPackage{linux}
+		},
+		LocalPackageFn: func(s string) mock.IPackage {
+			switch s {
+			case "linux":
+				return linux
+			case "yay-bin":
+				return yayBin
+			}
+			return nil
+		},
+		PackagesFromGroupFn: func(s string) []mock.IPackage {
+			return nil
+		},
+	}
+
+	mockAUR := &mockaur.MockAUR{GetFn: getFromFile(t, "pkg/dep/testdata/jellyfin.json")}
+
+	testCases := []struct {
+		name    string
+		args    []string
+		format  string
+		targets []string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "Ss template",
+			args:    []string{"S", "s"},
+			format:  "{{.Repository}}/{{.Name}} {{.Version}} {{.Votes}} {{.InstalledVersion}}",
+			targets: []string{"jellyfin"},
+			want:    "core/linux 6.9.1-1 0 6.9.1-1\naur/jellyfin 10.8.8-1 84 \n",
+		},
+		{
+			name:    "Si tsv",
+			args:    []string{"S", "i"},
+			format:  "tsv",
+			targets: []string{"linux", "jellyfin"},
+			want: "core\tlinux\tlinux\t6.9.1-1\tThe Linux kernel\t\t\t0\t0.00\tfalse\t6.9.1-1\n" +
+				"aur\tjellyfin\tjellyfin\t10.8.8-1\tThe Free Software Media System\thttps://github.com/jellyfin/jellyfin\tz3ntu\t84\t1.27\tfalse\t\n",
+		},
+		{
+			name:    "Qi template",
+			args:    []string{"Q", "i"},
+			format:  "{{.Name}} {{.Reason}} {{.Repository}}",
+			targets: []string{"linux"},
+			want:    "linux explicit local\n",
+		},
+		{
+			name:    "Qi foreign",
+			args:    []string{"Q", "i", "m"},
+			format:  "{{.Name}} {{.Reason}}",
+			targets: []string{"linux", "yay-bin"},
+			want:    "yay-bin dependency\n",
+		},
+		{
+			name:    "Qi native explicit",
+			args:    []string{"Q", "i", "n", "e"},
+			format:  "{{.Name}} {{.Reason}}",
+			targets: []string{"linux", "yay-bin"},
+			want:    "linux explicit\n",
+		},
+		{
+			name:    "Qi deps and explicit",
+			args:    []string{"Q", "i", "d", "e"},
+			format:  "json",
+			targets: []string{"linux"},
+			wantErr: true,
+		},
+		{
+			name:    "Qi missing",
+			args:    []string{"Q", "i"},
+			format:  "json",
+			targets: []string{"missing"},
+			want:    "[]\n",
+			wantErr: true,
+		},
+		{
+			name:    "Ss unknown format",
+			args:    []string{"S", "s"},
+			format:  "yaml",
+			targets: []string{"jellyfin"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			out := &strings.Builder{}
+			logger := text.NewLogger(out, io.Discard, strings.NewReader(""), false, "test")
+
+			run := &runtime.Runtime{
+				AURClient:     mockAUR,
+				AURInfoClient: mockAUR,
+				QueryBuilder: query.NewSourceQueryBuilder(mockAUR, logger, "name", parser.ModeAny, "name",
+					false, false, false, settings.DefaultRankWeights(), false),
+				Logger: logger,
+				Cfg:    &settings.Configuration{},
+			}
+
+			cmdArgs := parser.MakeArguments()
+			cmdArgs.AddArg(tc.args...)
+			cmdArgs.CreateOrAppendOption("format", tc.format)
+			cmdArgs.AddTarget(tc.targets...)
+
+			err := handleCmd(context.Background(), run, cmdArgs, dbExc)
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, tc.want, out.String())
+		})
+	}
+}
+
+func TestRequiredByOutput(t *testing.T) {
+	t.Parallel()
+
+	linux := &mock.Package{PName: "linux", PVersion: "6.9.1-1", PDB: mock.NewDB("core")}
+
+	dbExc := &mock.DBExecutor{
+		SyncPackageFn: func(s string) mock.IPackage {
+			if s == "linux" {
+				return linux
+			}
+			return nil
+		},
+		LocalPackageFn: func(s string) mock.IPackage {
+			if s == "linux-headers-git" {
+				return &mock.Package{PName: s}
+			}
+			return nil
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		makeDependent []aur.Pkg
+		want          string
+	}{
+		{
+			name:          "dependents",
+			makeDependent: []aur.Pkg{{Name: "linux-headers-git", Version: "6.10-1", MakeDepends: []string{"linux"}}},
+			want: "\x1b[1m\x1b[36m:: \x1b[0m\x1b[0m\x1b[1mAUR packages requiring \x1b[36mlinux\x1b[0m:\x1b[0m\n" +
+				" \x1b[1m\x1b[34maur\x1b[0m\x1b[0m/\x1b[1mlinux-headers-git\x1b[0m \x1b[36m6.10-1\x1b[0m (makedepends) " +
+				"\x1b[1m\x1b[32m(Installed)\x1b[0m\x1b[0m\n",
+		},
+		{
+			name:          "no dependents",
+			makeDependent: []aur.Pkg{},
+			want:          "\x1b[1m\x1b[33m -> \x1b[0m\x1b[0mno AUR package requires \x1b[36mlinux\x1b[0m\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			out := &strings.Builder{}
+			logger := text.NewLogger(out, io.Discard, strings.NewReader(""), false, "test")
+			mockAUR := &mockaur.MockAUR{
+				GetFn: func(ctx context.Context, query *aur.Query) ([]aur.Pkg, error) {
+					if query.By == aur.MakeDepends {
+						return tc.makeDependent, nil
+					}
+					return []aur.Pkg{}, nil
+				},
+			}
+
+			run := &runtime.Runtime{
+				AURClient: mockAUR,
+				Logger:    logger,
+				Cfg:       &settings.Configuration{},
+			}
+
+			cmdArgs := parser.MakeArguments()
+			cmdArgs.AddArg("S", "i", "required-by")
+			cmdArgs.AddTarget("linux")
+
+			require.NoError(t, handleCmd(context.Background(), run, cmdArgs, dbExc))
+			assert.Equal(t, tc.want, out.String())
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/Jguer/aur"
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yay/v12/pkg/db"
+	"github.com/Jguer/yay/v12/pkg/rebuild"
+	"github.com/Jguer/yay/v12/pkg/runtime"
+	"github.com/Jguer/yay/v12/pkg/settings/parser"
+	"github.com/Jguer/yay/v12/pkg/text"
+)
+
+// rebuildCheck lists the foreign packages broken by a library or runtime
+// upgrade and optionally queues them for the next sysupgrade.
+func rebuildCheck(run *runtime.Runtime, dbExecutor db.Executor, queue bool) error {
+	remote := dbExecutor.InstalledRemotePackages()
+
+	pkgs := make([]db.IPackage, 0, len(remote))
+	for _, pkg := range remote {
+		pkgs = append(pkgs, pkg)
+	}
+
+	sort.Slice(pkgs, func(i, j int) bool { return pkgs[i].Name() < pkgs[j].Name() })
+
+	checker := rebuild.NewChecker("/", rebuild.InstalledABIs(dbExecutor))
+	results := checker.Check(pkgs)
+
+	if len(results) == 0 {
+		run.Logger.Infoln(gotext.Get("No foreign packages need a rebuild."))
+		return nil
+	}
+
+	run.Logger.Printf("%s"+text.Bold(" %d ")+"%s\n", text.Bold(text.Cyan("::")),
+		len(results), text.Bold(gotext.GetN("foreign package needs a rebuild:",
+			"foreign packages need a rebuild:", len(results))))
+
+	for i := range results {
+		result := &results[i]
+
+		name := text.Bold(result.Name)
+		if result.Base != "" && result.Base != result.Name {
+			name += " " + gotext.Get("(base %s)", result.Base)
+		}
+
+		run.Logger.Println("  " + name)
+
+		for _, problem := range result.Problems {
+			switch problem.Kind {
+			case rebuild.KindMissingLibrary:
+				run.Logger.Println("      " + gotext.Get("%s not found, needed by /%s",
+					text.Red(problem.Detail), problem.File))
+			case rebuild.KindABI:
+				run.Logger.Println("      " + gotext.Get("installs modules for %s in /%s",
+					text.Red(problem.Detail), problem.File))
+			}
+		}
+	}
+
+	if !queue {
+		return nil
+	}
+
+	rebuilds := rebuild.NewQueue(run.Cfg.RebuildQueuePath)
+	if err := rebuilds.Load(); err != nil {
+		return err
+	}
+
+	rebuilds.Add(results)
+
+	if err := rebuilds.Save(); err != nil {
+		return err
+	}
+
+	run.Logger.OperationInfoln(gotext.Get("%d packages will be rebuilt by the next sysupgrade", len(rebuilds.Packages)))
+
+	return nil
+}
+
+// addQueuedRebuilds adds the installed packages queued by
+// --rebuild-check --rebuild-queue to the targets of a sysupgrade. It returns
+// the queue to clear once they were rebuilt and the package bases to build
+// again. Queued packages no longer in the AUR are skipped with a warning.
+func addQueuedRebuilds(ctx context.Context, run *runtime.Runtime,
+	dbExecutor db.Executor, cmdArgs *parser.Arguments,
+) (*rebuild.Queue, []string) {
+	rebuilds := rebuild.NewQueue(run.Cfg.RebuildQueuePath)
+	if err := rebuilds.Load(); err != nil {
+		run.Logger.Warnln(err)
+		return nil, nil
+	}
+
+	installed := make([]string, 0, len(rebuilds.Packages))
+
+	for _, name := range rebuilds.Packages {
+		if dbExecutor.LocalPackage(name) != nil {
+			installed = append(installed, name)
+		}
+	}
+
+	if len(installed) == 0 {
+		return rebuilds, nil
+	}
+
+	aurPkgs, err := run.AURClient.Get(ctx, &aur.Query{Needles: installed, By: aur.Name})
+	if err != nil {
+		run.Logger.Warnln(gotext.Get("unable to query queued rebuilds:"), err)
+		return nil, nil
+	}
+
+	found := make(map[string]string, len(aurPkgs))
+	for i := range aurPkgs {
+		found[aurPkgs[i].Name] = aurPkgs[i].PackageBase
+	}
+
+	targets := make([]string, 0, len(installed))
+	bases := make([]string, 0, len(installed))
+
+	for _, name := range installed {
+		base, ok := found[name]
+		if !ok {
+			run.Logger.Warnln(gotext.Get("%s is queued for a rebuild but was not found in the AUR -- skipping",
+				text.Cyan(name)))
+
+			continue
+		}
+
+		targets = append(targets, name)
+		bases = append(bases, base)
+	}
+
+	if len(targets) == 0 {
+		return rebuilds, nil
+	}
+
+	run.Logger.OperationInfoln(gotext.Get("Rebuilding packages queued by the rebuild check: %s",
+		text.Cyan(strings.Join(targets, " "))))
+
+	cmdArgs.AddTarget(targets...)
+
+	return rebuilds, bases
+}
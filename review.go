@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"os"
+
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yay/v12/pkg/runtime"
+)
+
+// exportReviews prints the review ledger so it can be shared with other machines.
+func exportReviews(run *runtime.Runtime) error {
+	return run.ReviewLedger.Export(os.Stdout)
+}
+
+// importReviews merges the review ledgers found in files into the local one.
+func importReviews(run *runtime.Runtime, files []string) error {
+	if len(files) == 0 {
+		return errors.New(gotext.Get("no review files to import"))
+	}
+
+	for _, path := range files {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+
+		added, err := run.ReviewLedger.Import(file)
+		file.Close()
+
+		if err != nil {
+			return err
+		}
+
+		run.Logger.OperationInfoln(gotext.Get("Imported %d reviews from %s", added, path))
+	}
+
+	return run.ReviewLedger.Save()
+}
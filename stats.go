@@ -0,0 +1,198 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Jguer/aur"
+	alpm "github.com/Jguer/go-alpm/v2"
+
+	"github.com/Jguer/yay/v12/pkg/db"
+	"github.com/Jguer/yay/v12/pkg/query"
+)
+
+// statisticsTop is the length of the lists of largest and oldest packages.
+const statisticsTop = 10
+
+// repoForeign is the repository of installed packages not in a sync database.
+const repoForeign = "foreign"
+
+// Statistics are the numbers printed by -Ps.
+type Statistics struct {
+	Version      string           `json:"version"`
+	Total        int              `json:"total"`
+	Foreign      int              `json:"foreign"`
+	Explicit     int              `json:"explicit"`
+	TotalSize    int64            `json:"totalSize"`
+	PacmanCaches map[string]int64 `json:"pacmanCaches"`
+	YayCache     int64            `json:"yayCache"`
+	// SizeByRepo is the installed size of the packages of each repository.
+	SizeByRepo      map[string]int64 `json:"sizeByRepo"`
+	AURHealth       AURHealth        `json:"aurHealth"`
+	BuildDirs       []SizeEntry      `json:"buildDirs"`
+	BiggestPackages []SizeEntry      `json:"biggestPackages"`
+	LargestForeign  []SizeEntry      `json:"largestForeign"`
+	OldestAURBuilds []BuildEntry     `json:"oldestAurBuilds"`
+}
+
+func (s Statistics) Fields() []string {
+	return []string{
+		s.Version, strconv.Itoa(s.Total), strconv.Itoa(s.Foreign), strconv.Itoa(s.Explicit),
+		strconv.FormatInt(s.TotalSize, 10), strconv.FormatInt(s.YayCache, 10),
+		strconv.Itoa(len(s.AURHealth.Orphaned)), strconv.Itoa(len(s.AURHealth.OutOfDate)),
+		strconv.Itoa(len(s.AURHealth.Deleted)), strconv.Itoa(len(s.AURHealth.Devel)),
+	}
+}
+
+// AURHealth lists the foreign packages by their state in the AUR.
+// Orphaned, OutOfDate and Deleted are null when the AUR could not be queried.
+type AURHealth struct {
+	Orphaned  []string `json:"orphaned"`
+	OutOfDate []string `json:"outOfDate"`
+	// Deleted packages are installed but no longer in the AUR.
+	Deleted []string `json:"deleted"`
+	Devel   []string `json:"devel"`
+}
+
+// SizeEntry is the size of a package or of a build directory.
+type SizeEntry struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+}
+
+// BuildEntry is the build date of an installed foreign package.
+type BuildEntry struct {
+	Name      string    `json:"name"`
+	Version   string    `json:"version"`
+	BuildDate time.Time `json:"buildDate"`
+}
+
+// packageRepo returns the sync database an installed package comes from.
+func packageRepo(dbExecutor db.Executor, remote map[string]alpm.IPackage, pkg alpm.IPackage) string {
+	if _, ok := remote[pkg.Name()]; ok {
+		return repoForeign
+	}
+
+	if syncPkg := dbExecutor.SyncPackage(pkg.Name()); syncPkg != nil {
+		return syncPkg.DB().Name()
+	}
+
+	return repoForeign
+}
+
+// addAURHealth fills in the state of the foreign packages in the AUR,
+// adding it to warnings.
+func (s *Statistics) addAURHealth(dbExecutor db.Executor, aurData []aur.Pkg, warnings *query.AURWarnings) {
+	remoteNames := dbExecutor.InstalledRemotePackageNames()
+	remote := dbExecutor.InstalledRemotePackages()
+
+	aurByName := make(map[string]*aur.Pkg, len(aurData))
+
+	for i := range aurData {
+		warnings.AddToWarnings(remote, &aurData[i])
+		aurByName[aurData[i].Name] = &aurData[i]
+	}
+
+	warnings.CalculateMissing(dbExecutor, remoteNames, remote, aurByName)
+
+	s.AURHealth.Orphaned = nonNilNames(warnings.Orphans)
+	s.AURHealth.OutOfDate = nonNilNames(warnings.OutOfDate)
+	s.AURHealth.Deleted = nonNilNames(warnings.Missing)
+}
+
+// addForeignStatistics fills in the breakdowns of the foreign packages that
+// do not need the AUR.
+func (s *Statistics) addForeignStatistics(dbExecutor db.Executor) {
+	remoteNames := dbExecutor.InstalledRemotePackageNames()
+	remote := dbExecutor.InstalledRemotePackages()
+	s.Foreign = len(remoteNames)
+	s.AURHealth.Devel = []string{}
+
+	foreign := make([]alpm.IPackage, 0, len(remoteNames))
+
+	for _, name := range remoteNames {
+		pkg := remote[name]
+		foreign = append(foreign, pkg)
+
+		if query.IsDevelPackage(pkg) {
+			s.AURHealth.Devel = append(s.AURHealth.Devel, name)
+		}
+	}
+
+	sort.SliceStable(foreign, func(i, j int) bool {
+		return foreign[i].ISize() > foreign[j].ISize()
+	})
+
+	s.LargestForeign = make([]SizeEntry, 0, statisticsTop)
+	for i := 0; i < len(foreign) && i < statisticsTop; i++ {
+		s.LargestForeign = append(s.LargestForeign, SizeEntry{foreign[i].Name(), foreign[i].ISize()})
+	}
+
+	sort.SliceStable(foreign, func(i, j int) bool {
+		return foreign[i].BuildDate().Before(foreign[j].BuildDate())
+	})
+
+	s.OldestAURBuilds = make([]BuildEntry, 0, statisticsTop)
+	for i := 0; i < len(foreign) && i < statisticsTop; i++ {
+		s.OldestAURBuilds = append(s.OldestAURBuilds,
+			BuildEntry{foreign[i].Name(), foreign[i].Version(), foreign[i].BuildDate()})
+	}
+}
+
+// addBiggestPackages lists the largest installed packages.
+func (s *Statistics) addBiggestPackages(dbExecutor db.Executor) {
+	biggest := dbExecutor.BiggestPackages()
+
+	s.BiggestPackages = make([]SizeEntry, 0, statisticsTop)
+	for i := 0; i < len(biggest) && i < statisticsTop; i++ {
+		s.BiggestPackages = append(s.BiggestPackages, SizeEntry{biggest[i].Name(), biggest[i].ISize()})
+	}
+}
+
+// addBuildDirs measures the build directory of each package base, largest
+// first, and the whole yay cache from the same walk.
+func (s *Statistics) addBuildDirs(buildDir string) {
+	s.BuildDirs = make([]SizeEntry, 0)
+
+	root, err := os.Stat(buildDir)
+	if err != nil {
+		return
+	}
+
+	s.YayCache = root.Size()
+
+	entries, err := os.ReadDir(buildDir)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		size := getFolderSize(filepath.Join(buildDir, entry.Name()))
+		s.YayCache += size
+
+		if !entry.IsDir() || entry.Name()[0] == '.' {
+			continue
+		}
+
+		s.BuildDirs = append(s.BuildDirs, SizeEntry{entry.Name(), size})
+	}
+
+	sort.SliceStable(s.BuildDirs, func(i, j int) bool {
+		return s.BuildDirs[i].Size > s.BuildDirs[j].Size
+	})
+
+	if len(s.BuildDirs) > statisticsTop {
+		s.BuildDirs = s.BuildDirs[:statisticsTop]
+	}
+}
+
+func nonNilNames(names []string) []string {
+	if names == nil {
+		return []string{}
+	}
+
+	return names
+}
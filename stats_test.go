@@ -0,0 +1,194 @@
+//go:build !integration
+// +build !integration
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Jguer/aur"
+	alpm "github.com/Jguer/go-alpm/v2"
+	"github.com/Morganamilo/go-pacmanconf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Jguer/yay/v12/pkg/db/mock"
+	mockaur "github.com/Jguer/yay/v12/pkg/dep/mock"
+	"github.com/Jguer/yay/v12/pkg/query"
+	"github.com/Jguer/yay/v12/pkg/runtime"
+	"github.com/Jguer/yay/v12/pkg/settings"
+	"github.com/Jguer/yay/v12/pkg/settings/parser"
+	"github.com/Jguer/yay/v12/pkg/text"
+)
+
+func TestStatistics(t *testing.T) {
+	t.Parallel()
+
+	core := mock.NewDB("core")
+	extra := mock.NewDB("extra")
+	linux := &mock.Package{PName: "linux", PVersion: "6.9.1-1", PISize: 100, PReason: alpm.PkgReasonExplicit}
+	vim := &mock.Package{PName: "vim", PVersion: "9.1-1", PISize: 50, PReason: alpm.PkgReasonDepend}
+	yayGit := &mock.Package{
+		PName: "yay-git", PBase: "yay-git", PVersion: "12.0.r1-1", PISize: 20,
+		PReason: alpm.PkgReasonExplicit, PBuildDate: time.Unix(2000, 0),
+	}
+	oldPkg := &mock.Package{
+		PName: "old-pkg", PBase: "old-pkg", PVersion: "1-1", PISize: 30,
+		PReason: alpm.PkgReasonExplicit, PBuildDate: time.Unix(1000, 0),
+	}
+	gone := &mock.Package{
+		PName: "gone", PBase: "gone", PVersion: "2-1", PISize: 5,
+		PReason: alpm.PkgReasonDepend, PBuildDate: time.Unix(3000, 0),
+	}
+	remote := map[string]mock.IPackage{"yay-git": yayGit, "old-pkg": oldPkg, "gone": gone}
+
+	dbExc := &mock.DBExecutor{
+		LocalPackagesFn: func() []mock.IPackage {
+			return []mock.IPackage{linux, vim, yayGit, oldPkg, gone}
+		},
+		InstalledRemotePackagesFn: func() map[string]mock.IPackage {
+			return remote
+		},
+		InstalledRemotePackageNamesFn: func() []string {
+			return []string{"gone", "old-pkg", "yay-git"}
+		},
+		SyncPackageFn: func(name string) mock.IPackage {
+			switch name {
+			case "linux":
+				return &mock.Package{PName: name, PDB: core}
+			case "vim":
+				return &mock.Package{PName: name, PDB: extra}
+			}
+
+			return nil
+		},
+		SyncSatisfierFn: func(string) mock.IPackage { return nil },
+	}
+
+	buildDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(buildDir, "small"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(buildDir, "big", "src"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(buildDir, "small", "PKGBUILD"), []byte("a"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(buildDir, "big", "src", "blob"), make([]byte, 1<<16), 0o644))
+
+	run := &runtime.Runtime{
+		Cfg:        &settings.Configuration{BuildDir: buildDir},
+		PacmanConf: &pacmanconf.Config{},
+		Logger:     text.NewLogger(io.Discard, io.Discard, strings.NewReader(""), false, "test"),
+	}
+
+	info := statistics(run, dbExc)
+	info.addForeignStatistics(dbExc)
+	info.addAURHealth(dbExc, []aur.Pkg{
+		{Name: "yay-git", Version: "12.0.r1-1", Maintainer: "jguer"},
+		{Name: "old-pkg", Version: "1-1", OutOfDate: 1700000000},
+	}, query.NewWarnings(run.Logger))
+	info.addBuildDirs(buildDir)
+
+	assert.Equal(t, 5, info.Total)
+	assert.Equal(t, 3, info.Foreign)
+	assert.Equal(t, 3, info.Explicit)
+	assert.Equal(t, int64(205), info.TotalSize)
+	assert.Equal(t, map[string]int64{"core": 100, "extra": 50, "foreign": 55}, info.SizeByRepo)
+	assert.Equal(t, AURHealth{
+		Orphaned:  []string{"old-pkg"},
+		OutOfDate: []string{"old-pkg"},
+		Deleted:   []string{"gone"},
+		Devel:     []string{"yay-git"},
+	}, info.AURHealth)
+	assert.Equal(t, []SizeEntry{{"old-pkg", 30}, {"yay-git", 20}, {"gone", 5}}, info.LargestForeign)
+	assert.Equal(t, []BuildEntry{
+		{"old-pkg", "1-1", time.Unix(1000, 0)},
+		{"yay-git", "12.0.r1-1", time.Unix(2000, 0)},
+		{"gone", "2-1", time.Unix(3000, 0)},
+	}, info.OldestAURBuilds)
+
+	require.Len(t, info.BuildDirs, 2)
+	assert.Equal(t, "big", info.BuildDirs[0].Name)
+	assert.Equal(t, "small", info.BuildDirs[1].Name)
+	assert.Greater(t, info.BuildDirs[0].Size, int64(1<<16))
+	assert.Equal(t, getFolderSize(buildDir), info.YayCache)
+}
+
+func TestLocalStatisticsAURUnreachable(t *testing.T) {
+	t.Parallel()
+
+	yayGit := &mock.Package{PName: "yay-git", PBase: "yay-git", PVersion: "12.0.r1-1", PISize: 20}
+
+	dbExc := &mock.DBExecutor{
+		LocalPackagesFn: func() []mock.IPackage { return []mock.IPackage{yayGit} },
+		InstalledRemotePackagesFn: func() map[string]mock.IPackage {
+			return map[string]mock.IPackage{"yay-git": yayGit}
+		},
+		InstalledRemotePackageNamesFn: func() []string { return []string{"yay-git"} },
+		BiggestPackagesFn:             func() []mock.IPackage { return []mock.IPackage{yayGit} },
+	}
+
+	out := &strings.Builder{}
+	run := &runtime.Runtime{
+		Cfg:        &settings.Configuration{BuildDir: t.TempDir()},
+		PacmanConf: &pacmanconf.Config{},
+		Logger:     text.NewLogger(out, out, strings.NewReader(""), false, "test"),
+		AURClient: &mockaur.MockAUR{
+			GetFn: func(ctx context.Context, query *aur.Query) ([]aur.Pkg, error) {
+				return nil, errors.New("network is unreachable")
+			},
+		},
+	}
+
+	err := localStatistics(context.Background(), run, parser.MakeArguments(), dbExc)
+	require.NoError(t, err)
+
+	assert.Contains(t, out.String(), "skipping AUR package health:network is unreachable")
+	assert.Contains(t, out.String(), "Foreign installed packages: \x1b[36m1")
+	assert.Contains(t, out.String(), "Devel\x1b[0m: \x1b[36m1")
+	assert.Contains(t, out.String(), "Largest foreign packages:")
+	assert.NotContains(t, out.String(), "Deleted")
+}
+
+func TestLocalStatisticsAURUnreachableJSON(t *testing.T) {
+	t.Parallel()
+
+	yayGit := &mock.Package{PName: "yay-git", PBase: "yay-git", PVersion: "12.0.r1-1", PISize: 20}
+
+	dbExc := &mock.DBExecutor{
+		LocalPackagesFn: func() []mock.IPackage { return []mock.IPackage{yayGit} },
+		InstalledRemotePackagesFn: func() map[string]mock.IPackage {
+			return map[string]mock.IPackage{"yay-git": yayGit}
+		},
+		InstalledRemotePackageNamesFn: func() []string { return []string{"yay-git"} },
+		BiggestPackagesFn:             func() []mock.IPackage { return []mock.IPackage{yayGit} },
+	}
+
+	stdout, stderr := &strings.Builder{}, &strings.Builder{}
+	run := &runtime.Runtime{
+		Cfg:        &settings.Configuration{BuildDir: t.TempDir()},
+		PacmanConf: &pacmanconf.Config{},
+		Logger:     text.NewLogger(stdout, stderr, strings.NewReader(""), false, "test"),
+		AURClient: &mockaur.MockAUR{
+			GetFn: func(ctx context.Context, query *aur.Query) ([]aur.Pkg, error) {
+				return nil, errors.New("network is unreachable")
+			},
+		},
+	}
+
+	cmdArgs := parser.MakeArguments()
+	cmdArgs.CreateOrAppendOption("format", "json")
+
+	err := localStatistics(context.Background(), run, cmdArgs, dbExc)
+	require.NoError(t, err)
+
+	stats := Statistics{}
+	require.NoError(t, json.Unmarshal([]byte(stdout.String()), &stats), stdout.String())
+	assert.Equal(t, 1, stats.Foreign)
+	assert.Nil(t, stats.AURHealth.Deleted)
+	assert.Contains(t, stderr.String(), "skipping AUR package health:network is unreachable")
+}
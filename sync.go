@@ -3,18 +3,24 @@ package main
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/leonelquinteros/gotext"
 
+	"github.com/Jguer/yay/v12/pkg/advisory"
+	"github.com/Jguer/yay/v12/pkg/aurrequest"
 	"github.com/Jguer/yay/v12/pkg/db"
 	"github.com/Jguer/yay/v12/pkg/dep"
 	"github.com/Jguer/yay/v12/pkg/multierror"
+	"github.com/Jguer/yay/v12/pkg/rebuild"
 	"github.com/Jguer/yay/v12/pkg/runtime"
 	"github.com/Jguer/yay/v12/pkg/settings"
 	"github.com/Jguer/yay/v12/pkg/settings/exe"
 	"github.com/Jguer/yay/v12/pkg/settings/parser"
 	"github.com/Jguer/yay/v12/pkg/sync"
+	"github.com/Jguer/yay/v12/pkg/unattended"
 	"github.com/Jguer/yay/v12/pkg/upgrade"
 )
 
@@ -22,7 +28,18 @@ func syncInstall(ctx context.Context,
 	run *runtime.Runtime,
 	cmdArgs *parser.Arguments,
 	dbExecutor db.Executor,
-) error {
+) (err error) {
+	var report *unattended.Report
+
+	if run.Cfg.Unattended {
+		report = unattended.NewReport(time.Now())
+		defer func() { writeReport(run, report, err) }()
+
+		if errNews := checkUnreadNews(ctx, run, dbExecutor); errNews != nil {
+			return errNews
+		}
+	}
+
 	aurCache := run.AURClient
 	refreshArg := cmdArgs.ExistsArg("y", "refresh")
 	noDeps := cmdArgs.ExistsArg("d", "nodeps")
@@ -43,6 +60,15 @@ func syncInstall(ctx context.Context,
 		}
 	}
 
+	var (
+		rebuilds     *rebuild.Queue
+		rebuildBases []string
+	)
+
+	if cmdArgs.ExistsArg("u", "sysupgrade") && run.Cfg.Mode.AtLeastAUR() {
+		rebuilds, rebuildBases = addQueuedRebuilds(ctx, run, dbExecutor, cmdArgs)
+	}
+
 	grapher := dep.NewGrapher(dbExecutor, aurCache, false, settings.NoConfirm,
 		noDeps, noCheck, cmdArgs.ExistsArg("needed"), run.Logger.Child("grapher"))
 
@@ -55,9 +81,27 @@ func syncInstall(ctx context.Context,
 	if cmdArgs.ExistsArg("u", "sysupgrade") {
 		var errSysUp error
 
-		upService := upgrade.NewUpgradeService(
+		upService, errUp := upgrade.NewUpgradeService(
 			grapher, aurCache, dbExecutor, run.VCSStore,
 			run.Cfg, settings.NoConfirm, run.Logger.Child("upgrade"))
+		if errUp != nil {
+			return errUp
+		}
+
+		if run.Cfg.AURCommits > 0 {
+			upService.Changelogs = upgrade.NewChangelogFetcher(run.CmdBuilder, run.Cfg.AURURL, run.Cfg.BuildDir,
+				filepath.Join(filepath.Dir(run.Cfg.VCSFilePath), ".aur-clones"), run.Cfg.AURCommits,
+				run.Logger.Child("changelog"))
+		}
+
+		if run.Cfg.AURRequestFeed != "" {
+			feed, errFeed := aurrequest.Load(ctx, run.HTTPClient, run.Cfg.AURRequestFeed)
+			if errFeed != nil {
+				run.Logger.Warnln(gotext.Get("unable to load AUR request feed:"), errFeed)
+			}
+
+			upService.Requests = feed
+		}
 
 		graph, errSysUp = upService.GraphUpgrades(ctx,
 			graph, cmdArgs.ExistsDouble("u", "sysupgrade"),
@@ -68,13 +112,31 @@ func syncInstall(ctx context.Context,
 
 		upService.AURWarnings.Print()
 
+		if run.Cfg.AdvisoryFeed != "" && graph.Len() > 0 {
+			feed, errFeed := advisory.Load(ctx, run.HTTPClient, run.Cfg.AdvisoryFeed)
+			if errFeed != nil {
+				run.Logger.Warnln(gotext.Get("unable to load advisory feed:"), errFeed)
+			}
+
+			upService.Advisories = feed
+		}
+
 		excluded, errSysUp = upService.UserExcludeUpgrades(graph)
 		if errSysUp != nil {
 			return errSysUp
 		}
+
+		if report != nil {
+			reportUpgrades(report, graph, upService.Held())
+
+			if errReview := checkMaintainers(ctx, run, graph); errReview != nil {
+				return errReview
+			}
+		}
 	}
 
 	opService := sync.NewOperationService(ctx, dbExecutor, run)
+	opService.RebuildBases = rebuildBases
 	multiErr := &multierror.MultiError{}
 	targets := graph.TopoSortedLayerMap(func(s string, ii *dep.InstallInfo) error {
 		if ii.Source == dep.Missing {
@@ -87,7 +149,17 @@ func syncInstall(ctx context.Context,
 		return err
 	}
 
-	return opService.Run(ctx, run, cmdArgs, targets, excluded)
+	if errRun := opService.Run(ctx, run, cmdArgs, targets, excluded); errRun != nil {
+		return errRun
+	}
+
+	if rebuilds != nil && len(rebuilds.Packages) > 0 {
+		if errClear := rebuilds.Clear(); errClear != nil {
+			run.Logger.Warnln(errClear)
+		}
+	}
+
+	return nil
 }
 
 func earlyRefresh(ctx context.Context, cfg *settings.Configuration, cmdBuilder exe.ICmdBuilder, cmdArgs *parser.Arguments) error {
@@ -103,3 +175,21 @@ func earlyRefresh(ctx context.Context, cfg *settings.Configuration, cmdBuilder e
 	return cmdBuilder.Show(cmdBuilder.BuildPacmanCmd(ctx,
 		arguments, cfg.Mode, settings.NoConfirm))
 }
+
+// refreshAURMetadata refreshes the AUR metadata cache used by --offline.
+func refreshAURMetadata(ctx context.Context, run *runtime.Runtime) error {
+	run.Logger.OperationInfoln(gotext.Get("Synchronizing AUR metadata..."))
+
+	updated, err := run.AURCache.Refresh(ctx)
+	if err != nil {
+		return err
+	}
+
+	if updated {
+		run.Logger.Println(" aur", gotext.Get("downloaded"))
+	} else {
+		run.Logger.Println(" aur", gotext.Get("is up to date"))
+	}
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/Jguer/aur"
+	"github.com/leonelquinteros/gotext"
+
+	"github.com/Jguer/yay/v12/pkg/db"
+	"github.com/Jguer/yay/v12/pkg/dep"
+	"github.com/Jguer/yay/v12/pkg/dep/topo"
+	"github.com/Jguer/yay/v12/pkg/news"
+	"github.com/Jguer/yay/v12/pkg/runtime"
+	"github.com/Jguer/yay/v12/pkg/text"
+	"github.com/Jguer/yay/v12/pkg/unattended"
+	"github.com/Jguer/yay/v12/pkg/upgrade"
+)
+
+// checkUnreadNews stops an unattended run if Arch news were published since
+// the last upgrade.
+func checkUnreadNews(ctx context.Context, run *runtime.Runtime, dbExecutor db.Executor) error {
+	unread, err := news.Unread(ctx, run.HTTPClient, dbExecutor.LastBuildTime())
+	if err != nil {
+		return err
+	}
+
+	conditions := make([]unattended.Condition, 0, len(unread))
+	for _, headline := range unread {
+		conditions = append(conditions, unattended.Condition{
+			Kind:   unattended.ConditionNews,
+			Detail: text.FormatTime(int(headline.Date.Unix())) + " " + headline.Title,
+		})
+	}
+
+	if len(conditions) > 0 {
+		return &unattended.ErrReviewRequired{Conditions: conditions}
+	}
+
+	return nil
+}
+
+// checkMaintainers stops an unattended run if the AUR maintainer of a target
+// changed and the package base was not reviewed since.
+func checkMaintainers(ctx context.Context, run *runtime.Runtime,
+	graph *topo.Graph[string, *dep.InstallInfo],
+) error {
+	names := make([]string, 0)
+
+	_ = graph.ForEach(func(name string, info *dep.InstallInfo) error {
+		if info.Source == dep.AUR {
+			names = append(names, name)
+		}
+
+		return nil
+	})
+
+	if len(names) == 0 {
+		return nil
+	}
+
+	aurPkgs, err := run.AURClient.Get(ctx, &aur.Query{Needles: names, By: aur.Name})
+	if err != nil {
+		return err
+	}
+
+	state := unattended.NewState(run.Cfg.UnattendedPath)
+	if err := state.Load(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	conditions := make([]unattended.Condition, 0)
+
+	for i := range aurPkgs {
+		pkg := &aurPkgs[i]
+
+		var lastReview time.Time
+		if run.ReviewLedger != nil {
+			if entry, ok := run.ReviewLedger.Latest(pkg.PackageBase); ok {
+				lastReview = entry.Time
+			}
+		}
+
+		if previous, changed := state.CheckMaintainer(pkg.PackageBase, pkg.Maintainer, lastReview, now); changed {
+			conditions = append(conditions, unattended.Condition{
+				Kind:    unattended.ConditionMaintainer,
+				Package: pkg.PackageBase,
+				Detail:  gotext.Get("maintainer changed from '%s' to '%s'", previous, pkg.Maintainer),
+			})
+		}
+	}
+
+	if err := state.Save(); err != nil {
+		return err
+	}
+
+	if len(conditions) > 0 {
+		return &unattended.ErrReviewRequired{Conditions: conditions}
+	}
+
+	return nil
+}
+
+// reportUpgrades adds the targets of the run and the held upgrades to the report.
+func reportUpgrades(report *unattended.Report, graph *topo.Graph[string, *dep.InstallInfo], held []upgrade.Upgrade) {
+	_ = graph.ForEach(func(name string, info *dep.InstallInfo) error {
+		repository := "aur"
+		if info.Source == dep.Sync {
+			repository = *info.SyncDBName
+		}
+
+		report.Targets = append(report.Targets, unattended.Package{
+			Name:          name,
+			Repository:    repository,
+			LocalVersion:  info.LocalVersion,
+			RemoteVersion: info.Version,
+		})
+
+		return nil
+	})
+
+	for _, up := range held {
+		report.Held = append(report.Held, unattended.Package{
+			Name:          up.Name,
+			Repository:    up.Repository,
+			LocalVersion:  up.LocalVersion,
+			RemoteVersion: up.RemoteVersion,
+			Note:          up.Extra,
+		})
+	}
+}
+
+// writeReport finishes the report of an unattended run and saves it.
+func writeReport(run *runtime.Runtime, report *unattended.Report, err error) {
+	report.Finish(time.Now(), err)
+
+	if errWrite := report.Write(run.Cfg.ReportPath); errWrite != nil {
+		run.Logger.Errorln(gotext.Get("failed to write unattended report: %s", errWrite))
+		return
+	}
+
+	run.Logger.OperationInfoln(gotext.Get("Unattended upgrade %s, report written to %s",
+		report.Status, text.Cyan(run.Cfg.ReportPath)))
+}
+
+// installTimer writes the systemd units running unattended upgrades. Units
+// are installed system wide when run as root and for the user otherwise.
+func installTimer(run *runtime.Runtime) error {
+	system := os.Geteuid() == 0
+
+	dir, err := unattended.UnitDir(system)
+	if err != nil {
+		return err
+	}
+
+	execPath, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	servicePath, timerPath, err := unattended.InstallTimer(dir, execPath, run.Cfg.UnattendedTimer)
+	if err != nil {
+		return err
+	}
+
+	run.Logger.OperationInfoln(gotext.Get("Wrote %s and %s", text.Cyan(servicePath), text.Cyan(timerPath)))
+
+	enable := "systemctl --user enable --now " + unattended.UnitName + ".timer"
+	if system {
+		enable = "systemctl enable --now " + unattended.UnitName + ".timer"
+	}
+
+	run.Logger.Infoln(gotext.Get("Enable it with: %s", text.Bold(enable)))
+
+	return nil
+}
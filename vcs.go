@@ -1,7 +1,11 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 
 	"github.com/Jguer/aur"
@@ -33,7 +37,10 @@ func infoToInstallInfo(info []aur.Pkg) []map[string]*dep.InstallInfo {
 func createDevelDB(ctx context.Context, run *runtime.Runtime, dbExecutor db.Executor) error {
 	remoteNames := dbExecutor.InstalledRemotePackageNames()
 
-	run.QueryBuilder.Execute(ctx, dbExecutor, remoteNames)
+	if err := run.QueryBuilder.Execute(ctx, dbExecutor, remoteNames); err != nil {
+		return err
+	}
+
 	info, err := run.AURClient.Get(ctx, &aur.Query{
 		Needles:  remoteNames,
 		By:       aur.Name,
@@ -73,3 +80,100 @@ func createDevelDB(ctx context.Context, run *runtime.Runtime, dbExecutor db.Exec
 
 	return err
 }
+
+// makepkgSrcDest returns the SRCDEST makepkg downloads sources to, read from
+// the environment or the makepkg configuration files. An empty result means
+// sources are downloaded next to the PKGBUILD.
+//
+// The configuration files are bash but are not evaluated: only plain
+// SRCDEST= lines are read, with quotes stripped and environment variables
+// expanded. Conditionals, command substitutions and variables set earlier in
+// the file are not understood, and the last assignment found wins.
+func makepkgSrcDest(makepkgConf string) string {
+	if srcDest := os.Getenv("SRCDEST"); srcDest != "" {
+		return srcDest
+	}
+
+	if makepkgConf == "" {
+		makepkgConf = "/etc/makepkg.conf"
+	}
+
+	confs := []string{makepkgConf}
+	dropIns, _ := filepath.Glob(makepkgConf + ".d/*.conf")
+	confs = append(confs, dropIns...)
+
+	if configHome := os.Getenv("XDG_CONFIG_HOME"); configHome != "" {
+		confs = append(confs, filepath.Join(configHome, "pacman", "makepkg.conf"))
+	} else if home := os.Getenv("HOME"); home != "" {
+		confs = append(confs, filepath.Join(home, ".config", "pacman", "makepkg.conf"))
+	}
+
+	if home := os.Getenv("HOME"); home != "" {
+		confs = append(confs, filepath.Join(home, ".makepkg.conf"))
+	}
+
+	srcDest := ""
+
+	for _, conf := range confs {
+		file, err := os.Open(conf)
+		if err != nil {
+			continue
+		}
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			value, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "SRCDEST=")
+			if ok {
+				srcDest = os.ExpandEnv(strings.Trim(value, `"'`))
+			}
+		}
+
+		file.Close()
+	}
+
+	return srcDest
+}
+
+// createLocalDevelDB creates the DB of the existing development packages from
+// the sources already downloaded in the build directory and SRCDEST, without
+// querying the AUR or the remotes of the sources.
+func createLocalDevelDB(ctx context.Context, run *runtime.Runtime, dbExecutor db.Executor) error {
+	srcDest := makepkgSrcDest(run.Cfg.MakepkgConf)
+	pkgBuildDirsByBase := make(map[string]string)
+	pkgNamesByBase := make(map[string][]string)
+
+	for _, pkgName := range dbExecutor.InstalledRemotePackageNames() {
+		base := pkgName
+		if pkg := dbExecutor.LocalPackage(pkgName); pkg != nil && pkg.Base() != "" {
+			base = pkg.Base()
+		}
+
+		dir := filepath.Join(run.Cfg.BuildDir, base)
+		if _, err := os.Stat(filepath.Join(dir, ".SRCINFO")); err != nil {
+			run.Logger.Debugln("no build directory for", pkgName)
+			continue
+		}
+
+		pkgBuildDirsByBase[base] = dir
+		pkgNamesByBase[base] = append(pkgNamesByBase[base], pkgName)
+	}
+
+	srcinfos, err := srcinfo.ParseSrcinfoFilesByBase(run.Logger.Child("srcinfo"), pkgBuildDirsByBase, false)
+	if err != nil {
+		return err
+	}
+
+	found := 0
+
+	for base, info := range srcinfos {
+		for _, pkgName := range pkgNamesByBase[base] {
+			if run.VCSStore.UpdateFromCheckouts(ctx, pkgName, info.Source, pkgBuildDirsByBase[base], srcDest) {
+				found++
+			}
+		}
+	}
+
+	run.Logger.OperationInfoln(gotext.Get("GenDB finished. %d development packages found in the build directory", found))
+
+	return nil
+}
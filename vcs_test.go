@@ -0,0 +1,32 @@
+//go:build !integration
+// +build !integration
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMakepkgSrcDest(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("SRCDEST", "")
+
+	conf := filepath.Join(t.TempDir(), "makepkg.conf")
+	require.NoError(t, os.WriteFile(conf, []byte("#SRCDEST=/ignored\nPKGDEST=/pkgs\n"), 0o644))
+	assert.Equal(t, "", makepkgSrcDest(conf))
+
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".config", "pacman"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".config", "pacman", "makepkg.conf"),
+		[]byte(`SRCDEST="$HOME/sources"`+"\n"), 0o644))
+	assert.Equal(t, filepath.Join(home, "sources"), makepkgSrcDest(conf))
+
+	t.Setenv("SRCDEST", "/srv/sources")
+	assert.Equal(t, "/srv/sources", makepkgSrcDest(conf))
+}